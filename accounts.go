@@ -0,0 +1,563 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//#region COMPTES DES JOUEURS
+
+const (
+	// sessionCookie est le nom du cookie portant le jeton de session
+	sessionCookie = "power4_session"
+
+	// sessionDuration est la durée de validité d'une session
+	sessionDuration = 30 * 24 * time.Hour
+
+	// passwordIterations est le nombre d'itérations PBKDF2 du hachage des mots de passe
+	passwordIterations = 100000
+
+	// minPasswordLength est la longueur minimale d'un mot de passe
+	minPasswordLength = 8
+)
+
+// usernamePattern décrit un identifiant valide : 3 à 20 lettres, chiffres, "_" ou "-"
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,20}$`)
+
+// skinPattern décrit un skin de jeton existant (static/tokens/skin1.png à skin8.png)
+var skinPattern = regexp.MustCompile(`^skin[1-8]$`)
+
+var (
+	// errBadCredentials est renvoyée pour un identifiant ou un mot de passe incorrect
+	// (sans préciser lequel, pour ne pas révéler les comptes existants)
+	errBadCredentials = errors.New("identifiant ou mot de passe incorrect")
+
+	// errNotLoggedIn est renvoyée quand la requête ne porte pas de session valide
+	errNotLoggedIn = errors.New("connexion requise")
+)
+
+// User est un compte de joueur enregistré
+type User struct {
+	ID           string    `json:"id"`           // Identifiant unique du compte
+	Username     string    `json:"username"`     // Identifiant de connexion, affiché comme pseudo
+	PasswordHash string    `json:"passwordHash"` // Mot de passe haché (voir hashPassword)
+	Skin         string    `json:"skin"`         // Skin de jeton préféré ("" si aucun)
+	CreatedAt    time.Time `json:"createdAt"`    // Date d'inscription
+
+	Ratings map[string]*Rating `json:"ratings,omitempty"` // Classement par format de plateau ("6x7", etc.)
+}
+
+// Profile est la partie publique d'un compte (jamais le mot de passe)
+type Profile struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Skin      string    `json:"skin"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// profile retourne la partie publique du compte
+func (u *User) profile() Profile {
+	return Profile{ID: u.ID, Username: u.Username, Skin: u.Skin, CreatedAt: u.CreatedAt}
+}
+
+// session relie un jeton de session à un compte
+type session struct {
+	userID  string    // Compte connecté
+	expires time.Time // Fin de validité de la session
+}
+
+// Accounts gère les comptes des joueurs et leurs sessions
+//
+// Les comptes sont enregistrés dans un fichier JSON (ou gardés en
+// mémoire) ; les sessions restent en mémoire : un redémarrage du
+// serveur déconnecte tout le monde.
+type Accounts struct {
+	mu       sync.RWMutex       // Protège toutes les maps ci-dessous
+	users    map[string]*User   // Comptes, indexés par identifiant
+	names    map[string]string  // Identifiant de compte par nom (en minuscules)
+	sessions map[string]session // Sessions ouvertes, indexées par jeton
+	path     string             // Fichier des comptes ("" = en mémoire)
+	presets  []string           // Préréglages classés (noms du registre des difficultés)
+}
+
+// NewAccounts crée le gestionnaire de comptes selon le stockage choisi
+//
+// Paramètres:
+//   - kind: "memory" (aucune persistance) ou "file" (DATA_DIR/users.json)
+//   - dataDir: dossier de données utilisé par le stockage "file"
+//
+// Retourne:
+//   - *Accounts: comptes rechargés depuis le fichier s'il existe
+//   - error: si le type est inconnu ou le fichier illisible
+func NewAccounts(kind, dataDir string) (*Accounts, error) {
+	accounts := newAccounts()
+	switch kind {
+	case "", "memory":
+		return accounts, nil
+	case "file":
+		if err := os.MkdirAll(dataDir, 0o755); err != nil {
+			return nil, fmt.Errorf("création du dossier %s: %w", dataDir, err)
+		}
+		accounts.path = filepath.Join(dataDir, "users.json")
+		return accounts, accounts.load()
+	}
+	return nil, fmt.Errorf("stockage inconnu: %q (attendu: memory ou file)", kind)
+}
+
+// newAccounts crée un gestionnaire de comptes en mémoire, sans compte
+func newAccounts() *Accounts {
+	return &Accounts{
+		users:    make(map[string]*User),
+		names:    make(map[string]string),
+		sessions: make(map[string]session),
+	}
+}
+
+// load relit le fichier des comptes (absent au premier démarrage)
+func (a *Accounts) load() error {
+	data, err := os.ReadFile(a.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var users []*User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return fmt.Errorf("%s: %w", a.path, err)
+	}
+	for _, u := range users {
+		a.users[u.ID] = u
+		a.names[strings.ToLower(u.Username)] = u.ID
+	}
+	return nil
+}
+
+// save écrit tous les comptes dans le fichier (sans effet en mémoire)
+//
+// L'appelant doit détenir a.mu.
+func (a *Accounts) save() error {
+	if a.path == "" {
+		return nil
+	}
+	users := make([]*User, 0, len(a.users))
+	for _, u := range a.users {
+		users = append(users, u)
+	}
+	return writeJSONFile(a.path, users)
+}
+
+//#endregion
+
+//#region INSCRIPTION ET CONNEXION
+
+// Register crée un compte
+//
+// Paramètres:
+//   - username: identifiant choisi (3 à 20 lettres, chiffres, "_" ou "-")
+//   - password: mot de passe (au moins minPasswordLength caractères)
+//
+// Retourne:
+//   - Profile: le compte créé
+//   - error: identifiant invalide ou déjà pris, mot de passe trop court
+func (a *Accounts) Register(username, password string) (Profile, error) {
+	if !usernamePattern.MatchString(username) {
+		return Profile{}, errors.New("l'identifiant doit faire 3 à 20 caractères (lettres, chiffres, _ ou -)")
+	}
+	if len([]rune(password)) < minPasswordLength {
+		return Profile{}, fmt.Errorf("le mot de passe doit faire au moins %d caractères", minPasswordLength)
+	}
+	hash := hashPassword(password) // Hors verrou : le hachage est volontairement lent
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, taken := a.names[strings.ToLower(username)]; taken {
+		return Profile{}, errors.New("cet identifiant est déjà pris")
+	}
+
+	user := &User{ID: newSecret(8), Username: username, PasswordHash: hash, CreatedAt: time.Now()}
+	for a.users[user.ID] != nil {
+		user.ID = newSecret(8)
+	}
+	a.users[user.ID] = user
+	a.names[strings.ToLower(username)] = user.ID
+	if err := a.save(); err != nil {
+		delete(a.users, user.ID)
+		delete(a.names, strings.ToLower(username))
+		return Profile{}, err
+	}
+	return user.profile(), nil
+}
+
+// Login vérifie un mot de passe et ouvre une session
+//
+// Paramètres:
+//   - username: identifiant du compte (insensible à la casse)
+//   - password: mot de passe
+//
+// Retourne:
+//   - string: jeton de la nouvelle session
+//   - Profile: le compte connecté
+//   - error: errBadCredentials si le compte n'existe pas ou si le mot de passe est faux
+func (a *Accounts) Login(username, password string) (string, Profile, error) {
+	a.mu.RLock()
+	user := a.users[a.names[strings.ToLower(username)]]
+	a.mu.RUnlock()
+
+	if user == nil || !verifyPassword(user.PasswordHash, password) {
+		return "", Profile{}, errBadCredentials
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.purgeSessions()
+	token := newSecret(32)
+	a.sessions[token] = session{userID: user.ID, expires: time.Now().Add(sessionDuration)}
+	return token, user.profile(), nil
+}
+
+// Logout ferme une session (sans effet si elle n'existe pas)
+func (a *Accounts) Logout(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.sessions, token)
+}
+
+// purgeSessions retire les sessions expirées
+//
+// L'appelant doit détenir a.mu en écriture.
+func (a *Accounts) purgeSessions() {
+	now := time.Now()
+	for token, s := range a.sessions {
+		if now.After(s.expires) {
+			delete(a.sessions, token)
+		}
+	}
+}
+
+// Get retrouve un compte par son identifiant
+//
+// Retourne:
+//   - Profile: le compte
+//   - bool: false si le compte n'existe pas
+func (a *Accounts) Get(id string) (Profile, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	user, ok := a.users[id]
+	if !ok {
+		return Profile{}, false
+	}
+	return user.profile(), true
+}
+
+// FromRequest retrouve le compte connecté d'une requête (cookie de session)
+//
+// Retourne:
+//   - Profile: le compte connecté
+//   - bool: false sans session valide
+func (a *Accounts) FromRequest(r *http.Request) (Profile, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return Profile{}, false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	s, ok := a.sessions[cookie.Value]
+	if !ok || time.Now().After(s.expires) {
+		return Profile{}, false
+	}
+	user, ok := a.users[s.userID]
+	if !ok {
+		return Profile{}, false
+	}
+	return user.profile(), true
+}
+
+// SetSkin enregistre le skin préféré d'un compte
+//
+// Paramètres:
+//   - id: identifiant du compte
+//   - skin: skin de jeton ("skin1" à "skin8")
+//
+// Retourne:
+//   - Profile: le compte mis à jour
+//   - error: skin inconnu, compte introuvable ou erreur d'écriture
+func (a *Accounts) SetSkin(id, skin string) (Profile, error) {
+	if !skinPattern.MatchString(skin) {
+		return Profile{}, fmt.Errorf("skin inconnu: %q", skin)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	user, ok := a.users[id]
+	if !ok {
+		return Profile{}, errors.New("compte introuvable")
+	}
+	previous := user.Skin
+	user.Skin = skin
+	if err := a.save(); err != nil {
+		user.Skin = previous
+		return Profile{}, err
+	}
+	return user.profile(), nil
+}
+
+//#endregion
+
+//#region HACHAGE DES MOTS DE PASSE
+
+// hashPassword hache un mot de passe avec un sel aléatoire
+//
+// Format: "pbkdf2-sha256$<itérations>$<sel base64>$<hachage base64>"
+// Le nombre d'itérations est conservé pour pouvoir l'augmenter plus
+// tard sans invalider les comptes existants.
+func hashPassword(password string) string {
+	salt := []byte(newSecret(16))
+	key := pbkdf2SHA256([]byte(password), salt, passwordIterations)
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+// verifyPassword vérifie un mot de passe contre son hachage (en temps constant)
+func verifyPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	got := pbkdf2SHA256([]byte(password), salt, iterations)
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// pbkdf2SHA256 dérive une clé de 32 octets (PBKDF2, RFC 8018, avec HMAC-SHA256)
+//
+// Une clé de la taille d'un seul bloc HMAC suffit : un seul bloc est calculé.
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1}) // Numéro du bloc
+	u := mac.Sum(nil)
+
+	key := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
+
+//#endregion
+
+//#region HANDLERS HTTP
+
+// credentialsRequest est le corps des requêtes d'inscription et de connexion
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// setSessionCookie dépose le cookie de session (ou l'efface si token est vide)
+func setSessionCookie(w http.ResponseWriter, token string) {
+	cookie := &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(sessionDuration / time.Second),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if token == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+// HandleRegister crée un compte et le connecte aussitôt
+//
+// Route: POST /api/account/register
+// Body JSON attendu:
+//
+//	{
+//	  "username": "alice",
+//	  "password": "motdepasse"
+//	}
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request contenant l'identifiant et le mot de passe
+//
+// Réponse:
+//   - 200 OK: Profil du compte créé (et cookie de session)
+//   - 400 Bad Request: Identifiant invalide ou déjà pris, mot de passe trop court
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (a *Accounts) HandleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	var req credentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Format JSON invalide")
+		return
+	}
+
+	if _, err := a.Register(req.Username, req.Password); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	token, profile, err := a.Login(req.Username, req.Password)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	setSessionCookie(w, token)
+	respondJSON(w, http.StatusOK, profile)
+}
+
+// HandleLogin ouvre une session
+//
+// Route: POST /api/account/login
+// Body JSON attendu: {"username": "alice", "password": "motdepasse"}
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request contenant l'identifiant et le mot de passe
+//
+// Réponse:
+//   - 200 OK: Profil du compte (et cookie de session)
+//   - 400 Bad Request: Format JSON invalide
+//   - 401 Unauthorized: Identifiant ou mot de passe incorrect
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (a *Accounts) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	var req credentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Format JSON invalide")
+		return
+	}
+
+	token, profile, err := a.Login(req.Username, req.Password)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	setSessionCookie(w, token)
+	respondJSON(w, http.StatusOK, profile)
+}
+
+// HandleLogout ferme la session de la requête
+//
+// Route: POST /api/account/logout
+//
+// Réponse:
+//   - 200 OK: Message de confirmation (et cookie de session effacé)
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (a *Accounts) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		a.Logout(cookie.Value)
+	}
+	setSessionCookie(w, "")
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Déconnecté"})
+}
+
+// HandleMe retourne le compte connecté
+//
+// Route: GET /api/account/me
+//
+// Réponse:
+//   - 200 OK: Profil du compte connecté
+//   - 401 Unauthorized: Aucune session valide
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (a *Accounts) HandleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	profile, ok := a.FromRequest(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, errNotLoggedIn.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, profile)
+}
+
+// HandleSkin enregistre le skin préféré du compte connecté
+//
+// Route: POST /api/account/skin
+// Body JSON attendu: {"skin": "skin3"}
+//
+// Réponse:
+//   - 200 OK: Profil mis à jour
+//   - 400 Bad Request: Skin inconnu
+//   - 401 Unauthorized: Aucune session valide
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (a *Accounts) HandleSkin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	profile, ok := a.FromRequest(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, errNotLoggedIn.Error())
+		return
+	}
+
+	var req struct {
+		Skin string `json:"skin"` // Skin de jeton ("skin1" à "skin8")
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Format JSON invalide")
+		return
+	}
+
+	profile, err := a.SetSkin(profile.ID, req.Skin)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, profile)
+}
+
+//#endregion
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+//#region OUTILS DE TEST
+
+// loginCookie inscrit un compte via l'API et retourne son cookie de session
+func loginCookie(t *testing.T, accounts *Accounts, username string) *http.Cookie {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	accounts.HandleRegister(rec, httptest.NewRequest("POST", "/api/account/register",
+		strings.NewReader(`{"username":"`+username+`","password":"motdepasse"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("inscription de %s: %d %s", username, rec.Code, rec.Body.String())
+	}
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == sessionCookie {
+			return cookie
+		}
+	}
+	t.Fatal("aucun cookie de session")
+	return nil
+}
+
+//#endregion
+
+//#region TESTS DES COMPTES
+
+// TestPBKDF2 vérifie la dérivation de clé contre le vecteur de la RFC 7914
+func TestPBKDF2(t *testing.T) {
+	got := hex.EncodeToString(pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1))
+	if want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"; got != want {
+		t.Fatalf("PBKDF2: %s, attendu %s", got, want)
+	}
+	hash := hashPassword("secret123")
+	if !verifyPassword(hash, "secret123") || verifyPassword(hash, "secret124") {
+		t.Fatal("vérification du mot de passe incorrecte")
+	}
+	if hashPassword("secret123") == hash {
+		t.Fatal("deux hachages du même mot de passe doivent différer (sel)")
+	}
+}
+
+// TestRegisterAndLogin vérifie l'inscription, la connexion et leurs refus
+func TestRegisterAndLogin(t *testing.T) {
+	accounts := newAccounts()
+	if _, err := accounts.Register("Alice", "motdepasse"); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range [][2]string{{"alice", "motdepasse"}, {"al", "motdepasse"}, {"bob!", "motdepasse"}, {"bob", "court"}} {
+		if _, err := accounts.Register(bad[0], bad[1]); err == nil {
+			t.Fatalf("inscription %q acceptée", bad[0])
+		}
+	}
+
+	if _, _, err := accounts.Login("alice", "mauvais"); err != errBadCredentials {
+		t.Fatalf("mauvais mot de passe: %v", err)
+	}
+	if _, _, err := accounts.Login("inconnu", "motdepasse"); err != errBadCredentials {
+		t.Fatalf("compte inconnu: %v", err)
+	}
+	token, profile, err := accounts.Login("ALICE", "motdepasse")
+	if err != nil || token == "" || profile.Username != "Alice" {
+		t.Fatalf("connexion: %q %+v %v", token, profile, err)
+	}
+}
+
+// TestSessionHandlers vérifie le cookie de session, le profil, le skin et la déconnexion
+func TestSessionHandlers(t *testing.T) {
+	accounts := newAccounts()
+	cookie := loginCookie(t, accounts, "alice")
+	if !cookie.HttpOnly {
+		t.Fatal("le cookie de session doit être HttpOnly")
+	}
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		http.HandlerFunc(map[string]http.HandlerFunc{
+			"/api/account/me":     accounts.HandleMe,
+			"/api/account/skin":   accounts.HandleSkin,
+			"/api/account/logout": accounts.HandleLogout,
+		}[path]).ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request("POST", "/api/account/skin", `{"skin":"skin9"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("skin inconnu: %d", rec.Code)
+	}
+	if rec := request("POST", "/api/account/skin", `{"skin":"skin3"}`); rec.Code != http.StatusOK {
+		t.Fatalf("skin: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := request("GET", "/api/account/me", ""); !strings.Contains(rec.Body.String(), `"skin":"skin3"`) {
+		t.Fatalf("profil: %d %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(request("GET", "/api/account/me", "").Body.String(), "passwordHash") {
+		t.Fatal("le profil ne doit pas contenir le mot de passe")
+	}
+
+	request("POST", "/api/account/logout", "")
+	if rec := request("GET", "/api/account/me", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("session encore valide après déconnexion: %d", rec.Code)
+	}
+}
+
+// TestAccountsPersist vérifie que les comptes survivent à un redémarrage (stockage "file")
+func TestAccountsPersist(t *testing.T) {
+	dir := t.TempDir()
+	accounts, err := NewAccounts("file", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, _ := accounts.Register("alice", "motdepasse")
+	accounts.SetSkin(profile.ID, "skin5")
+
+	restarted, err := NewAccounts("file", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := restarted.Login("alice", "motdepasse"); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, ok := restarted.Get(profile.ID); !ok || reloaded.Skin != "skin5" {
+		t.Fatalf("compte rechargé: %+v", reloaded)
+	}
+}
+
+// TestNewGameBindSeat vérifie la liaison d'un siège au compte connecté
+func TestNewGameBindSeat(t *testing.T) {
+	accounts := newAccounts()
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), accounts)
+	body := `{"rows":6,"cols":7,"player2":"Bob","bindSeat":"player1"}`
+
+	rec := httptest.NewRecorder()
+	gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new", strings.NewReader(body)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("liaison sans session: %d", rec.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/api/game/new", strings.NewReader(body))
+	req.AddCookie(loginCookie(t, accounts, "alice"))
+	rec = httptest.NewRecorder()
+	gm.HandleNewGame(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("liaison: %d %s", rec.Code, rec.Body.String())
+	}
+	state := decodeState(t, rec)
+	profile, _ := accounts.FromRequest(req)
+	users, _ := state["users"].(map[string]interface{})
+	if state["player1"] != "alice" || users["player1"] != profile.ID {
+		t.Fatalf("siège non lié: %v", state)
+	}
+}
+
+//#endregion
@@ -0,0 +1,302 @@
+package main
+
+//#region NIVEAUX DE L'ORDINATEUR
+
+// Niveaux de difficulté de l'ordinateur
+//
+// Le niveau 1 joue un coup légal au hasard, les niveaux suivants utilisent
+// un minimax avec élagage alpha-bêta de plus en plus profond.
+const (
+	BotLevelMin = 1 // Coups aléatoires
+	BotLevelMax = 5 // Recherche la plus profonde
+)
+
+// botSearchDepth associe à chaque niveau la profondeur de recherche (en demi-coups)
+var botSearchDepth = map[int]int{
+	1: 0, // Aléatoire, pas de recherche
+	2: 1, // Ne voit que le coup immédiat
+	3: 3,
+	4: 5,
+	5: 7,
+}
+
+// botWinScore est la valeur d'une victoire dans l'évaluation
+// (bien supérieure à tout score heuristique)
+const botWinScore = 1000000
+
+//#endregion
+
+//#region TOUR DE L'ORDINATEUR
+
+// PlayBotTurn fait jouer l'ordinateur si c'est à son tour
+//
+// Ne fait rien si la partie n'a pas d'ordinateur, si elle est terminée
+// ou si c'est au tour d'un humain.
+//
+// Retourne:
+//   - bool: true si l'ordinateur a joué un coup
+func (g *Game) PlayBotTurn() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.playBotTurn()
+}
+
+// playBotTurn contient la logique de PlayBotTurn, sans verrouillage
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) playBotTurn() bool {
+	if g.Bot == "" || g.GameOver || g.CurrentPlayer != g.Bot {
+		return false
+	}
+
+	col := g.chooseBotMove()
+	if col < 0 {
+		return false
+	}
+	return g.dropPiece(col, false) == nil
+}
+
+// chooseBotMove choisit la colonne jouée par l'ordinateur selon son niveau
+//
+// Retourne:
+//   - int: colonne choisie (-1 si aucun coup n'est possible)
+func (g *Game) chooseBotMove() int {
+	moves := g.legalMoves()
+	if len(moves) == 0 {
+		return -1
+	}
+
+	// Niveau le plus faible : un coup légal au hasard
+	depth := botSearchDepth[g.BotLevel]
+	if depth == 0 {
+		return moves[g.random().Intn(len(moves))]
+	}
+
+	// Recherche sur une copie pour ne jamais toucher au vrai plateau
+	sim := g.clone()
+	best := []int{}
+	bestScore := -botWinScore * 2
+	for _, col := range moves {
+		score, ok := sim.searchMove(col, depth-1, -botWinScore*2, botWinScore*2)
+		if !ok {
+			continue
+		}
+		if score > bestScore {
+			bestScore = score
+			best = []int{col}
+		} else if score == bestScore {
+			best = append(best, col)
+		}
+	}
+
+	// Départage aléatoire entre coups équivalents pour varier les parties
+	return best[g.random().Intn(len(best))]
+}
+
+//#endregion
+
+//#region RECHERCHE MINIMAX
+
+// searchMove joue un coup, l'évalue par negamax puis l'annule
+//
+// Le coup est joué avec applyMove : la recherche respecte donc exactement
+// les règles de la partie (gravité inversée, jetons pré-remplis...).
+// Il n'est pas inscrit dans l'historique de la partie.
+//
+// Paramètres:
+//   - col: colonne à jouer
+//   - depth: profondeur restante après ce coup
+//   - alpha, beta: fenêtre alpha-bêta du point de vue du joueur qui joue
+//
+// Retourne:
+//   - int: score du coup pour le joueur qui le joue
+//   - bool: false si le coup est illégal
+func (g *Game) searchMove(col, depth, alpha, beta int) (int, bool) {
+	mover := g.CurrentPlayer
+	before := g.saveTurnState()
+	row, err := g.applyMove(col, false)
+	if err != nil {
+		return 0, false
+	}
+
+	var score int
+	switch {
+	case g.GameOver && g.Winner == "draw":
+		score = 0
+	case g.GameOver:
+		// Les victoires rapides valent plus que les victoires lointaines
+		score = botWinScore + depth
+	case depth == 0:
+		score = g.evaluate(mover)
+	default:
+		score = -g.negamax(depth, -beta, -alpha)
+	}
+
+	g.Board.Clear(row, col)
+	g.loadTurnState(before)
+	return score, true
+}
+
+// negamax retourne le meilleur score atteignable par le joueur actuel
+//
+// Paramètres:
+//   - depth: nombre de demi-coups restant à explorer
+//   - alpha, beta: fenêtre alpha-bêta
+//
+// Retourne:
+//   - int: score du point de vue de g.CurrentPlayer
+func (g *Game) negamax(depth, alpha, beta int) int {
+	best := -botWinScore * 2
+	played := false
+
+	for _, col := range g.orderedColumns() {
+		score, ok := g.searchMove(col, depth-1, alpha, beta)
+		if !ok {
+			continue
+		}
+		played = true
+		if score > best {
+			best = score
+		}
+		if best > alpha {
+			alpha = best
+		}
+		// Coupure : l'adversaire n'autorisera jamais cette branche
+		if alpha >= beta {
+			break
+		}
+	}
+
+	// Aucun coup possible : partie nulle
+	if !played {
+		return 0
+	}
+	return best
+}
+
+//#endregion
+
+//#region ÉVALUATION HEURISTIQUE
+
+// evaluate note la position du point de vue d'un joueur
+//
+// Chaque fenêtre de WinLength cases ne contenant les jetons que d'un seul
+// joueur rapporte des points à ce joueur (d'autant plus qu'elle est remplie),
+// et les jetons proches du centre sont favorisés.
+//
+// Paramètres:
+//   - player: joueur pour lequel la position est évaluée
+//
+// Retourne:
+//   - int: score positif si la position est favorable au joueur
+func (g *Game) evaluate(player string) int {
+	n := g.WinLength
+	mine := *g.Board.mask(player)
+	theirs := g.Board.player2
+	if player == "player2" {
+		theirs = g.Board.player1
+	}
+	score := 0
+
+	// Bonus de centralité
+	for col := 0; col < g.Cols; col++ {
+		bonus := g.Cols/2 - abs(col-g.Cols/2)
+		column := g.Board.columnMask(col)
+		score += bonus * (mine.and(column).count() - theirs.and(column).count())
+	}
+
+	// Fenêtres de n cases (un obstacle ferme la fenêtre aux deux joueurs)
+	for _, window := range g.Board.windows(n) {
+		if !window.and(g.Board.blocked).isZero() {
+			continue
+		}
+		m, t := window.and(mine).count(), window.and(theirs).count()
+		if t == 0 && m < n {
+			score += windowWeight(m)
+		} else if m == 0 && t < n {
+			score -= windowWeight(t)
+		}
+	}
+
+	return score
+}
+
+// windowWeight retourne les points d'une fenêtre selon son nombre de jetons
+//
+// Une fenêtre vide ne rapporte rien, puis chaque jeton supplémentaire
+// multiplie la valeur par 4 (0, 1, 4, 16 pour le Puissance 4 classique).
+func windowWeight(tokens int) int {
+	if tokens == 0 {
+		return 0
+	}
+	return 1 << (2 * (tokens - 1))
+}
+
+//#endregion
+
+//#region UTILITAIRES
+
+// legalMoves liste les colonnes qui ne sont pas pleines
+func (g *Game) legalMoves() []int {
+	moves := []int{}
+	for col := 0; col < g.Cols; col++ {
+		if !g.Board.ColumnFull(col) {
+			moves = append(moves, col)
+		}
+	}
+	return moves
+}
+
+// orderedColumns liste les colonnes du centre vers les bords
+//
+// Explorer d'abord les coups centraux (souvent les meilleurs)
+// rend l'élagage alpha-bêta beaucoup plus efficace.
+func (g *Game) orderedColumns() []int {
+	cols := make([]int, 0, g.Cols)
+	center := (g.Cols - 1) / 2
+	cols = append(cols, center)
+	for offset := 1; len(cols) < g.Cols; offset++ {
+		if center+offset < g.Cols {
+			cols = append(cols, center+offset)
+		}
+		if center-offset >= 0 {
+			cols = append(cols, center-offset)
+		}
+	}
+	return cols
+}
+
+// clone retourne une copie indépendante de la partie (sans son verrou
+// ni son historique)
+//
+// Le plateau étant un bitboard (une valeur), la copie est immédiate.
+func (g *Game) clone() *Game {
+	return &Game{
+		ID:             g.ID,
+		Rows:           g.Rows,
+		Cols:           g.Cols,
+		WinLength:      g.WinLength,
+		Board:          g.Board,
+		CurrentPlayer:  g.CurrentPlayer,
+		Player1:        g.Player1,
+		Player2:        g.Player2,
+		GameOver:       g.GameOver,
+		Winner:         g.Winner,
+		LastMove:       g.LastMove,
+		TurnCount:      g.TurnCount,
+		InverseGravity: g.InverseGravity,
+		Gravity:        g.Gravity,
+		Bot:            g.Bot,
+		BotLevel:       g.BotLevel,
+	}
+}
+
+// abs retourne la valeur absolue d'un entier
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+//#endregion
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+//#region TESTS DE L'ORDINATEUR
+
+// newEmptyGame crée une partie sans jetons pré-remplis
+func newEmptyGame(rows, cols int) *Game {
+	g := NewGame(rows, cols, "Alice", "Bob")
+	g.Board = NewBitboard(rows, cols)
+	return g
+}
+
+// TestBotTakesWinningMove vérifie que l'ordinateur gagne quand il le peut
+func TestBotTakesWinningMove(t *testing.T) {
+	for level := 2; level <= BotLevelMax; level++ {
+		g := newEmptyGame(6, 7)
+		g.Bot, g.BotLevel = "player2", level
+		g.CurrentPlayer = "player2"
+		g.Board.Set(5, 0, "player2")
+		g.Board.Set(5, 1, "player2")
+		g.Board.Set(5, 2, "player2")
+		g.Board.Set(4, 0, "player1")
+		g.Board.Set(4, 1, "player1")
+		g.Board.Set(4, 2, "player1")
+		g.TurnCount = 6
+
+		if !g.PlayBotTurn() {
+			t.Fatalf("niveau %d: l'ordinateur n'a pas joué", level)
+		}
+		if g.Winner != "player2" {
+			t.Fatalf("niveau %d: coup gagnant manqué (colonne %d)", level, g.LastMove.Col)
+		}
+	}
+}
+
+// TestBotBlocksImmediateLoss vérifie que l'ordinateur bloque une victoire adverse
+func TestBotBlocksImmediateLoss(t *testing.T) {
+	g := newEmptyGame(6, 7)
+	g.Bot, g.BotLevel = "player2", 3
+	g.CurrentPlayer = "player2"
+	g.Board.Set(5, 2, "player1")
+	g.Board.Set(5, 3, "player1")
+	g.Board.Set(5, 4, "player1")
+	g.Board.Set(4, 3, "player2")
+	g.TurnCount = 4
+
+	g.PlayBotTurn()
+	if col := g.LastMove.Col; col != 1 && col != 5 {
+		t.Fatalf("menace non bloquée: l'ordinateur a joué en %d", col)
+	}
+}
+
+// TestBotHandlesGravityFlip vérifie que l'ordinateur joue selon la gravité
+// déjà inversée: le jeton tombe vers le haut.
+func TestBotHandlesGravityFlip(t *testing.T) {
+	g := newEmptyGame(6, 7)
+	g.Bot, g.BotLevel = "player2", 2
+	g.CurrentPlayer = "player2"
+	g.InverseGravity = true
+	g.TurnCount = 5
+	g.Board.Set(0, 0, "player2")
+	g.Board.Set(0, 1, "player2")
+	g.Board.Set(0, 2, "player2")
+	g.Board.Set(5, 0, "player1")
+	g.Board.Set(5, 1, "player1")
+
+	g.PlayBotTurn()
+	if g.Winner != "player2" || g.LastMove.Row != 0 {
+		t.Fatalf("victoire en gravité inversée manquée: %+v", g.LastMove)
+	}
+}
+
+// TestBotAnticipatesGravityFlip vérifie que la recherche voit une inversion
+// de gravité qui survient pendant son anticipation.
+//
+// player1 menace d'aligner la ligne du bas en colonne 3. Sans inversion,
+// l'ordinateur doit bloquer. Avec la règle classique, son coup (5e tour)
+// inverse la gravité : la menace du bas disparaît, et le seul jeton qui
+// reste en haut de la colonne 5 lui donne deux menaces sur la ligne du haut.
+func TestBotAnticipatesGravityFlip(t *testing.T) {
+	choose := func(gravity GravityRule) int {
+		g := newEmptyGame(6, 7)
+		g.Gravity = gravity
+		g.Bot, g.BotLevel = "player2", 3
+		g.CurrentPlayer = "player2"
+		g.TurnCount = 4
+		for _, cell := range []struct {
+			row, col int
+			player   string
+		}{
+			{5, 0, "player1"}, {5, 1, "player1"}, {5, 2, "player1"}, // Menace du bas (5,3)
+			{0, 3, "player2"}, {0, 4, "player2"}, // Ligne du haut
+			{1, 5, "player2"}, {2, 5, "player1"}, {3, 5, "player2"}, {4, 5, "player1"}, {5, 5, "player2"},
+		} {
+			g.Board.Set(cell.row, cell.col, cell.player)
+		}
+		return g.chooseBotMove()
+	}
+
+	off, _ := ResolveGravityRule(GravityRule{Preset: "off"}, 0)
+	classic, _ := ResolveGravityRule(GravityRule{Preset: "classic"}, 0)
+	if col := choose(off); col != 3 {
+		t.Fatalf("sans inversion, la menace du bas doit être bloquée: colonne %d", col)
+	}
+	for i := 0; i < 10; i++ {
+		if col := choose(classic); col != 5 {
+			t.Fatalf("inversion au 5e tour ignorée: colonne %d au lieu de 5", col)
+		}
+	}
+}
+
+// TestBotStrongestLevelIsFast vérifie que le niveau maximal répond rapidement
+// sur le plus grand plateau autorisé.
+func TestBotStrongestLevelIsFast(t *testing.T) {
+	g := newEmptyGame(10, 10)
+	g.Bot, g.BotLevel = "player1", BotLevelMax
+
+	start := time.Now()
+	g.PlayBotTurn()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("coup calculé en %v", elapsed)
+	}
+}
+
+//#endregion
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//#region ARCHIVE DES PARTIES TERMINÉES
+
+const (
+	// defaultArchivePageSize et maxArchivePageSize bornent le paramètre "pageSize" de /api/games
+	defaultArchivePageSize = 20
+	maxArchivePageSize     = 100
+
+	// maxArchivePage borne le paramètre "page" : le décalage de la page
+	// ((page-1) * pageSize) ne doit pas dépasser la capacité d'un int
+	maxArchivePage = math.MaxInt / maxArchivePageSize
+)
+
+// ArchivedGame est une partie terminée conservée dans l'archive
+//
+// Elle contient la relecture complète (disposition initiale et coups)
+// et survit à la suppression de la partie par /api/game/reset.
+type ArchivedGame struct {
+	Replay
+	Difficulty string            `json:"difficulty"`      // Préréglage ("" pour un plateau personnalisé)
+	Reason     string            `json:"reason"`          // Cause de la fin de partie
+	TurnCount  int               `json:"turnCount"`       // Nombre de coups joués
+	Bot        string            `json:"bot"`             // Joueur contrôlé par l'ordinateur ("" si aucun)
+	BotLevel   int               `json:"botLevel"`        // Niveau de l'ordinateur
+	Seed       int64             `json:"seed"`            // Graine de la disposition initiale
+	Users      map[string]string `json:"users,omitempty"` // Compte lié à chaque siège
+	StartedAt  time.Time         `json:"startedAt"`       // Création de la partie
+	EndedAt    time.Time         `json:"endedAt"`         // Fin de la partie
+}
+
+// ArchiveSummary est une ligne de la liste des parties archivées (sans les coups)
+type ArchiveSummary struct {
+	ID         string            `json:"id"`
+	Difficulty string            `json:"difficulty"`
+	Rows       int               `json:"rows"`
+	Cols       int               `json:"cols"`
+	Player1    string            `json:"player1"`
+	Player2    string            `json:"player2"`
+	Users      map[string]string `json:"users,omitempty"`
+	Bot        string            `json:"bot"`
+	Winner     string            `json:"winner"`
+	Reason     string            `json:"reason"`
+	TurnCount  int               `json:"turnCount"`
+	StartedAt  time.Time         `json:"startedAt"`
+	EndedAt    time.Time         `json:"endedAt"`
+}
+
+// summary retourne la ligne de liste d'une partie archivée
+func (a ArchivedGame) summary() ArchiveSummary {
+	return ArchiveSummary{
+		ID: a.ID, Difficulty: a.Difficulty, Rows: a.Rows, Cols: a.Cols,
+		Player1: a.Player1, Player2: a.Player2, Users: a.Users, Bot: a.Bot,
+		Winner: a.Winner, Reason: a.Reason, TurnCount: a.TurnCount,
+		StartedAt: a.StartedAt, EndedAt: a.EndedAt,
+	}
+}
+
+// archived retourne la forme archivée de la partie
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) archived() ArchivedGame {
+	users := make(map[string]string, len(g.Users))
+	for seat, id := range g.Users {
+		users[seat] = id
+	}
+	return ArchivedGame{
+		Replay:     g.replay(),
+		Difficulty: g.Difficulty,
+		Reason:     g.Reason,
+		TurnCount:  g.TurnCount,
+		Bot:        g.Bot,
+		BotLevel:   g.BotLevel,
+		Seed:       g.Seed,
+		Users:      users,
+		StartedAt:  g.CreatedAt,
+		EndedAt:    g.EndedAt,
+	}
+}
+
+// archiveGame tient l'archive à jour quand une partie se termine ou est rouverte
+//
+// Une partie est archivée quand elle passe d'en cours à terminée : EndedAt
+// retient la date de cette fin, qu'un nouvel enregistrement de la partie
+// terminée ne modifie plus. Une partie terminée rouverte par l'annulation
+// d'un coup (voir Undo) est retirée de l'archive : une victoire annulée
+// ne doit pas rester dans l'archive ni dans les statistiques. Une erreur
+// d'écriture est signalée dans les logs sans interrompre la partie.
+//
+// L'appelant doit détenir game.mu.
+func (gm *GameManager) archiveGame(game *Game) {
+	switch {
+	case game.GameOver && game.EndedAt.IsZero():
+		game.EndedAt = time.Now()
+		archived := game.archived()
+		if err := gm.store.Archive(archived); err != nil {
+			log.Printf("Erreur d'archivage de la partie %s: %v", game.ID, err)
+		}
+		gm.archive.put(archived)
+	case !game.GameOver && !game.EndedAt.IsZero():
+		game.EndedAt = time.Time{}
+		if err := gm.store.Unarchive(game.ID); err != nil {
+			log.Printf("Erreur de retrait de l'archive de la partie %s: %v", game.ID, err)
+		}
+		gm.archive.remove(game.ID)
+	}
+}
+
+// GameArchive indexe en mémoire les parties archivées pour la recherche
+type GameArchive struct {
+	mu    sync.RWMutex            // Protège la map des parties
+	games map[string]ArchivedGame // Parties archivées, indexées par identifiant
+}
+
+// newGameArchive crée un index d'archive vide
+func newGameArchive() *GameArchive {
+	return &GameArchive{games: make(map[string]ArchivedGame)}
+}
+
+// put ajoute (ou remplace) une partie dans l'index
+func (a *GameArchive) put(game ArchivedGame) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.games[game.ID] = game
+}
+
+// remove retire une partie de l'index
+func (a *GameArchive) remove(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.games, id)
+}
+
+// Get retrouve une partie archivée
+//
+// Retourne:
+//   - ArchivedGame: la partie
+//   - bool: false si elle n'est pas dans l'archive
+func (a *GameArchive) Get(id string) (ArchivedGame, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	game, ok := a.games[id]
+	return game, ok
+}
+
+// PlayedBy retourne les parties archivées dont un siège est lié à un compte
+//
+// Paramètres:
+//   - id: identifiant du compte
+//
+// Retourne:
+//   - []ArchivedGame: parties du compte, dans un ordre quelconque
+func (a *GameArchive) PlayedBy(id string) []ArchivedGame {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var played []ArchivedGame
+	for _, game := range a.games {
+		if game.Users["player1"] == id || game.Users["player2"] == id {
+			played = append(played, game)
+		}
+	}
+	return played
+}
+
+//#endregion
+
+//#region RECHERCHE DANS L'ARCHIVE
+
+// ArchiveQuery décrit une recherche dans l'archive (critère vide = ignoré)
+type ArchiveQuery struct {
+	Player     string    // Pseudo (insensible à la casse) ou identifiant de compte de l'un des joueurs
+	Difficulty string    // Préréglage (customDifficulty pour un plateau personnalisé)
+	Result     string    // "player1", "player2", "draw", ou ResultWin / ResultLoss du point de vue de Player
+	From       time.Time // Fin de partie au plus tôt (zéro = sans borne)
+	To         time.Time // Fin de partie au plus tard (zéro = sans borne)
+	Page       int       // Page demandée, à partir de 1
+	PageSize   int       // Nombre de parties par page
+}
+
+// seatsOf retourne les sièges occupés par un joueur (pseudo ou compte)
+func (a ArchivedGame) seatsOf(player string) []string {
+	var seats []string
+	for seat, pseudo := range map[string]string{"player1": a.Player1, "player2": a.Player2} {
+		if strings.EqualFold(pseudo, player) || a.Users[seat] == player {
+			seats = append(seats, seat)
+		}
+	}
+	return seats
+}
+
+// matches indique si une partie archivée répond aux critères de la recherche
+func (q ArchiveQuery) matches(game ArchivedGame) bool {
+	var seats []string
+	if q.Player != "" {
+		if seats = game.seatsOf(q.Player); len(seats) == 0 {
+			return false
+		}
+	}
+
+	difficulty := game.Difficulty
+	if difficulty == "" {
+		difficulty = customDifficulty
+	}
+	if q.Difficulty != "" && q.Difficulty != difficulty {
+		return false
+	}
+
+	switch q.Result {
+	case "":
+	case ResultWin, ResultLoss:
+		won := false
+		for _, seat := range seats {
+			won = won || game.Winner == seat
+		}
+		if game.Winner == "draw" || won != (q.Result == ResultWin) {
+			return false
+		}
+	default:
+		if game.Winner != q.Result {
+			return false
+		}
+	}
+
+	if !q.From.IsZero() && game.EndedAt.Before(q.From) {
+		return false
+	}
+	return q.To.IsZero() || !game.EndedAt.After(q.To)
+}
+
+// Search retourne une page des parties archivées répondant à une recherche
+//
+// Les parties sont triées de la plus récente à la plus ancienne.
+//
+// Paramètres:
+//   - q: critères et page demandée
+//
+// Retourne:
+//   - []ArchiveSummary: parties de la page (vide au-delà de la dernière)
+//   - int: nombre total de parties répondant aux critères
+func (a *GameArchive) Search(q ArchiveQuery) ([]ArchiveSummary, int) {
+	a.mu.RLock()
+	found := []ArchivedGame{}
+	for _, game := range a.games {
+		if q.matches(game) {
+			found = append(found, game)
+		}
+	}
+	a.mu.RUnlock()
+
+	sort.Slice(found, func(i, j int) bool {
+		if !found[i].EndedAt.Equal(found[j].EndedAt) {
+			return found[i].EndedAt.After(found[j].EndedAt)
+		}
+		return found[i].ID < found[j].ID
+	})
+
+	// Au-delà de la dernière page, la page est vide (sans calculer un décalage démesuré)
+	page := []ArchiveSummary{}
+	if q.Page-1 > len(found)/q.PageSize {
+		return page, len(found)
+	}
+	for i := (q.Page - 1) * q.PageSize; i < len(found) && len(page) < q.PageSize; i++ {
+		page = append(page, found[i].summary())
+	}
+	return page, len(found)
+}
+
+// parseArchiveQuery lit les critères de recherche d'une URL
+//
+// Les dates acceptent le format "2006-01-02" (la borne "to" couvre
+// alors toute la journée) ou RFC 3339.
+//
+// Paramètres:
+//   - values: paramètres player, difficulty, result, from, to, page et pageSize
+//
+// Retourne:
+//   - ArchiveQuery: recherche à effectuer
+//   - error: paramètre invalide
+func parseArchiveQuery(values url.Values) (ArchiveQuery, error) {
+	q := ArchiveQuery{
+		Player:     values.Get("player"),
+		Difficulty: values.Get("difficulty"),
+		Result:     values.Get("result"),
+		Page:       1,
+		PageSize:   defaultArchivePageSize,
+	}
+
+	switch q.Result {
+	case "", "player1", "player2", "draw":
+	case ResultWin, ResultLoss:
+		if q.Player == "" {
+			return q, fmt.Errorf("result=%s demande le paramètre player", q.Result)
+		}
+	default:
+		return q, fmt.Errorf("résultat inconnu: %q (attendu: player1, player2, draw, win ou loss)", q.Result)
+	}
+
+	var err error
+	if q.From, err = parseArchiveDate(values.Get("from"), false); err != nil {
+		return q, err
+	}
+	if q.To, err = parseArchiveDate(values.Get("to"), true); err != nil {
+		return q, err
+	}
+
+	for name, target := range map[string]*int{"page": &q.Page, "pageSize": &q.PageSize} {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || (name == "pageSize" && n > maxArchivePageSize) || (name == "page" && n > maxArchivePage) {
+			return q, fmt.Errorf("%s invalide: %q", name, raw)
+		}
+		*target = n
+	}
+	return q, nil
+}
+
+// parseArchiveDate lit une borne de date ("" = sans borne)
+//
+// Paramètres:
+//   - raw: date "2006-01-02" ou RFC 3339
+//   - endOfDay: true pour qu'une date sans heure couvre toute la journée
+func parseArchiveDate(raw string, endOfDay bool) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("date invalide: " + raw + " (attendu: AAAA-MM-JJ ou RFC 3339)")
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+//#endregion
+
+//#region HANDLERS HTTP - ARCHIVE
+
+// HandleArchive recherche dans les parties archivées
+//
+// Route: GET /api/games?player=alice&difficulty=easy&result=win&from=2024-01-01&to=2024-12-31&page=1&pageSize=20
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request contenant les critères (tous facultatifs)
+//
+// Réponse:
+//   - 200 OK: {"games": [...], "total": 42, "page": 1, "pageSize": 20}
+//   - 400 Bad Request: Critère invalide
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleArchive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	q, err := parseArchiveQuery(r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	games, total := gm.archive.Search(q)
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"games":    games,
+		"total":    total,
+		"page":     q.Page,
+		"pageSize": q.PageSize,
+	})
+}
+
+// HandleArchivedGame retourne une partie archivée complète, prête à être relue
+//
+// Route: GET /api/games/{id}
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request dont le chemin contient l'identifiant de la partie
+//
+// Réponse:
+//   - 200 OK: Partie archivée (champs de /api/game/replay, plus difficulty, reason, dates, etc.)
+//   - 404 Not Found: Partie absente de l'archive
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleArchivedGame(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	game, ok := gm.archive.Get(strings.TrimPrefix(r.URL.Path, "/api/games/"))
+	if !ok {
+		respondError(w, http.StatusNotFound, "Partie introuvable dans l'archive")
+		return
+	}
+	respondJSON(w, http.StatusOK, game)
+}
+
+//#endregion
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+//#region TESTS DE L'ARCHIVE
+
+// TestArchiveFinishedGames vérifie qu'une partie terminée est archivée,
+// qu'elle survit à sa suppression et au redémarrage du serveur
+func TestArchiveFinishedGames(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewGameStore("file", dir)
+	gm := NewGameManager(store, testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 7)
+	gm.getGame(id).DropPiece(2)
+	gm.commitGame(gm.getGame(id))
+	if _, ok := gm.archive.Get(id); ok {
+		t.Fatal("partie en cours archivée")
+	}
+
+	gm.HandleResign(httptest.NewRecorder(), seatRequest(gm, "POST", id, "player1", "/api/game/resign", ""))
+	gm.HandleReset(httptest.NewRecorder(), seatRequest(gm, "POST", id, "player1", "/api/game/reset", ""))
+
+	store, _ = NewGameStore("file", dir)
+	restarted := NewGameManager(store, testDifficulties(t), newAccounts())
+	if n, err := restarted.LoadGames(); err != nil || n != 0 {
+		t.Fatalf("LoadGames() = %d, %v", n, err)
+	}
+
+	rec := httptest.NewRecorder()
+	restarted.HandleArchivedGame(rec, httptest.NewRequest("GET", "/api/games/"+id, nil))
+	var archived ArchivedGame
+	if err := json.NewDecoder(rec.Body).Decode(&archived); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || archived.Winner != "player2" || archived.Reason != ReasonResignation ||
+		len(archived.Moves) != 1 || archived.Moves[0].Col != 2 || archived.Player1 != "Alice" ||
+		archived.StartedAt.IsZero() || archived.EndedAt.Before(archived.StartedAt) {
+		t.Fatalf("partie archivée: %d %+v", rec.Code, archived)
+	}
+
+	rec = httptest.NewRecorder()
+	restarted.HandleArchivedGame(rec, httptest.NewRequest("GET", "/api/games/inconnue", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("partie inconnue: %d", rec.Code)
+	}
+}
+
+// TestArchiveUndoneWin vérifie qu'une victoire annulée quitte l'archive et
+// les statistiques, et que la date de fin d'une partie archivée ne change plus
+func TestArchiveUndoneWin(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewGameStore("file", dir)
+	gm := NewGameManager(store, testDifficulties(t), newAccounts())
+	alice, _ := gm.accounts.Register("alice", "motdepasse")
+	rec := httptest.NewRecorder()
+	gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new",
+		strings.NewReader(`{"rows":6,"cols":7,"player1":"Alice","player2":"Bob","gravity":{"preset":"off"}}`)))
+	id, _ := decodeState(t, rec)["id"].(string)
+	game := gm.getGame(id)
+	game.Users = map[string]string{"player1": alice.ID}
+	for _, col := range []int{0, 1, 0, 1, 0, 1, 0} {
+		game.DropPiece(col)
+	}
+	gm.commitGame(game)
+	archived, ok := gm.archive.Get(id)
+	if !ok || archived.Winner != "player1" || archived.EndedAt.IsZero() {
+		t.Fatalf("victoire archivée: %+v", archived)
+	}
+
+	// Un nouvel enregistrement de la partie terminée garde la première date de fin
+	time.Sleep(10 * time.Millisecond)
+	gm.commitGame(game)
+	if again, _ := gm.archive.Get(id); !again.EndedAt.Equal(archived.EndedAt) {
+		t.Fatalf("date de fin modifiée: %v, puis %v", archived.EndedAt, again.EndedAt)
+	}
+
+	// L'annulation du coup gagnant rouvre la partie et la retire de l'archive
+	rec = httptest.NewRecorder()
+	gm.HandleUndo(rec, seatRequest(gm, "POST", id, "player1", "/api/game/undo", ""))
+	if rec.Code != http.StatusOK || game.GameOver {
+		t.Fatalf("annulation: %d %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := gm.archive.Get(id); ok {
+		t.Fatal("victoire annulée encore archivée")
+	}
+
+	// Supprimée ensuite, la partie ne laisse aucune victoire derrière elle
+	gm.HandleReset(httptest.NewRecorder(), seatRequest(gm, "POST", id, "player1", "/api/game/reset", ""))
+	if stats, _ := gm.Stats(alice.ID); stats.Games != 0 || stats.Wins != 0 {
+		t.Fatalf("statistiques après suppression: %+v", stats)
+	}
+	store, _ = NewGameStore("file", dir)
+	if games, err := store.LoadArchive(); err != nil || len(games) != 0 {
+		t.Fatalf("archive enregistrée: %v, %v", games, err)
+	}
+}
+
+// TestArchiveSearch vérifie les filtres, le tri et la pagination de /api/games
+func TestArchiveSearch(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.Local) }
+	for _, g := range []ArchivedGame{
+		{Replay: Replay{ID: "a", Player1: "Alice", Player2: "Bob", Winner: "player1"}, Difficulty: "easy", EndedAt: day(1)},
+		{Replay: Replay{ID: "b", Player1: "Bob", Player2: "Alice", Winner: "player1"}, Difficulty: "hard", EndedAt: day(2)},
+		{Replay: Replay{ID: "c", Player1: "Carol", Player2: "Bob", Winner: "draw"}, EndedAt: day(3),
+			Users: map[string]string{"player1": "compte-carol"}},
+		{Replay: Replay{ID: "d", Player1: "Alice", Player2: "Carol", Winner: "player2"}, Difficulty: "easy", EndedAt: day(4)},
+	} {
+		gm.archive.put(g)
+	}
+
+	search := func(query string) (int, []string, int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		gm.HandleArchive(rec, httptest.NewRequest("GET", "/api/games?"+query, nil))
+		var page struct {
+			Games []ArchiveSummary `json:"games"`
+			Total int              `json:"total"`
+		}
+		json.NewDecoder(rec.Body).Decode(&page)
+		ids := []string{}
+		for _, g := range page.Games {
+			ids = append(ids, g.ID)
+		}
+		return rec.Code, ids, page.Total
+	}
+
+	for query, want := range map[string]string{
+		"":                                       "dcba", // Plus récente en premier
+		"player=alice":                           "dba",
+		"player=compte-carol":                    "c",
+		"difficulty=easy":                        "da",
+		"difficulty=custom":                      "c",
+		"result=draw":                            "c",
+		"result=player1":                         "ba",
+		"player=alice&result=win":                "a",
+		"player=alice&result=loss":               "db",
+		"player=bob&result=loss&difficulty=easy": "a",
+		"from=2024-03-02&to=2024-03-03":          "cb",
+		"to=2024-03-01":                          "a",
+		"from=" + url.QueryEscape(day(3).Add(-time.Hour).Format(time.RFC3339)): "dc",
+	} {
+		code, ids, _ := search(query)
+		got := ""
+		for _, id := range ids {
+			got += id
+		}
+		if code != http.StatusOK || got != want {
+			t.Errorf("%q: %d %q, attendu %q", query, code, got, want)
+		}
+	}
+
+	if _, ids, total := search("page=2&pageSize=3"); total != 4 || len(ids) != 1 || ids[0] != "a" {
+		t.Errorf("page 2: %v sur %d", ids, total)
+	}
+	if _, ids, total := search("page=5"); total != 4 || len(ids) != 0 {
+		t.Errorf("page vide: %v sur %d", ids, total)
+	}
+	for _, query := range []string{"result=win", "result=abandon", "from=mardi", "pageSize=0", "pageSize=101", "page=x",
+		"page=4611686018427387904", fmt.Sprintf("page=%d", maxArchivePage+1)} {
+		if code, _, _ := search(query); code != http.StatusBadRequest {
+			t.Errorf("%q accepté: %d", query, code)
+		}
+	}
+	if code, ids, total := search(fmt.Sprintf("page=%d&pageSize=%d", maxArchivePage, maxArchivePageSize)); code != http.StatusOK || total != 4 || len(ids) != 0 {
+		t.Errorf("dernière page possible: %d %v sur %d", code, ids, total)
+	}
+
+	// Appelée directement, la recherche ne déborde pas non plus
+	if page, total := gm.archive.Search(ArchiveQuery{Page: math.MaxInt, PageSize: maxArchivePageSize}); len(page) != 0 || total != 4 {
+		t.Errorf("page démesurée: %v sur %d", page, total)
+	}
+}
+
+//#endregion
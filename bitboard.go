@@ -0,0 +1,428 @@
+package main
+
+import (
+	"math/bits"
+	"sync"
+)
+
+//#region ENTIER 128 BITS
+
+// bits128 est un masque de 128 bits (deux mots de 64 bits)
+//
+// Un plateau 10x10 avec une ligne sentinelle par colonne occupe
+// 10 x 11 = 110 bits : un uint64 ne suffit pas.
+type bits128 struct {
+	lo uint64 // Bits 0 à 63
+	hi uint64 // Bits 64 à 127
+}
+
+// bit retourne un masque ne contenant que le bit i
+func bit(i int) bits128 {
+	if i < 64 {
+		return bits128{lo: 1 << uint(i)}
+	}
+	return bits128{hi: 1 << uint(i-64)}
+}
+
+// and retourne l'intersection de deux masques
+func (b bits128) and(o bits128) bits128 { return bits128{b.lo & o.lo, b.hi & o.hi} }
+
+// or retourne l'union de deux masques
+func (b bits128) or(o bits128) bits128 { return bits128{b.lo | o.lo, b.hi | o.hi} }
+
+// andNot retourne les bits de b absents de o
+func (b bits128) andNot(o bits128) bits128 { return bits128{b.lo &^ o.lo, b.hi &^ o.hi} }
+
+// isZero indique si aucun bit n'est à 1
+func (b bits128) isZero() bool { return b.lo == 0 && b.hi == 0 }
+
+// count retourne le nombre de bits à 1
+func (b bits128) count() int { return bits.OnesCount64(b.lo) + bits.OnesCount64(b.hi) }
+
+// shr décale le masque de n bits vers les poids faibles
+func (b bits128) shr(n int) bits128 {
+	switch {
+	case n == 0:
+		return b
+	case n >= 64:
+		return bits128{lo: b.hi >> uint(n-64)}
+	default:
+		return bits128{lo: b.lo>>uint(n) | b.hi<<uint(64-n), hi: b.hi >> uint(n)}
+	}
+}
+
+// shl décale le masque de n bits vers les poids forts
+func (b bits128) shl(n int) bits128 {
+	switch {
+	case n == 0:
+		return b
+	case n >= 64:
+		return bits128{hi: b.lo << uint(n-64)}
+	default:
+		return bits128{lo: b.lo << uint(n), hi: b.hi<<uint(n) | b.lo>>uint(64-n)}
+	}
+}
+
+// lowest retourne l'indice du bit à 1 de plus faible poids (-1 si vide)
+func (b bits128) lowest() int {
+	switch {
+	case b.lo != 0:
+		return bits.TrailingZeros64(b.lo)
+	case b.hi != 0:
+		return 64 + bits.TrailingZeros64(b.hi)
+	}
+	return -1
+}
+
+// highest retourne l'indice du bit à 1 de plus fort poids (-1 si vide)
+func (b bits128) highest() int {
+	switch {
+	case b.hi != 0:
+		return 127 - bits.LeadingZeros64(b.hi)
+	case b.lo != 0:
+		return 63 - bits.LeadingZeros64(b.lo)
+	}
+	return -1
+}
+
+//#endregion
+
+//#region PLATEAU EN BITBOARD
+
+// BlockedCell est le contenu d'une case occupée par un obstacle neutre
+//
+// Un obstacle n'appartient à aucun joueur : il occupe la case (les jetons
+// s'arrêtent dessus) mais ne compte jamais dans un alignement.
+const BlockedCell = "blocked"
+
+// Bitboard représente le plateau de jeu sous forme de masques de bits
+//
+// Chaque colonne occupe Rows+1 bits consécutifs : la case du bas a le bit
+// de poids le plus faible, et un bit sentinelle (toujours à 0) coiffe la
+// colonne pour que les décalages ne débordent pas d'une colonne à l'autre.
+//
+//	bit = col*(Rows+1) + (Rows-1-row)
+//
+// Avec cette disposition, un alignement se détecte en quelques décalages
+// (1 = vertical, Rows+1 = horizontal, Rows+2 et Rows = diagonales).
+type Bitboard struct {
+	Rows    int     // Nombre de lignes
+	Cols    int     // Nombre de colonnes
+	player1 bits128 // Cases occupées par le joueur 1
+	player2 bits128 // Cases occupées par le joueur 2
+	blocked bits128 // Cases occupées par un obstacle neutre
+	full    bits128 // Toutes les cases jouables du plateau
+}
+
+// NewBitboard crée un plateau vide
+//
+// Paramètres:
+//   - rows: nombre de lignes (10 maximum)
+//   - cols: nombre de colonnes (10 maximum)
+//
+// Retourne:
+//   - Bitboard: plateau vide
+func NewBitboard(rows, cols int) Bitboard {
+	b := Bitboard{Rows: rows, Cols: cols}
+	for col := 0; col < cols; col++ {
+		b.full = b.full.or(b.columnMask(col))
+	}
+	return b
+}
+
+// height retourne le nombre de bits occupés par une colonne (sentinelle comprise)
+func (b *Bitboard) height() int {
+	return b.Rows + 1
+}
+
+// index retourne la position du bit correspondant à une case
+func (b *Bitboard) index(row, col int) int {
+	return col*b.height() + (b.Rows - 1 - row)
+}
+
+// columnMask retourne le masque des cases d'une colonne (sans la sentinelle)
+func (b *Bitboard) columnMask(col int) bits128 {
+	return bits128{lo: 1<<uint(b.Rows) - 1}.shl(col * b.height())
+}
+
+// occupied retourne le masque de toutes les cases occupées
+func (b *Bitboard) occupied() bits128 {
+	return b.player1.or(b.player2).or(b.blocked)
+}
+
+// mask retourne le masque des jetons d'un joueur (ou des obstacles pour BlockedCell)
+func (b *Bitboard) mask(player string) *bits128 {
+	switch player {
+	case "player1":
+		return &b.player1
+	case BlockedCell:
+		return &b.blocked
+	}
+	return &b.player2
+}
+
+//#endregion
+
+//#region LECTURE ET ÉCRITURE DES CASES
+
+// Cell retourne le contenu d'une case
+//
+// Retourne:
+//   - string: "" (vide), "player1", "player2" ou BlockedCell
+func (b *Bitboard) Cell(row, col int) string {
+	i := bit(b.index(row, col))
+	switch {
+	case !b.player1.and(i).isZero():
+		return "player1"
+	case !b.player2.and(i).isZero():
+		return "player2"
+	case !b.blocked.and(i).isZero():
+		return BlockedCell
+	}
+	return ""
+}
+
+// Set place un jeton d'un joueur, ou un obstacle (BlockedCell), sur une case (sans gravité)
+func (b *Bitboard) Set(row, col int, player string) {
+	b.Clear(row, col)
+	m := b.mask(player)
+	*m = m.or(bit(b.index(row, col)))
+}
+
+// Clear vide une case
+func (b *Bitboard) Clear(row, col int) {
+	i := bit(b.index(row, col))
+	b.player1 = b.player1.andNot(i)
+	b.player2 = b.player2.andNot(i)
+	b.blocked = b.blocked.andNot(i)
+}
+
+// Count retourne le nombre de jetons d'un joueur (ou d'obstacles pour BlockedCell)
+func (b *Bitboard) Count(player string) int {
+	return b.mask(player).count()
+}
+
+// Grid convertit le plateau en tableau 2D de chaînes
+//
+// C'est le format attendu par le client : "" = vide, "player1", "player2"
+// ou "blocked" (obstacle neutre).
+//
+// Retourne:
+//   - [][]string: plateau indexé par [ligne][colonne], ligne 0 en haut
+func (b *Bitboard) Grid() [][]string {
+	grid := make([][]string, b.Rows)
+	for row := range grid {
+		grid[row] = make([]string, b.Cols)
+		for col := range grid[row] {
+			grid[row][col] = b.Cell(row, col)
+		}
+	}
+	return grid
+}
+
+//#endregion
+
+//#region GÉNÉRATION DE COUPS
+
+// LandingRow retourne la ligne où tomberait un jeton joué dans une colonne
+//
+// En gravité normale le jeton occupe la case vide la plus basse,
+// en gravité inversée la case vide la plus haute.
+//
+// Paramètres:
+//   - col: colonne jouée
+//   - inverse: true si la gravité est inversée
+//
+// Retourne:
+//   - int: ligne d'arrivée (-1 si la colonne est pleine)
+func (b *Bitboard) LandingRow(col int, inverse bool) int {
+	empty := b.columnMask(col).andNot(b.occupied())
+	i := empty.lowest()
+	if inverse {
+		i = empty.highest()
+	}
+	if i < 0 {
+		return -1
+	}
+	return b.Rows - 1 - (i - col*b.height())
+}
+
+// ColumnFull indique si une colonne ne contient plus de case vide
+func (b *Bitboard) ColumnFull(col int) bool {
+	return b.columnMask(col).andNot(b.occupied()).isZero()
+}
+
+// IsFull indique si toutes les cases du plateau sont occupées
+func (b *Bitboard) IsFull() bool {
+	return b.full.andNot(b.occupied()).isZero()
+}
+
+//#endregion
+
+//#region DÉTECTION DE VICTOIRE
+
+// directions retourne les décalages des 4 directions d'alignement
+func (b *Bitboard) directions() [4]int {
+	h := b.height()
+	return [4]int{
+		1,     // Vertical
+		h,     // Horizontal
+		h + 1, // Diagonale montante vers la droite
+		h - 1, // Diagonale descendante vers la droite
+	}
+}
+
+// runs marque le premier bit de chaque suite de n bits consécutifs dans la direction d
+//
+// b & (b >> d) & (b >> 2d) & ... & (b >> (n-1)d) : un bit reste à 1 si les
+// n cases qui partent de lui dans la direction d sont toutes dans b.
+func (b bits128) runs(d, n int) bits128 {
+	r := b
+	for k := 1; k < n && !r.isZero(); k++ {
+		r = r.and(b.shr(k * d))
+	}
+	return r
+}
+
+// WinsThrough indique si le jeton en (row, col) fait partie d'un alignement de n
+//
+// Pour chaque direction, runs marque le premier bit de chaque alignement de n ;
+// il suffit ensuite de vérifier qu'un de ces alignements commence à l'une des
+// n positions qui couvrent la case jouée. Le calcul ne dépend que de n,
+// quelle que soit la taille du plateau.
+//
+// Paramètres:
+//   - row, col: case du dernier jeton posé
+//   - n: nombre de jetons à aligner pour gagner
+//
+// Retourne:
+//   - bool: true si le propriétaire de la case a aligné n jetons à travers elle
+func (b *Bitboard) WinsThrough(row, col, n int) bool {
+	p := bit(b.index(row, col))
+	m := b.player1
+	if m.and(p).isZero() {
+		m = b.player2
+	}
+	if m.and(p).isZero() {
+		return false // Case vide
+	}
+
+	for _, d := range b.directions() {
+		starts := m.runs(d, n)
+		if starts.isZero() {
+			continue
+		}
+		// Positions de départ possibles d'un alignement couvrant la case jouée
+		covering := p
+		for k := 1; k < n; k++ {
+			covering = covering.or(p.shr(k * d))
+		}
+		if !starts.and(covering).isZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// WinningCells retourne les cases des alignements gagnants passant par (row, col)
+//
+// Chaque direction où le propriétaire de la case aligne au moins n jetons
+// contribue toute sa suite de jetons consécutifs : un coup qui complète
+// plusieurs lignes (ou une ligne de plus de n jetons) les renvoie toutes.
+//
+// Paramètres:
+//   - row, col: case du coup gagnant
+//   - n: nombre de jetons à aligner pour gagner
+//
+// Retourne:
+//   - []Move: cases gagnantes sans doublon, la case jouée en premier (vide si aucun alignement)
+func (b *Bitboard) WinningCells(row, col, n int) []Move {
+	cells := []Move{}
+	owner := b.Cell(row, col)
+	if owner != "player1" && owner != "player2" {
+		return cells
+	}
+
+	inside := func(r, c int) bool { return r >= 0 && r < b.Rows && c >= 0 && c < b.Cols }
+	for _, dir := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+		// Suite de jetons du propriétaire de part et d'autre de la case
+		line := []Move{}
+		for _, sign := range []int{-1, 1} {
+			r, c := row+sign*dir[0], col+sign*dir[1]
+			for inside(r, c) && b.Cell(r, c) == owner {
+				line = append(line, Move{Row: r, Col: c})
+				r, c = r+sign*dir[0], c+sign*dir[1]
+			}
+		}
+		if len(line)+1 >= n {
+			cells = append(cells, line...)
+		}
+	}
+	if len(cells) == 0 {
+		return cells
+	}
+	return append([]Move{{Row: row, Col: col}}, cells...)
+}
+
+// windowCache garde les fenêtres déjà calculées, par dimensions et longueur
+var windowCache sync.Map // map[[3]int][]bits128
+
+// windows retourne les masques de toutes les fenêtres de n cases alignées
+//
+// Une fenêtre est un segment de n cases dans l'une des 4 directions,
+// entièrement contenu dans le plateau. La liste ne dépend que des
+// dimensions : elle est calculée une fois puis partagée (lecture seule).
+func (b *Bitboard) windows(n int) []bits128 {
+	key := [3]int{b.Rows, b.Cols, n}
+	if cached, ok := windowCache.Load(key); ok {
+		return cached.([]bits128)
+	}
+
+	list := []bits128{}
+	for row := 0; row < b.Rows; row++ {
+		for col := 0; col < b.Cols; col++ {
+			for _, dir := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+				endRow, endCol := row+(n-1)*dir[0], col+(n-1)*dir[1]
+				if endRow < 0 || endRow >= b.Rows || endCol < 0 || endCol >= b.Cols {
+					continue
+				}
+				var window bits128
+				for i := 0; i < n; i++ {
+					window = window.or(bit(b.index(row+i*dir[0], col+i*dir[1])))
+				}
+				list = append(list, window)
+			}
+		}
+	}
+	windowCache.Store(key, list)
+	return list
+}
+
+// CanAlign indique si un joueur peut encore aligner n jetons
+//
+// Un alignement reste possible s'il existe n cases consécutives ne contenant
+// ni jeton adverse ni obstacle. Toute case vide finit par être atteignable (les
+// colonnes se remplissent d'un côté ou de l'autre), l'ordre des coups n'est
+// donc pas pris en compte.
+//
+// Paramètres:
+//   - player: "player1" ou "player2"
+//   - n: nombre de jetons à aligner pour gagner
+//
+// Retourne:
+//   - bool: true s'il reste au moins une fenêtre de n cases ouverte au joueur
+func (b *Bitboard) CanAlign(player string, n int) bool {
+	opponent := b.player2
+	if player == "player2" {
+		opponent = b.player1
+	}
+	free := b.full.andNot(opponent).andNot(b.blocked)
+	for _, d := range b.directions() {
+		if !free.runs(d, n).isZero() {
+			return true
+		}
+	}
+	return false
+}
+
+//#endregion
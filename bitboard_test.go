@@ -0,0 +1,242 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+//#region IMPLÉMENTATION DE RÉFÉRENCE (TABLEAU 2D)
+
+// gridGame reproduit l'ancienne représentation du plateau ([][]string parcouru
+// case par case) : elle sert de référence pour les tests et les benchmarks.
+type gridGame struct {
+	rows, cols int
+	board      [][]string
+}
+
+func newGridGame(rows, cols int) *gridGame {
+	board := make([][]string, rows)
+	for i := range board {
+		board[i] = make([]string, cols)
+	}
+	return &gridGame{rows: rows, cols: cols, board: board}
+}
+
+// drop place un jeton et retourne sa ligne (-1 si la colonne est pleine)
+func (g *gridGame) drop(col int, player string, inverse bool) int {
+	for i := 0; i < g.rows; i++ {
+		r := g.rows - 1 - i
+		if inverse {
+			r = i
+		}
+		if g.board[r][col] == "" {
+			g.board[r][col] = player
+			return r
+		}
+	}
+	return -1
+}
+
+// checkWin compte les jetons alignés autour de (row, col) dans les 4 directions
+func (g *gridGame) checkWin(row, col, n int) bool {
+	player := g.board[row][col]
+	for _, dir := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+		count := 1 + g.countDirection(row, col, dir[0], dir[1], player) +
+			g.countDirection(row, col, -dir[0], -dir[1], player)
+		if count >= n {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *gridGame) countDirection(row, col, dRow, dCol int, player string) int {
+	count := 0
+	r, c := row+dRow, col+dCol
+	for r >= 0 && r < g.rows && c >= 0 && c < g.cols && g.board[r][c] == player {
+		count++
+		r += dRow
+		c += dCol
+	}
+	return count
+}
+
+//#endregion
+
+//#region TESTS D'ÉQUIVALENCE
+
+// TestBitboardMatchesGrid joue des parties aléatoires sur les deux
+// représentations et vérifie qu'elles restent identiques coup après coup.
+func TestBitboardMatchesGrid(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for game := 0; game < 500; game++ {
+		rows, cols := 4+rng.Intn(7), 4+rng.Intn(7)
+		n := MinWinLength + rng.Intn(4) // Alignements de 3 à 6
+		grid := newGridGame(rows, cols)
+		bb := NewBitboard(rows, cols)
+
+		for turn := 0; turn < rows*cols; turn++ {
+			player := []string{"player1", "player2"}[turn%2]
+			inverse := rng.Intn(3) == 0
+			col := rng.Intn(cols)
+
+			want := grid.drop(col, player, inverse)
+			got := bb.LandingRow(col, inverse)
+			if got != want {
+				t.Fatalf("%dx%d: ligne d'arrivée %d, attendu %d", rows, cols, got, want)
+			}
+			if want < 0 {
+				continue
+			}
+			bb.Set(got, col, player)
+
+			if bb.WinsThrough(got, col, n) != grid.checkWin(got, col, n) {
+				t.Fatalf("%dx%d: détection de victoire à %d divergente en (%d, %d)\n%v", rows, cols, n, got, col, grid.board)
+			}
+		}
+
+		if !bb.IsFull() {
+			continue
+		}
+		for r := 0; r < rows; r++ {
+			for c := 0; c < cols; c++ {
+				if bb.Cell(r, c) != grid.board[r][c] {
+					t.Fatalf("%dx%d: case (%d, %d) divergente", rows, cols, r, c)
+				}
+			}
+		}
+	}
+}
+
+// TestBlockedCellNeverAligns vérifie qu'un obstacle occupe sa case sans
+// jamais compter dans un alignement
+func TestBlockedCellNeverAligns(t *testing.T) {
+	bb := NewBitboard(6, 7)
+	bb.Set(5, 3, BlockedCell)
+	for _, col := range []int{0, 1, 2, 4, 5, 6} {
+		bb.Set(5, col, "player1")
+	}
+
+	if got := bb.Grid()[5][3]; got != BlockedCell {
+		t.Fatalf("case murée: %q, attendu %q", got, BlockedCell)
+	}
+	if row := bb.LandingRow(3, false); row != 4 {
+		t.Fatalf("jeton posé en ligne %d, attendu 4 (sur l'obstacle)", row)
+	}
+	for _, col := range []int{0, 2, 3, 4, 6} {
+		if bb.WinsThrough(5, col, 4) {
+			t.Fatalf("alignement compté à travers l'obstacle (colonne %d)", col)
+		}
+	}
+	if !bb.WinsThrough(5, 0, 3) {
+		t.Fatal("alignement de 3 à gauche de l'obstacle non détecté")
+	}
+
+	// Un mur complet au milieu d'un plateau 4x4 interdit tout alignement de 4
+	small := NewBitboard(4, 4)
+	for row := 0; row < 4; row++ {
+		small.Set(row, 1, BlockedCell)
+	}
+	small.Set(0, 0, BlockedCell)
+	small.Set(3, 2, BlockedCell)
+	small.Set(1, 3, BlockedCell)
+	if small.CanAlign("player1", 4) || small.CanAlign("player2", 4) {
+		t.Fatal("alignement de 4 jugé possible malgré les obstacles")
+	}
+	if small.Count(BlockedCell) != 7 || small.Count("player1") != 0 {
+		t.Fatalf("%d obstacles comptés, attendu 7", small.Count(BlockedCell))
+	}
+}
+
+// TestWinningCells vérifie les cases renvoyées pour une ligne simple, une
+// ligne de plus de n jetons et un coup qui complète deux lignes
+func TestWinningCells(t *testing.T) {
+	bb := NewBitboard(6, 7)
+	for _, col := range []int{0, 1, 2, 4, 5} {
+		bb.Set(5, col, "player1")
+	}
+	for _, row := range []int{2, 3, 4} {
+		bb.Set(row, 3, "player1")
+	}
+	bb.Set(5, 6, "player2")
+
+	if cells := bb.WinningCells(5, 0, 4); len(cells) != 0 {
+		t.Fatalf("cases gagnantes sans alignement: %v", cells)
+	}
+
+	// Le jeton en (5, 3) complète la ligne du bas (6 jetons) et la colonne 3 (4 jetons)
+	bb.Set(5, 3, "player1")
+	cells := bb.WinningCells(5, 3, 4)
+	if len(cells) != 9 || cells[0] != (Move{Row: 5, Col: 3}) {
+		t.Fatalf("cases gagnantes %v, attendu 9 cases en commençant par le coup joué", cells)
+	}
+	seen := map[Move]bool{}
+	for _, c := range cells {
+		if seen[c] || bb.Cell(c.Row, c.Col) != "player1" {
+			t.Fatalf("case %v en double ou n'appartenant pas au gagnant", c)
+		}
+		seen[c] = true
+	}
+}
+
+//#endregion
+
+//#region BENCHMARKS
+
+// benchmarkMoves génère une séquence de colonnes jouées sur un plateau 10x10
+func benchmarkMoves() []int {
+	rng := rand.New(rand.NewSource(1))
+	moves := make([]int, 100)
+	for i := range moves {
+		moves[i] = rng.Intn(10)
+	}
+	return moves
+}
+
+// BenchmarkDropAndCheckWinGrid mesure l'ancienne représentation [][]string
+func BenchmarkDropAndCheckWinGrid(b *testing.B) {
+	moves := benchmarkMoves()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g := newGridGame(10, 10)
+		for turn, col := range moves {
+			player := []string{"player1", "player2"}[turn%2]
+			if row := g.drop(col, player, turn/5%2 == 1); row >= 0 {
+				g.checkWin(row, col, 4)
+			}
+		}
+	}
+}
+
+// BenchmarkDropAndCheckWinBitboard mesure la représentation en bitboard
+func BenchmarkDropAndCheckWinBitboard(b *testing.B) {
+	moves := benchmarkMoves()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bb := NewBitboard(10, 10)
+		for turn, col := range moves {
+			player := []string{"player1", "player2"}[turn%2]
+			if row := bb.LandingRow(col, turn/5%2 == 1); row >= 0 {
+				bb.Set(row, col, player)
+				bb.WinsThrough(row, col, 4)
+			}
+		}
+	}
+}
+
+// BenchmarkGameDropPiece mesure un coup complet via Game.DropPiece
+// (verrou, gravité, victoire et égalité compris)
+func BenchmarkGameDropPiece(b *testing.B) {
+	moves := benchmarkMoves()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g := NewGame(10, 10, "Alice", "Bob")
+		for _, col := range moves {
+			if g.DropPiece(col) != nil && g.GameOver {
+				break
+			}
+		}
+	}
+}
+
+//#endregion
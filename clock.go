@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+//#region CADENCE DE JEU
+
+// maxClockSeconds borne chaque valeur d'une cadence (24 heures)
+const maxClockSeconds = 24 * 60 * 60
+
+// errTimeUp est renvoyée quand le joueur dont c'est le tour n'a plus de temps
+var errTimeUp = errors.New("temps écoulé")
+
+// TimeControl est la cadence d'une partie chronométrée, en secondes
+//
+// Deux formes sont possibles :
+//   - Base (+ Increment) : réserve de temps par joueur, augmentée de
+//     Increment après chacun de ses coups (cadence "Fischer")
+//   - PerMove : temps fixe accordé pour chaque coup, non cumulable
+//
+// La cadence vide désactive les pendules.
+type TimeControl struct {
+	Base      int `json:"base,omitempty"`      // Réserve initiale de chaque joueur
+	Increment int `json:"increment,omitempty"` // Temps ajouté après chaque coup joué
+	PerMove   int `json:"perMove,omitempty"`   // Temps fixe par coup
+}
+
+// enabled indique si la cadence active les pendules
+func (tc TimeControl) enabled() bool {
+	return tc.Base > 0 || tc.PerMove > 0
+}
+
+// initial retourne le temps dont dispose chaque joueur au début de la partie
+func (tc TimeControl) initial() time.Duration {
+	if tc.PerMove > 0 {
+		return time.Duration(tc.PerMove) * time.Second
+	}
+	return time.Duration(tc.Base) * time.Second
+}
+
+// validateTimeControl vérifie qu'une cadence demandée est cohérente
+//
+// Paramètres:
+//   - tc: cadence demandée
+//
+// Retourne:
+//   - error: nil si la cadence est vide ou valide, une erreur explicite sinon
+func validateTimeControl(tc TimeControl) error {
+	for _, v := range []int{tc.Base, tc.Increment, tc.PerMove} {
+		if v < 0 || v > maxClockSeconds {
+			return fmt.Errorf("les temps de la cadence doivent être entre 0 et %d secondes", maxClockSeconds)
+		}
+	}
+	if tc.PerMove > 0 && (tc.Base > 0 || tc.Increment > 0) {
+		return errors.New("un temps par coup ne se combine pas avec une réserve ou un incrément")
+	}
+	if tc.Increment > 0 && tc.Base == 0 {
+		return errors.New("un incrément demande une réserve de temps (base)")
+	}
+	return nil
+}
+
+//#endregion
+
+//#region PENDULES
+
+// Les pendules démarrent au premier coup : tant que personne n'a joué
+// (TurnStarted nul), aucun temps n'est décompté. Clocks contient le
+// temps restant de chaque joueur au début du tour en cours ; le temps
+// écoulé depuis TurnStarted est retranché de celui du joueur actuel.
+
+// clockRunning indique si la pendule du joueur actuel tourne
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) clockRunning() bool {
+	return g.TimeControl.enabled() && !g.GameOver && !g.TurnStarted.IsZero()
+}
+
+// remaining retourne le temps restant d'un joueur à un instant donné
+//
+// Paramètres:
+//   - player: "player1" ou "player2"
+//   - now: instant de la mesure
+//
+// Retourne:
+//   - time.Duration: temps restant (jamais négatif)
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) remaining(player string, now time.Time) time.Duration {
+	left := g.Clocks[player]
+	if player == g.CurrentPlayer && g.clockRunning() {
+		left -= now.Sub(g.TurnStarted)
+	}
+	return max(left, 0)
+}
+
+// clockDeadline retourne l'instant où le joueur actuel tombera au temps
+//
+// Retourne:
+//   - time.Time: échéance de la pendule en cours
+//   - bool: false si aucune pendule ne tourne
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) clockDeadline() (time.Time, bool) {
+	if !g.clockRunning() {
+		return time.Time{}, false
+	}
+	return g.TurnStarted.Add(g.Clocks[g.CurrentPlayer]), true
+}
+
+// CheckTime termine la partie si le joueur actuel n'a plus de temps
+//
+// Retourne:
+//   - bool: true si la partie vient d'être perdue au temps
+func (g *Game) CheckTime() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.checkTime(time.Now())
+}
+
+// checkTime contient la logique de CheckTime, sans verrouillage
+//
+// Le joueur à court de temps perd : son adversaire devient le gagnant.
+//
+// Paramètres:
+//   - now: instant de la vérification
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) checkTime(now time.Time) bool {
+	if !g.clockRunning() || g.remaining(g.CurrentPlayer, now) > 0 {
+		return false
+	}
+	g.Clocks[g.CurrentPlayer] = 0
+	g.TurnStarted = time.Time{}
+	g.endGame(opponentOf(g.CurrentPlayer), ReasonTimeout)
+	return true
+}
+
+// tickClock arrête la pendule du joueur qui vient de jouer et lance celle de l'adversaire
+//
+// Le temps du tour est retranché de la réserve du joueur, puis l'incrément
+// lui est ajouté (ou, en temps par coup, sa pendule est remise à PerMove).
+//
+// Paramètres:
+//   - player: joueur qui vient de jouer
+//   - now: instant du coup
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) tickClock(player string, now time.Time) {
+	if !g.TimeControl.enabled() {
+		return
+	}
+	if !g.TurnStarted.IsZero() {
+		g.Clocks[player] -= now.Sub(g.TurnStarted)
+	}
+	if g.TimeControl.PerMove > 0 {
+		g.Clocks[player] = g.TimeControl.initial()
+	} else {
+		g.Clocks[player] += time.Duration(g.TimeControl.Increment) * time.Second
+	}
+
+	g.TurnStarted = now
+	if g.GameOver {
+		g.TurnStarted = time.Time{} // Partie terminée : les pendules s'arrêtent
+	}
+}
+
+// restartClock relance la pendule du joueur actuel après une annulation ou un rétablissement
+//
+// Le temps restant de chaque joueur est conservé : seul le décompte
+// du tour repart de maintenant.
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) restartClock(now time.Time) {
+	if !g.TimeControl.enabled() {
+		return
+	}
+	g.TurnStarted = now
+	if g.GameOver || len(g.history) == 0 {
+		g.TurnStarted = time.Time{} // Avant le premier coup, les pendules ne tournent pas
+	}
+}
+
+// clockState retourne le temps restant de chaque joueur, en millisecondes
+//
+// Retourne:
+//   - map[string]int64: temps restant par joueur (nil si la partie n'est pas chronométrée)
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) clockState() map[string]int64 {
+	if !g.TimeControl.enabled() {
+		return nil
+	}
+	now := time.Now()
+	return map[string]int64{
+		"player1": g.remaining("player1", now).Milliseconds(),
+		"player2": g.remaining("player2", now).Milliseconds(),
+	}
+}
+
+// opponentOf retourne l'adversaire d'un joueur
+func opponentOf(player string) string {
+	if player == "player1" {
+		return "player2"
+	}
+	return "player1"
+}
+
+//#endregion
+
+//#region SURVEILLANCE DES PENDULES
+
+// watchClock programme la défaite au temps du joueur actuel
+//
+// Un minuteur se déclenche à l'échéance de la pendule en cours : la
+// partie est alors terminée, enregistrée et diffusée même si aucun
+// client n'envoie de requête. Le minuteur précédent de la partie est
+// arrêté.
+//
+// Paramètres:
+//   - game: partie à surveiller
+//
+// L'appelant doit détenir game.mu.
+func (gm *GameManager) watchClock(game *Game) {
+	if game.clockTimer != nil {
+		game.clockTimer.Stop()
+		game.clockTimer = nil
+	}
+	deadline, ok := game.clockDeadline()
+	if !ok {
+		return
+	}
+	game.clockTimer = time.AfterFunc(time.Until(deadline), func() {
+		// La partie a pu être supprimée entre-temps
+		if gm.getGame(game.ID) != game {
+			return
+		}
+		if game.CheckTime() {
+			log.Printf("Partie %s: défaite au temps", game.ID)
+		}
+		gm.commitGame(game) // Enregistre la défaite, ou reprogramme le minuteur
+	})
+}
+
+//#endregion
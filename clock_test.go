@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//#region TESTS DES PENDULES
+
+// assertClock vérifie le temps restant d'un joueur, à une seconde près
+func assertClock(t *testing.T, g *Game, player string, want time.Duration) {
+	t.Helper()
+	got := g.remaining(player, time.Now())
+	if got < want-time.Second || got > want+time.Second {
+		t.Fatalf("%s: %v restantes, attendu %v", player, got, want)
+	}
+}
+
+// TestClockIncrement vérifie le décompte avec réserve et incrément
+func TestClockIncrement(t *testing.T) {
+	g := NewGameWithOptions(6, 7, "Alice", "Bob", GameOptions{TimeControl: TimeControl{Base: 60, Increment: 5}})
+
+	// Premier coup : les pendules ne tournaient pas encore
+	if err := g.DropPiece(0); err != nil {
+		t.Fatal(err)
+	}
+	assertClock(t, g, "player1", 65*time.Second)
+
+	// Le joueur 2 réfléchit 10 secondes
+	g.TurnStarted = g.TurnStarted.Add(-10 * time.Second)
+	assertClock(t, g, "player2", 50*time.Second)
+	if err := g.DropPiece(1); err != nil {
+		t.Fatal(err)
+	}
+	assertClock(t, g, "player2", 55*time.Second)
+
+	// Les pendules survivent à l'enregistrement
+	restored, err := GameFromRecord(g.Record())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertClock(t, restored, "player1", 65*time.Second)
+	assertClock(t, restored, "player2", 55*time.Second)
+}
+
+// TestClockPerMove vérifie que le temps par coup repart à chaque coup
+func TestClockPerMove(t *testing.T) {
+	g := NewGameWithOptions(6, 7, "Alice", "Bob", GameOptions{TimeControl: TimeControl{PerMove: 10}})
+	g.DropPiece(0)
+	g.TurnStarted = g.TurnStarted.Add(-8 * time.Second)
+	if err := g.DropPiece(1); err != nil {
+		t.Fatal(err)
+	}
+	assertClock(t, g, "player1", 10*time.Second)
+	assertClock(t, g, "player2", 10*time.Second)
+}
+
+// TestLossOnTime vérifie qu'un coup joué trop tard fait perdre la partie
+// et que cette défaite ne peut pas être annulée
+func TestLossOnTime(t *testing.T) {
+	g := NewGameWithOptions(6, 7, "Alice", "Bob", GameOptions{TimeControl: TimeControl{Base: 5}})
+	g.DropPiece(0)
+	g.TurnStarted = g.TurnStarted.Add(-6 * time.Second)
+
+	if err := g.DropPiece(1); !errors.Is(err, errTimeUp) {
+		t.Fatalf("coup hors délai: %v, attendu errTimeUp", err)
+	}
+	state := g.GetState()
+	if !g.GameOver || g.Winner != "player1" || g.Reason != ReasonTimeout {
+		t.Fatalf("défaite au temps non constatée: %v", state)
+	}
+	if state["canUndo"] != false || g.Undo() == nil {
+		t.Fatal("une défaite au temps ne doit pas pouvoir être annulée")
+	}
+	if clocks := state["clocks"].(map[string]int64); clocks["player2"] != 0 {
+		t.Fatalf("pendule du perdant: %d ms", clocks["player2"])
+	}
+}
+
+// TestClockTimerFlags vérifie que la défaite au temps est constatée sans
+// aucune requête d'un client
+func TestClockTimerFlags(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	rec := httptest.NewRecorder()
+	gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new", strings.NewReader(
+		`{"rows":6,"cols":7,"player1":"Alice","player2":"Bob","timeControl":{"perMove":30}}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("création: %d %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	id := created.ID
+
+	rec = httptest.NewRecorder()
+	gm.HandleDropPiece(rec, seatRequest(gm, "POST", id, "player1", "/api/game/drop", `{"col":3}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("coup: %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Le joueur 2 n'a plus que 50 millisecondes
+	game := gm.getGame(id)
+	game.mu.Lock()
+	game.TurnStarted = time.Now().Add(-30*time.Second + 50*time.Millisecond)
+	gm.watchClock(game)
+	game.mu.Unlock()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for game.GetState()["reason"] != ReasonTimeout {
+		if time.Now().After(deadline) {
+			t.Fatalf("partie non terminée au temps: %v", game.GetState())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if saved := game.Record(); saved.Winner != "player1" || saved.Reason != ReasonTimeout {
+		t.Fatalf("défaite au temps non enregistrée: %+v", saved)
+	}
+}
+
+// TestNewGameTimeControlValidation vérifie le refus des cadences incohérentes
+func TestNewGameTimeControlValidation(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	for _, tc := range []string{`{"base":-1}`, `{"increment":5}`, `{"base":60,"perMove":10}`, `{"perMove":100000}`} {
+		rec := httptest.NewRecorder()
+		body := `{"rows":6,"cols":7,"player1":"Alice","player2":"Bob","timeControl":` + tc + `}`
+		gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("cadence %s acceptée (%d)", tc, rec.Code)
+		}
+	}
+}
+
+//#endregion
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+//#region PRÉRÉGLAGES DE DIFFICULTÉ
+
+// Difficulty est un préréglage de partie proposé sur la page d'accueil
+//
+// Les préréglages sont décrits dans un fichier JSON (config/difficulties.json
+// par défaut) : ajouter une difficulté ne demande aucune modification du code.
+type Difficulty struct {
+	Name          string      `json:"name"`                    // Identifiant envoyé à /api/game/new ("easy", "normal"...)
+	Label         string      `json:"label"`                   // Nom affiché aux joueurs
+	Rows          int         `json:"rows"`                    // Nombre de lignes du plateau
+	Cols          int         `json:"cols"`                    // Nombre de colonnes du plateau
+	Prefilled     int         `json:"prefilled"`               // Nombre de jetons pré-remplis au démarrage
+	Blockers      int         `json:"blockers,omitempty"`      // Nombre d'obstacles neutres placés au hasard
+	BlockerLayout []Move      `json:"blockerLayout,omitempty"` // Cases murées par des obstacles neutres
+	WinLength     int         `json:"winLength,omitempty"`     // Jetons à aligner (0 = DefaultWinLength)
+	Gravity       GravityRule `json:"gravity"`                 // Règle d'inversion de la gravité
+	Theme         string      `json:"theme"`                   // Classe CSS du thème visuel
+	Background    string      `json:"background"`              // Image de fond de la partie
+}
+
+// DifficultyRegistry contient les préréglages disponibles, dans l'ordre du fichier
+type DifficultyRegistry struct {
+	list   []Difficulty          // Préréglages dans l'ordre d'affichage
+	byName map[string]Difficulty // Préréglages indexés par leur nom
+}
+
+// LoadDifficulties lit et valide le fichier des préréglages
+//
+// Paramètres:
+//   - path: chemin du fichier JSON (tableau de préréglages)
+//
+// Retourne:
+//   - *DifficultyRegistry: préréglages chargés
+//   - error: si le fichier est illisible ou un préréglage invalide
+func LoadDifficulties(path string) (*DifficultyRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []Difficulty
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewDifficultyRegistry(list)
+}
+
+// NewDifficultyRegistry valide une liste de préréglages
+//
+// La règle de gravité de chaque préréglage est résolue (préréglage de
+// gravité nommé ou mode détaillé).
+//
+// Paramètres:
+//   - list: préréglages dans l'ordre d'affichage
+//
+// Retourne:
+//   - *DifficultyRegistry: registre prêt à l'emploi
+//   - error: au premier préréglage invalide ou en double
+func NewDifficultyRegistry(list []Difficulty) (*DifficultyRegistry, error) {
+	reg := &DifficultyRegistry{byName: make(map[string]Difficulty)}
+	for _, d := range list {
+		if d.Name == "" {
+			return nil, errors.New("difficulté sans nom")
+		}
+		if _, ok := reg.byName[d.Name]; ok {
+			return nil, fmt.Errorf("difficulté %q définie deux fois", d.Name)
+		}
+		if d.WinLength == 0 {
+			d.WinLength = DefaultWinLength
+		}
+		if err := validateBoard(d.Rows, d.Cols, d.WinLength, d.Prefilled); err != nil {
+			return nil, fmt.Errorf("difficulté %q: %w", d.Name, err)
+		}
+		if err := validateBlockers(d.Rows, d.Cols, d.WinLength, d.Blockers, d.BlockerLayout); err != nil {
+			return nil, fmt.Errorf("difficulté %q: %w", d.Name, err)
+		}
+		gravity, err := ResolveGravityRule(d.Gravity, 0)
+		if err != nil {
+			return nil, fmt.Errorf("difficulté %q: %w", d.Name, err)
+		}
+		d.Gravity = gravity
+
+		reg.list = append(reg.list, d)
+		reg.byName[d.Name] = d
+	}
+	return reg, nil
+}
+
+// Get retourne un préréglage par son nom
+//
+// Retourne:
+//   - Difficulty: le préréglage
+//   - bool: false si aucun préréglage ne porte ce nom
+func (reg *DifficultyRegistry) Get(name string) (Difficulty, bool) {
+	d, ok := reg.byName[name]
+	return d, ok
+}
+
+// List retourne tous les préréglages, dans l'ordre du fichier
+func (reg *DifficultyRegistry) List() []Difficulty {
+	return append([]Difficulty{}, reg.list...)
+}
+
+//#endregion
+
+//#region VALIDATION DES DIMENSIONS
+
+// validateBoard vérifie qu'un plateau et ses règles sont jouables
+//
+// Paramètres:
+//   - rows, cols: dimensions (4 à 10, limite de l'interface et du bitboard)
+//   - winLength: jetons à aligner (MinWinLength à la plus grande dimension)
+//   - prefilled: jetons pré-remplis (au plus un quart des cases)
+//
+// Retourne:
+//   - error: description du premier paramètre invalide
+func validateBoard(rows, cols, winLength, prefilled int) error {
+	if rows < 4 || rows > 10 {
+		return errors.New("le nombre de lignes doit être entre 4 et 10")
+	}
+	if cols < 4 || cols > 10 {
+		return errors.New("le nombre de colonnes doit être entre 4 et 10")
+	}
+	if maxLength := max(rows, cols); winLength < MinWinLength || winLength > maxLength {
+		return fmt.Errorf("le nombre de jetons à aligner doit être entre %d et %d", MinWinLength, maxLength)
+	}
+	if maxPrefilled := rows * cols / 4; prefilled < 0 || prefilled > maxPrefilled {
+		return fmt.Errorf("le nombre de jetons pré-remplis doit être entre 0 et %d", maxPrefilled)
+	}
+	return nil
+}
+
+// validateBlockers vérifie les obstacles neutres demandés pour un plateau déjà validé
+//
+// Paramètres:
+//   - rows, cols: dimensions du plateau
+//   - winLength: jetons à aligner (un alignement doit rester possible malgré les obstacles)
+//   - count: obstacles placés au hasard
+//   - layout: cases murées imposées (dans le plateau, sans doublon)
+//
+// Retourne:
+//   - error: description du premier problème rencontré
+func validateBlockers(rows, cols, winLength, count int, layout []Move) error {
+	if maxBlockers := rows * cols / 4; count < 0 || count+len(layout) > maxBlockers {
+		return fmt.Errorf("le nombre d'obstacles doit être entre 0 et %d", maxBlockers)
+	}
+	board := NewBitboard(rows, cols)
+	for _, m := range layout {
+		if m.Row < 0 || m.Row >= rows || m.Col < 0 || m.Col >= cols {
+			return fmt.Errorf("obstacle hors du plateau: ligne %d, colonne %d", m.Row, m.Col)
+		}
+		if board.Cell(m.Row, m.Col) != "" {
+			return fmt.Errorf("obstacle en double: ligne %d, colonne %d", m.Row, m.Col)
+		}
+		board.Set(m.Row, m.Col, BlockedCell)
+	}
+	if !board.CanAlign("player1", winLength) {
+		return fmt.Errorf("les obstacles ne laissent aucun alignement de %d jetons possible", winLength)
+	}
+	return nil
+}
+
+//#endregion
+
+//#region HANDLER HTTP
+
+// HandleDifficulties retourne la liste des préréglages de difficulté
+//
+// Route: GET /api/difficulties
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request HTTP
+//
+// Réponse:
+//   - 200 OK: tableau des préréglages (nom, libellé, dimensions, jetons
+//     pré-remplis, obstacles, règle de gravité, thème et image de fond)
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleDifficulties(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+	respondJSON(w, http.StatusOK, gm.difficulties.List())
+}
+
+//#endregion
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+//#region TESTS DES PRÉRÉGLAGES DE DIFFICULTÉ
+
+// TestLoadDifficulties vérifie le fichier de préréglages livré avec le jeu
+func TestLoadDifficulties(t *testing.T) {
+	reg := testDifficulties(t)
+
+	names := []string{}
+	for _, d := range reg.List() {
+		names = append(names, d.Name)
+		if d.Gravity.Mode == "" || d.WinLength != DefaultWinLength {
+			t.Fatalf("%s: règles non résolues: %+v", d.Name, d)
+		}
+	}
+	if strings.Join(names, ",") != "easy,normal,hard" {
+		t.Fatalf("préréglages %v, attendu easy, normal, hard dans cet ordre", names)
+	}
+
+	hard, ok := reg.Get("hard")
+	if !ok || hard.Rows != 7 || hard.Cols != 8 || hard.Prefilled != 7 || hard.Blockers != 0 {
+		t.Fatalf("préréglage hard: %+v", hard)
+	}
+}
+
+// TestDifficultyRegistryRejectsInvalid vérifie la validation des préréglages
+func TestDifficultyRegistryRejectsInvalid(t *testing.T) {
+	valid := Difficulty{Name: "easy", Rows: 6, Cols: 7, Prefilled: 3}
+	for _, list := range [][]Difficulty{
+		{{Rows: 6, Cols: 7}},
+		{valid, valid},
+		{{Name: "big", Rows: 12, Cols: 7}},
+		{{Name: "full", Rows: 6, Cols: 7, Prefilled: 20}},
+		{{Name: "long", Rows: 6, Cols: 7, WinLength: 8}},
+		{{Name: "walls", Rows: 6, Cols: 7, Blockers: 11}},
+		{{Name: "outside", Rows: 6, Cols: 7, BlockerLayout: []Move{{Row: 6, Col: 0}}}},
+		{{Name: "twice", Rows: 6, Cols: 7, BlockerLayout: []Move{{Row: 1, Col: 1}, {Row: 1, Col: 1}}}},
+		{{Name: "odd", Rows: 6, Cols: 7, Gravity: GravityRule{Preset: "inconnu"}}},
+	} {
+		if _, err := NewDifficultyRegistry(list); err == nil {
+			t.Fatalf("préréglages %+v acceptés", list)
+		}
+	}
+}
+
+// TestNewGameByDifficulty vérifie la création d'une partie par le nom d'un préréglage
+func TestNewGameByDifficulty(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+
+	rec := httptest.NewRecorder()
+	gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new",
+		strings.NewReader(`{"difficulty":"hard","player1":"Alice","player2":"Bob"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("statut %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		ID         string `json:"id"`
+		Rows       int    `json:"rows"`
+		Cols       int    `json:"cols"`
+		Difficulty string `json:"difficulty"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Rows != 7 || resp.Cols != 8 || resp.Difficulty != "hard" {
+		t.Fatalf("partie %+v, attendu le préréglage hard", resp)
+	}
+	p1, p2 := countTokens(gm.getGame(resp.ID))
+	if p1+p2 != 7 {
+		t.Fatalf("%d jetons pré-remplis, attendu 7", p1+p2)
+	}
+	if n := gm.getGame(resp.ID).Board.Count(BlockedCell); n != 0 {
+		t.Fatalf("%d obstacles, attendu aucun", n)
+	}
+
+	rec = httptest.NewRecorder()
+	gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new",
+		strings.NewReader(`{"difficulty":"extreme","player1":"Alice","player2":"Bob"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("difficulté inconnue: statut %d, attendu 400", rec.Code)
+	}
+}
+
+// TestHandleDifficulties vérifie la liste exposée par /api/difficulties
+func TestHandleDifficulties(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	rec := httptest.NewRecorder()
+	gm.HandleDifficulties(rec, httptest.NewRequest("GET", "/api/difficulties", nil))
+
+	var list []Difficulty
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].Background == "" || list[0].Theme != "easy" {
+		t.Fatalf("préréglages reçus: %+v", list)
+	}
+}
+
+//#endregion
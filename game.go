@@ -4,25 +4,66 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 )
 
 //#region STRUCTURES DE DONNÉES
 
+// Bornes du nombre de jetons à aligner pour gagner
+const (
+	DefaultWinLength = 4 // Puissance 4 classique
+	MinWinLength     = 3 // En dessous, la partie serait jouée d'avance
+)
+
 // Game représente une partie complète de Puissance 4
 // Cette structure contient toutes les informations nécessaires pour gérer une partie
+//
+// Les méthodes exportées (DropPiece, GetState) verrouillent la partie :
+// elle peut donc être utilisée depuis plusieurs goroutines net/http à la fois.
 type Game struct {
-	Rows           int         `json:"rows"`           // Nombre de lignes du plateau (6, 7, etc.)
-	Cols           int         `json:"cols"`           // Nombre de colonnes du plateau (7, 8, 9, etc.)
-	Board          [][]string  `json:"board"`          // Plateau de jeu 2D : "" = vide, "player1" ou "player2"
-	CurrentPlayer  string      `json:"currentPlayer"`  // Joueur actuel ("player1" ou "player2")
-	Player1        string      `json:"player1"`        // Pseudo du joueur 1
-	Player2        string      `json:"player2"`        // Pseudo du joueur 2
-	GameOver       bool        `json:"gameOver"`       // true si la partie est terminée
-	Winner         string      `json:"winner"`         // Gagnant ("player1", "player2", "draw", ou "")
-	LastMove       *Move       `json:"lastMove"`       // Dernier coup joué (nil si aucun)
-	TurnCount      int         `json:"turnCount"`      // Nombre de tours joués (utilisé pour la gravité inversée)
-	InverseGravity bool        `json:"inverseGravity"` // true si la gravité est actuellement inversée
+	mu sync.Mutex // Protège tous les champs ci-dessous contre les accès concurrents
+
+	ID             string            `json:"id"`             // Identifiant unique attribué par le GameManager
+	Rows           int               `json:"rows"`           // Nombre de lignes du plateau (6, 7, etc.)
+	Cols           int               `json:"cols"`           // Nombre de colonnes du plateau (7, 8, 9, etc.)
+	Difficulty     string            `json:"difficulty"`     // Préréglage de difficulté ("" pour un plateau personnalisé)
+	PresetRules    bool              `json:"presetRules"`    // true si les règles du préréglage n'ont pas été modifiées (condition du classement)
+	WinLength      int               `json:"winLength"`      // Nombre de jetons à aligner pour gagner (4 par défaut)
+	Board          Bitboard          `json:"-"`              // Plateau de jeu (exporté en 2D par GetState : "" = vide, "player1" ou "player2")
+	CurrentPlayer  string            `json:"currentPlayer"`  // Joueur actuel ("player1" ou "player2")
+	Player1        string            `json:"player1"`        // Pseudo du joueur 1
+	Player2        string            `json:"player2"`        // Pseudo du joueur 2
+	GameOver       bool              `json:"gameOver"`       // true si la partie est terminée
+	Winner         string            `json:"winner"`         // Gagnant ("player1", "player2", "draw", ou "")
+	Reason         string            `json:"reason"`         // Cause de la fin de partie (ReasonAlignment, etc. ; "" en cours)
+	LastMove       *Move             `json:"lastMove"`       // Dernier coup joué (nil si aucun)
+	TurnCount      int               `json:"turnCount"`      // Nombre de tours joués (utilisé pour la gravité inversée)
+	InverseGravity bool              `json:"inverseGravity"` // true si la gravité est actuellement inversée
+	Gravity        GravityRule       `json:"gravity"`        // Règle d'inversion de la gravité choisie à la création
+	Bot            string            `json:"bot"`            // Joueur contrôlé par l'ordinateur ("player1", "player2" ou "")
+	BotLevel       int               `json:"botLevel"`       // Niveau de l'ordinateur (BotLevelMin à BotLevelMax)
+	Prefilled      []Token           `json:"prefilled"`      // Obstacles et jetons pré-remplis au démarrage (disposition initiale)
+	Seed           int64             `json:"seed"`           // Graine du générateur aléatoire (recrée la même disposition initiale)
+	SeatTokens     map[string]string `json:"-"`              // Jeton secret de chaque siège humain (jamais envoyé par GetState)
+	Users          map[string]string `json:"users"`          // Compte lié à chaque siège ("player1" → identifiant du compte)
+	RatingApplied  bool              `json:"ratingApplied"`  // true si le résultat a été compté dans le classement (voir applyRating)
+	CreatedAt      time.Time         `json:"createdAt"`      // Date de création de la partie
+	EndedAt        time.Time         `json:"endedAt"`        // Date de fin de la partie (nulle tant qu'elle n'est pas archivée, voir archiveGame)
+
+	TimeControl TimeControl              `json:"timeControl"` // Cadence de la partie (vide = sans pendules)
+	Clocks      map[string]time.Duration `json:"-"`           // Temps restant de chaque joueur au début du tour en cours
+	TurnStarted time.Time                `json:"-"`           // Début du tour en cours (nul tant que les pendules ne tournent pas)
+	DrawOffer   string                   `json:"drawOffer"`   // Joueur proposant la nulle ("" si aucune proposition en attente)
+
+	rng       *rand.Rand   // Générateur aléatoire propre à la partie (pré-remplissage, ordinateur)
+	history   []MoveRecord // Coups joués, du premier au dernier (pour l'annulation)
+	redoStack []MoveRecord // Coups annulés pouvant être rétablis (le dernier annulé en fin)
+	published int          // Nombre de coups de l'historique déjà diffusés aux clients WebSocket
+
+	publishedOver bool // true si la fin de partie a déjà été diffusée (voir unpublishedGameOver)
+
+	clockTimer *time.Timer // Minuteur de la défaite au temps du joueur actuel (voir watchClock)
 }
 
 // Move représente un coup joué sur le plateau
@@ -31,10 +72,35 @@ type Move struct {
 	Col int `json:"col"` // Colonne où le jeton a été déposé
 }
 
+// Causes de fin de partie
+const (
+	ReasonAlignment   = "alignment"    // WinLength jetons alignés (quatre en ligne par défaut)
+	ReasonFullBoard   = "full-board"   // Plateau plein sans alignement
+	ReasonNoAlignment = "no-alignment" // Plus aucun alignement possible pour les deux joueurs
+	ReasonResignation = "resignation"  // Abandon d'un joueur
+	ReasonAgreedDraw  = "agreed-draw"  // Nulle proposée et acceptée
+	ReasonTimeout     = "timeout"      // Pendule d'un joueur tombée à zéro
+)
+
 //#endregion
 
 //#region CRÉATION D'UNE NOUVELLE PARTIE
 
+// GameOptions regroupe les règles choisies à la création d'une partie
+//
+// Les champs laissés à zéro prennent la valeur par défaut
+// (Puissance 4 classique, gravité inversée tous les 5 tours).
+type GameOptions struct {
+	WinLength     int         // Nombre de jetons à aligner (0 = DefaultWinLength)
+	Prefilled     int         // Nombre de jetons pré-remplis au démarrage
+	Blockers      int         // Nombre d'obstacles neutres placés au hasard
+	BlockerLayout []Move      // Cases des obstacles neutres imposés par la difficulté
+	Gravity       GravityRule // Règle d'inversion de la gravité (vide = DefaultGravityPreset)
+	Seed          int64       // Graine du générateur aléatoire de la partie
+	EvenStart     bool        // Ne garder que des dispositions initiales jugées équilibrées par l'ordinateur
+	TimeControl   TimeControl // Cadence des pendules (vide = partie sans pendules)
+}
+
 // NewGame crée et initialise une nouvelle partie de Puissance 4
 //
 // Paramètres:
@@ -46,25 +112,50 @@ type Move struct {
 // Retourne:
 //   - *Game: pointeur vers la nouvelle partie initialisée
 //
-// La fonction initialise un plateau vide, ajoute des jetons pré-remplis selon
-// la difficulté, et configure le joueur 1 comme joueur de départ
+// La fonction initialise un plateau vide, sans jetons pré-remplis, et
+// configure le joueur 1 comme joueur de départ. Les règles sont celles par
+// défaut et la graine est tirée au hasard (voir NewGameWithOptions pour
+// choisir les règles et les jetons pré-remplis d'une difficulté).
 func NewGame(rows, cols int, player1, player2 string) *Game {
-	// Initialisation du plateau vide (tableau 2D)
-	board := make([][]string, rows)
-	for i := range board {
-		board[i] = make([]string, cols)
-		// Remplissage avec des chaînes vides (cases vides)
-		for j := range board[i] {
-			board[i][j] = ""
-		}
+	return NewGameWithOptions(rows, cols, player1, player2, GameOptions{Seed: newSeed()})
+}
+
+// newSeed tire au hasard la graine d'une nouvelle partie
+//
+// Elle est limitée à 2^53 pour rester exacte une fois lue en JavaScript
+// (le client peut la renvoyer telle quelle pour recréer la partie).
+func newSeed() int64 {
+	return rand.Int63n(1 << 53)
+}
+
+// NewGameWithOptions crée une partie avec des règles choisies
+//
+// Le hasard de la partie est entièrement déterminé par opts.Seed : deux
+// parties créées avec les mêmes dimensions et les mêmes options ont
+// exactement les mêmes obstacles et jetons pré-remplis.
+//
+// Paramètres:
+//   - rows, cols: dimensions du plateau
+//   - player1, player2: pseudos des joueurs
+//   - opts: règles de la partie (déjà validées par l'appelant)
+//
+// Retourne:
+//   - *Game: pointeur vers la nouvelle partie initialisée
+func NewGameWithOptions(rows, cols int, player1, player2 string, opts GameOptions) *Game {
+	if opts.WinLength == 0 {
+		opts.WinLength = DefaultWinLength
+	}
+	if opts.Gravity.Mode == "" {
+		opts.Gravity, _ = ResolveGravityRule(GravityRule{}, opts.Seed)
 	}
 
 	// Création de la structure Game avec les valeurs par défaut
 	game := &Game{
 		Rows:           rows,
 		Cols:           cols,
-		Board:          board,
-		CurrentPlayer:  "player1", // Le joueur 1 commence toujours
+		WinLength:      opts.WinLength,
+		Board:          NewBitboard(rows, cols), // Plateau vide
+		CurrentPlayer:  "player1",               // Le joueur 1 commence toujours
 		Player1:        player1,
 		Player2:        player2,
 		GameOver:       false,
@@ -72,88 +163,37 @@ func NewGame(rows, cols int, player1, player2 string) *Game {
 		LastMove:       nil,
 		TurnCount:      0,
 		InverseGravity: false,
+		Gravity:        opts.Gravity,
+		Seed:           opts.Seed,
+		TimeControl:    opts.TimeControl,
+		CreatedAt:      time.Now(),
+	}
+	if opts.TimeControl.enabled() {
+		game.Clocks = map[string]time.Duration{
+			"player1": opts.TimeControl.initial(),
+			"player2": opts.TimeControl.initial(),
+		}
 	}
 
-	// Ajout de jetons pré-remplis selon la difficulté
-	numPrefilledBlocks := getPrefilledBlocksCount(rows, cols)
-	game.addPrefilledBlocks(numPrefilledBlocks)
+	// Ajout des obstacles puis des jetons pré-remplis demandés (selon la difficulté)
+	game.addBlockers(opts.BlockerLayout, opts.Blockers)
+	game.addPrefilledBlocks(opts.Prefilled, opts.EvenStart)
 
 	return game
 }
 
-// getPrefilledBlocksCount détermine le nombre de jetons à pré-remplir
-// selon les dimensions du plateau (qui correspondent à une difficulté)
+// random retourne le générateur aléatoire de la partie
 //
-// Paramètres:
-//   - rows: nombre de lignes
-//   - cols: nombre de colonnes
+// Il est créé à partir de Seed à la première utilisation (y compris pour
+// une partie rechargée depuis le stockage). Contrairement au générateur
+// global, il n'est partagé avec aucune autre partie.
 //
-// Retourne:
-//   - int: nombre de jetons à placer aléatoirement au départ
-//
-// Règles:
-//   - Facile (6x7) : 3 jetons
-//   - Normal (6x9) : 5 jetons
-//   - Difficile (7x8) : 7 jetons
-func getPrefilledBlocksCount(rows, cols int) int {
-	// Mode Facile: grille 6x7 = 3 blocs pré-remplis
-	if rows == 6 && cols == 7 {
-		return 3
-	}
-	// Mode Normal: grille 6x9 = 5 blocs pré-remplis
-	if rows == 6 && cols == 9 {
-		return 5
-	}
-	// Mode Difficile: grille 7x8 = 7 blocs pré-remplis
-	if rows == 7 && cols == 8 {
-		return 7
-	}
-	// Par défaut (dimensions personnalisées), pas de blocs
-	return 0
-}
-
-// addPrefilledBlocks ajoute des jetons aléatoires au plateau au démarrage
-//
-// Cette fonction place aléatoirement le nombre spécifié de jetons sur le plateau,
-// en respectant la gravité (les jetons tombent dans les colonnes)
-//
-// Paramètres:
-//   - count: nombre de jetons à placer
-func (g *Game) addPrefilledBlocks(count int) {
-	// Si count = 0, ne rien faire
-	if count == 0 {
-		return
-	}
-
-	// Initialisation du générateur de nombres aléatoires
-	rand.Seed(time.Now().UnixNano())
-
-	// Liste des joueurs possibles pour les jetons
-	players := []string{"player1", "player2"}
-	placed := 0 // Compteur de jetons placés
-
-	// Boucle jusqu'à avoir placé tous les jetons
-	for placed < count {
-		// Sélection d'une colonne aléatoire
-		col := rand.Intn(g.Cols)
-
-		// Recherche de la première case vide dans la colonne (du bas vers le haut)
-		row := -1
-		for r := g.Rows - 1; r >= 0; r-- {
-			if g.Board[r][col] == "" {
-				row = r
-				break
-			}
-		}
-
-		// Si la colonne n'est pas pleine, placer un jeton
-		if row != -1 {
-			// Sélection aléatoire du joueur (player1 ou player2)
-			g.Board[row][col] = players[rand.Intn(2)]
-			placed++
-		}
-		// Si la colonne est pleine, on essaye une autre colonne au prochain tour de boucle
+// L'appelant doit détenir g.mu (ou être seul à connaître la partie).
+func (g *Game) random() *rand.Rand {
+	if g.rng == nil {
+		g.rng = rand.New(rand.NewSource(g.Seed))
 	}
+	return g.rng
 }
 
 //#endregion
@@ -166,7 +206,7 @@ func (g *Game) addPrefilledBlocks(count int) {
 // 1. Vérifie que le coup est valide (partie non terminée, colonne valide)
 // 2. Trouve la première case vide dans la colonne (selon la gravité)
 // 3. Place le jeton du joueur actuel
-// 4. Incrémente le compteur de tours et gère la gravité inversée
+// 4. Incrémente le compteur de tours et gère la gravité inversée (selon Gravity)
 // 5. Vérifie les conditions de victoire ou d'égalité
 // 6. Change de joueur si la partie continue
 //
@@ -175,68 +215,126 @@ func (g *Game) addPrefilledBlocks(count int) {
 //
 // Retourne:
 //   - error: nil si le coup est valide, une erreur sinon
+//
+// DropPiece joue pour un humain : le coup est refusé si c'est
+// au tour de l'ordinateur (voir PlayBotTurn).
 func (g *Game) DropPiece(col int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Bot != "" && g.CurrentPlayer == g.Bot && !g.GameOver {
+		return errors.New("c'est au tour de l'ordinateur")
+	}
+	return g.dropPiece(col, false)
+}
+
+// dropPiece contient la logique de DropPiece, sans verrouillage
+//
+// Le coup est inscrit dans l'historique et efface les coups annulés
+// qui pouvaient encore être rétablis. En partie chronométrée, le coup
+// est refusé (errTimeUp) si le joueur n'a plus de temps, puis sa
+// pendule s'arrête et celle de l'adversaire démarre.
+//
+// Paramètres:
+//   - col: numéro de la colonne (0 à Cols-1)
+//   - flip: true pour un coup spécial qui inverse la gravité (mode GravitySpecial)
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) dropPiece(col int, flip bool) error {
+	// Un joueur tombé au temps ne peut plus jouer, même avant le minuteur
+	now := time.Now()
+	if g.checkTime(now) {
+		return errTimeUp
+	}
+
+	player := g.CurrentPlayer
+	before := g.saveTurnState()
+
+	// Le coup spécial consomme une des charges du joueur
+	if flip && !g.GameOver && g.specialFlipsLeft(player) < 1 {
+		return errors.New("aucun coup spécial disponible")
+	}
+
+	row, err := g.applyMove(col, flip)
+	if err != nil {
+		return err
+	}
+
+	g.history = append(g.history, MoveRecord{
+		Turn:           before.TurnCount + 1,
+		Row:            row,
+		Col:            col,
+		Player:         player,
+		InverseGravity: before.InverseGravity,
+		FlipGravity:    flip,
+		before:         before,
+		after:          g.saveTurnState(),
+	})
+	g.redoStack = nil
+	g.DrawOffer = "" // Jouer un coup vaut refus de la nulle proposée
+	g.tickClock(player, now)
+	return nil
+}
+
+// applyMove applique les règles du jeu pour un coup, sans l'inscrire dans l'historique
+//
+// Paramètres:
+//   - col: numéro de la colonne (0 à Cols-1)
+//   - flip: true si le coup inverse la gravité en plus de la règle automatique
+//
+// Retourne:
+//   - int: ligne où le jeton s'est posé
+//   - error: nil si le coup est valide, une erreur sinon
+func (g *Game) applyMove(col int, flip bool) (int, error) {
 	// Vérification 1: la partie ne doit pas être terminée
 	if g.GameOver {
-		return errors.New("la partie est terminée")
+		return -1, errors.New("la partie est terminée")
 	}
 
 	// Vérification 2: la colonne doit être valide
 	if col < 0 || col >= g.Cols {
-		return fmt.Errorf("colonne invalide: %d (doit être entre 0 et %d)", col, g.Cols-1)
+		return -1, fmt.Errorf("colonne invalide: %d (doit être entre 0 et %d)", col, g.Cols-1)
 	}
 
-	// Recherche de la première case vide selon la gravité actuelle
-	row := -1
-	if g.InverseGravity {
-		// Gravité inversée : les jetons "tombent" vers le haut
-		// On cherche donc la première case vide en partant du haut
-		for r := 0; r < g.Rows; r++ {
-			if g.Board[r][col] == "" {
-				row = r
-				break
-			}
-		}
-	} else {
-		// Gravité normale : les jetons tombent vers le bas
-		// On cherche donc la première case vide en partant du bas
-		for r := g.Rows - 1; r >= 0; r-- {
-			if g.Board[r][col] == "" {
-				row = r
-				break
-			}
-		}
-	}
+	// Recherche de la première case vide selon la gravité actuelle :
+	// en partant du bas en gravité normale, du haut en gravité inversée
+	row := g.Board.LandingRow(col, g.InverseGravity)
 
 	// Vérification 3: la colonne ne doit pas être pleine
 	if row == -1 {
-		return errors.New("colonne pleine")
+		return -1, errors.New("colonne pleine")
 	}
 
 	// Placement du jeton
-	g.Board[row][col] = g.CurrentPlayer
+	g.Board.Set(row, col, g.CurrentPlayer)
 	g.LastMove = &Move{Row: row, Col: col}
 
 	// Incrémentation du compteur de tours
 	g.TurnCount++
 
-	// Gestion de la gravité inversée : s'active tous les 5 tours
-	if g.TurnCount%5 == 0 {
+	// Gestion de la gravité inversée selon la règle de la partie
+	// (ou par coup spécial, qui s'ajoute à la règle automatique)
+	if flip || g.Gravity.flipsAfter(g.TurnCount) {
 		g.InverseGravity = !g.InverseGravity // Inverse l'état actuel
 	}
 
-	// Vérification de la victoire (4 jetons alignés)
+	// Vérification de la victoire (WinLength jetons alignés)
 	if g.checkWin(row, col) {
 		g.GameOver = true
 		g.Winner = g.CurrentPlayer
-		return nil // Partie terminée, pas besoin de changer de joueur
+		g.Reason = ReasonAlignment
+		return row, nil // Partie terminée, pas besoin de changer de joueur
 	}
 
 	// Vérification de l'égalité (plateau plein)
 	if g.checkDraw() {
 		g.GameOver = true
 		g.Winner = "draw"
-		return nil // Partie terminée
+		g.Reason = ReasonNoAlignment
+		if g.Board.IsFull() {
+			g.Reason = ReasonFullBoard
+		}
+		return row, nil // Partie terminée
 	}
 
 	// Changement de joueur pour le prochain tour
@@ -246,7 +344,7 @@ func (g *Game) DropPiece(col int) error {
 		g.CurrentPlayer = "player1"
 	}
 
-	return nil
+	return row, nil
 }
 
 //#endregion
@@ -255,12 +353,16 @@ func (g *Game) DropPiece(col int) error {
 
 // checkWin vérifie si le dernier coup joué a créé un alignement gagnant
 //
-// Un joueur gagne en alignant 4 jetons dans l'une des 4 directions :
+// Un joueur gagne en alignant WinLength jetons (4 par défaut) dans l'une
+// des 4 directions :
 // - Horizontal (←→)
 // - Vertical (↑↓)
 // - Diagonale descendante (\)
 // - Diagonale montante (/)
 //
+// La détection est déléguée au bitboard (Bitboard.WinsThrough), qui ne
+// considère que les alignements passant par la case jouée.
+//
 // Paramètres:
 //   - row: ligne du dernier jeton placé
 //   - col: colonne du dernier jeton placé
@@ -268,92 +370,40 @@ func (g *Game) DropPiece(col int) error {
 // Retourne:
 //   - bool: true si le coup est gagnant, false sinon
 func (g *Game) checkWin(row, col int) bool {
-	// Récupération du joueur qui vient de jouer
-	player := g.Board[row][col]
-
-	// Définition des 4 directions à vérifier
-	// Chaque direction est définie par un vecteur [dRow, dCol]
-	directions := [][2]int{
-		{0, 1},  // Horizontal (même ligne, colonnes ++)
-		{1, 0},  // Vertical (lignes ++, même colonne)
-		{1, 1},  // Diagonale descendante (\)
-		{1, -1}, // Diagonale montante (/)
-	}
+	return g.Board.WinsThrough(row, col, g.WinLength)
+}
 
-	// Vérification dans chaque direction
-	for _, dir := range directions {
-		// Comptage des jetons alignés :
-		// 1 (le jeton actuel) +
-		// count dans une direction +
-		// count dans la direction opposée
-		count := 1 +
-			g.countDirection(row, col, dir[0], dir[1], player) +     // Direction positive
-			g.countDirection(row, col, -dir[0], -dir[1], player)     // Direction négative (opposée)
-
-		// Si on trouve 4 jetons alignés ou plus : victoire !
-		if count >= 4 {
-			return true
-		}
-	}
+//#endregion
 
-	// Aucun alignement de 4 jetons trouvé
-	return false
-}
+//#region VÉRIFICATION D'ÉGALITÉ
 
-// countDirection compte le nombre de jetons alignés dans une direction donnée
-//
-// Cette fonction part de la position actuelle et compte combien de jetons
-// du même joueur sont alignés dans la direction spécifiée, jusqu'à rencontrer
-// une case vide, un jeton adverse, ou le bord du plateau
+// checkDraw vérifie si la partie ne peut plus être gagnée (égalité)
 //
-// Paramètres:
-//   - row: ligne de départ
-//   - col: colonne de départ
-//   - dRow: déplacement en ligne (+1 = bas, -1 = haut, 0 = même ligne)
-//   - dCol: déplacement en colonne (+1 = droite, -1 = gauche, 0 = même colonne)
-//   - player: joueur dont on compte les jetons
+// Une égalité se produit quand toutes les cases sont remplies sans
+// alignement, ou plus tôt quand aucun joueur ne dispose encore de
+// WinLength cases consécutives libres de jetons adverses (fréquent
+// quand WinLength est grand par rapport au plateau)
 //
 // Retourne:
-//   - int: nombre de jetons alignés dans cette direction (sans compter la position de départ)
-func (g *Game) countDirection(row, col, dRow, dCol int, player string) int {
-	count := 0
-	// Position suivante dans la direction
-	r, c := row+dRow, col+dCol
-
-	// Continue tant qu'on est dans les limites ET que la case contient le même joueur
-	for r >= 0 && r < g.Rows && c >= 0 && c < g.Cols && g.Board[r][c] == player {
-		count++
-		// Déplacement vers la case suivante
-		r += dRow
-		c += dCol
+//   - bool: true si plus aucun joueur ne peut gagner, false sinon
+func (g *Game) checkDraw() bool {
+	if g.Board.IsFull() {
+		return true
 	}
-
-	return count
+	return !g.Board.CanAlign("player1", g.WinLength) && !g.Board.CanAlign("player2", g.WinLength)
 }
 
-//#endregion
-
-//#region VÉRIFICATION D'ÉGALITÉ
-
-// checkDraw vérifie si le plateau est complètement rempli (égalité)
+// winningCells retourne les cases de l'alignement gagnant (vide sans vainqueur)
 //
-// Une égalité se produit quand toutes les cases sont remplies
-// et qu'aucun joueur n'a aligné 4 jetons
+// Elles se déduisent du plateau et du dernier coup : rien à mémoriser,
+// l'annulation et le rechargement d'une partie restent cohérents.
 //
-// Retourne:
-//   - bool: true si le plateau est plein, false s'il reste au moins une case vide
-func (g *Game) checkDraw() bool {
-	// Parcours de toutes les cases du plateau
-	for row := 0; row < g.Rows; row++ {
-		for col := 0; col < g.Cols; col++ {
-			// Si on trouve une case vide, ce n'est pas une égalité
-			if g.Board[row][col] == "" {
-				return false
-			}
-		}
+// L'appelant doit détenir g.mu.
+func (g *Game) winningCells() []Move {
+	if g.LastMove == nil || (g.Winner != "player1" && g.Winner != "player2") {
+		return []Move{}
 	}
-	// Toutes les cases sont remplies : c'est une égalité
-	return true
+	return g.Board.WinningCells(g.LastMove.Row, g.LastMove.Col, g.WinLength)
 }
 
 //#endregion
@@ -367,19 +417,66 @@ func (g *Game) checkDraw() bool {
 //
 // Retourne:
 //   - map[string]interface{}: dictionnaire contenant toutes les informations de la partie
+//
+// Le plateau et le dernier coup sont copiés : la map retournée peut être
+// sérialisée sans verrou pendant que d'autres coups sont joués.
 func (g *Game) GetState() map[string]interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.getState()
+}
+
+// getState contient la logique de GetState, sans verrouillage
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) getState() map[string]interface{} {
+	// Conversion du plateau au format 2D attendu par le client
+	board := g.Board.Grid()
+
+	// Copie du dernier coup
+	var lastMove *Move
+	if g.LastMove != nil {
+		m := *g.LastMove
+		lastMove = &m
+	}
+
+	// Coups spéciaux restants (règle de gravité "special")
+	specialFlips := map[string]int{
+		"player1": g.specialFlipsLeft("player1"),
+		"player2": g.specialFlipsLeft("player2"),
+	}
+
 	return map[string]interface{}{
+		"id":             g.ID,
 		"rows":           g.Rows,
 		"cols":           g.Cols,
-		"board":          g.Board,
+		"difficulty":     g.Difficulty,
+		"winLength":      g.WinLength,
+		"board":          board,
 		"currentPlayer":  g.CurrentPlayer,
 		"player1":        g.Player1,
 		"player2":        g.Player2,
 		"gameOver":       g.GameOver,
 		"winner":         g.Winner,
-		"lastMove":       g.LastMove,
+		"reason":         g.Reason,
+		"winningCells":   g.winningCells(),
+		"lastMove":       lastMove,
 		"turnCount":      g.TurnCount,
 		"inverseGravity": g.InverseGravity,
+		"gravity":        g.Gravity,
+		"seed":           g.Seed,
+		"gravityFlipIn":  g.gravityFlipIn(),
+		"specialFlips":   specialFlips,
+		"bot":            g.Bot,
+		"botLevel":       g.BotLevel,
+		"canUndo":        g.canUndo(),
+		"canRedo":        g.canRedo(),
+		"timeControl":    g.TimeControl,
+		"clocks":         g.clockState(),
+		"clockRunning":   g.clockRunning(),
+		"drawOffer":      g.DrawOffer,
+		"users":          g.Users,
+		"rated":          g.ranked(),
 	}
 }
 
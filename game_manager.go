@@ -1,29 +1,228 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"sync"
+	"time"
 )
 
 //#region GESTIONNAIRE DE PARTIES
 
-// GameManager gère l'état d'une partie en cours
+// GameManager gère l'ensemble des parties en cours
 //
-// Cette structure maintient une seule partie active à la fois.
-// Elle pourrait être étendue pour gérer plusieurs parties simultanées
-// (par exemple avec un map[sessionID]*Game)
+// Chaque partie est identifiée par un identifiant unique généré à sa création.
+// Plusieurs parties peuvent ainsi se dérouler simultanément (un onglet de
+// navigateur = une partie) sans qu'une nouvelle partie n'écrase les autres.
 type GameManager struct {
-	game *Game // Partie actuellement en cours (nil si aucune partie active)
+	mu    sync.RWMutex     // Protège l'accès concurrent à la map des parties
+	games map[string]*Game // Parties en cours, indexées par leur identifiant
+	store GameStore        // Stockage persistant des parties
+	hub   *EventHub        // Diffusion des événements aux clients WebSocket
+
+	difficulties *DifficultyRegistry // Préréglages acceptés par /api/game/new
+	accounts     *Accounts           // Comptes des joueurs (sièges liés à un compte)
+	archive      *GameArchive        // Parties terminées, consultables par /api/games
+	lobby        *Lobby              // File d'attente des parties rapides en ligne
+	analyses     chan struct{}       // Places des analyses /api/analyze en cours
+
+	analyzeMaxTime time.Duration // Durée maximale d'une analyse /api/analyze (solverMaxTime par défaut)
 }
 
 // NewGameManager crée un nouveau gestionnaire de jeu
 //
+// Paramètres:
+//   - store: stockage dans lequel les parties sont enregistrées
+//   - difficulties: préréglages de difficulté proposés aux joueurs
+//   - accounts: comptes auxquels les sièges peuvent être liés (un classement par préréglage)
+//
 // Retourne:
 //   - *GameManager: nouveau gestionnaire sans partie active
-func NewGameManager() *GameManager {
+func NewGameManager(store GameStore, difficulties *DifficultyRegistry, accounts *Accounts) *GameManager {
+	accounts.setRatedPresets(difficulties)
 	return &GameManager{
-		game: nil, // Aucune partie au démarrage
+		games:        make(map[string]*Game), // Aucune partie au démarrage
+		store:        store,
+		hub:          NewEventHub(),
+		difficulties: difficulties,
+		accounts:     accounts,
+		archive:      newGameArchive(),
+		lobby:        newLobby(),
+		analyses:     make(chan struct{}, solverMaxAnalyses),
+
+		analyzeMaxTime: solverMaxTime,
+	}
+}
+
+// LoadGames recharge toutes les parties du stockage (au démarrage du serveur)
+//
+// Une partie illisible est ignorée (et signalée dans les logs)
+// pour ne pas empêcher le serveur de démarrer. L'archive des parties
+// terminées est rechargée en même temps.
+//
+// Retourne:
+//   - int: nombre de parties rechargées (hors archive)
+//   - error: si le stockage ne peut pas être lu
+func (gm *GameManager) LoadGames() (int, error) {
+	records, err := gm.store.LoadAll()
+	if err != nil {
+		return 0, err
+	}
+	archived, err := gm.store.LoadArchive()
+	if err != nil {
+		return 0, err
+	}
+	for _, game := range archived {
+		gm.archive.put(game)
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	for _, record := range records {
+		game, err := GameFromRecord(record)
+		if err != nil {
+			log.Println("Partie ignorée:", err)
+			continue
+		}
+		gm.games[game.ID] = game
+
+		// Une partie terminée enregistrée sans date de fin reprend celle de l'archive
+		if archived, ok := gm.archive.Get(game.ID); ok && game.GameOver && game.EndedAt.IsZero() {
+			game.EndedAt = archived.EndedAt
+		}
+
+		// Les pendules ont continué de tourner pendant l'arrêt du serveur
+		game.mu.Lock()
+		gm.watchClock(game)
+		game.mu.Unlock()
+	}
+	return len(gm.games), nil
+}
+
+// commitGame enregistre une partie modifiée et diffuse ses changements
+//
+// La partie est enregistrée dans le stockage puis les nouveaux coups et
+// le nouvel état sont poussés aux clients WebSocket abonnés.
+//
+// Une partie classée qui vient de se terminer met à jour le classement
+// de ses deux joueurs avant d'être enregistrée (voir applyRating) ;
+// une partie qui vient de se terminer est archivée, et retirée de
+// l'archive si une annulation la rouvre (voir archiveGame).
+//
+// La défaite au temps du joueur actuel est ensuite reprogrammée (voir
+// watchClock), puisque le coup a changé l'échéance de sa pendule.
+//
+// La partie reste verrouillée pendant toute l'opération : deux
+// enregistrements (ou diffusions) concurrents d'une même partie ne
+// peuvent pas s'inverser. Une erreur d'écriture est signalée dans les
+// logs sans interrompre la partie.
+//
+// Paramètres:
+//   - game: partie modifiée
+func (gm *GameManager) commitGame(game *Game) {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+	gm.applyRating(game)
+	gm.archiveGame(game)
+	if err := gm.store.Save(game.record()); err != nil {
+		log.Printf("Erreur de sauvegarde de la partie %s: %v", game.ID, err)
+	}
+	gm.hub.Publish(game.ID, moveEvents(game.unpublishedMoves(), game.getState(), game.unpublishedGameOver())...)
+	gm.watchClock(game)
+}
+
+// newGameID génère un identifiant de partie aléatoire
+//
+// Retourne:
+//   - string: identifiant hexadécimal de 16 caractères
+func newGameID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // La source d'aléa du système ne devrait jamais échouer
+	}
+	return hex.EncodeToString(b)
+}
+
+// addGame enregistre une partie et lui attribue un identifiant unique
+//
+// Paramètres:
+//   - game: partie à enregistrer
+//
+// Retourne:
+//   - string: identifiant attribué à la partie
+func (gm *GameManager) addGame(game *Game) string {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	// Tirage d'un identifiant jusqu'à en obtenir un libre
+	id := newGameID()
+	for gm.games[id] != nil {
+		id = newGameID()
 	}
+	game.ID = id
+	gm.games[id] = game
+	return id
+}
+
+// getGame retrouve une partie à partir de son identifiant
+//
+// Paramètres:
+//   - id: identifiant de la partie
+//
+// Retourne:
+//   - *Game: la partie correspondante (nil si inconnue)
+func (gm *GameManager) getGame(id string) *Game {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.games[id]
+}
+
+// removeGame supprime une partie du gestionnaire
+//
+// Paramètres:
+//   - id: identifiant de la partie à supprimer
+//
+// Retourne:
+//   - bool: true si la partie existait
+func (gm *GameManager) removeGame(id string) bool {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	if _, ok := gm.games[id]; !ok {
+		return false
+	}
+	delete(gm.games, id)
+	return true
+}
+
+// gameFromRequest retrouve la partie désignée par le paramètre "id" de l'URL
+//
+// Envoie directement une erreur au client si l'identifiant est absent
+// ou ne correspond à aucune partie.
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer l'éventuelle erreur
+//   - r: Request contenant le paramètre ?id=
+//
+// Retourne:
+//   - *Game: la partie trouvée (nil si une erreur a été envoyée)
+func (gm *GameManager) gameFromRequest(w http.ResponseWriter, r *http.Request) *Game {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		respondError(w, http.StatusBadRequest, "Identifiant de partie manquant")
+		return nil
+	}
+
+	game := gm.getGame(id)
+	if game == nil {
+		respondError(w, http.StatusNotFound, "Partie introuvable")
+		return nil
+	}
+	return game
 }
 
 //#endregion
@@ -36,10 +235,22 @@ func NewGameManager() *GameManager {
 // Body JSON attendu:
 //
 //	{
-//	  "rows": 6,
+//	  "difficulty": "easy", // préréglage (voir /api/difficulties), ou bien:
+//	  "rows": 6,            // dimensions d'un plateau personnalisé
 //	  "cols": 7,
+//	  "prefilled": 0,    // optionnel: jetons pré-remplis d'un plateau personnalisé
+//	  "blockers": 2,     // optionnel: obstacles neutres placés au hasard
+//	  "blockerLayout": [{"row": 5, "col": 3}], // optionnel: cases murées par un obstacle
+//	  "winLength": 4,    // optionnel: jetons à aligner pour gagner (4 par défaut)
 //	  "player1": "Alice",
-//	  "player2": "Bob"
+//	  "player2": "Bob",
+//	  "gravity": {"preset": "classic"}, // optionnel: règle de gravité (préréglage ou mode détaillé)
+//	  "seed": 42,        // optionnel: graine pour recréer la même disposition initiale
+//	  "evenStart": true, // optionnel: disposition initiale jugée équilibrée par l'ordinateur
+//	  "bot": "player2",  // optionnel: joueur contrôlé par l'ordinateur
+//	  "botLevel": 3,     // niveau de l'ordinateur (1 = aléatoire à 5)
+//	  "timeControl": {"base": 300, "increment": 5}, // optionnel: pendules (ou {"perMove": 30})
+//	  "bindSeat": "player1" // optionnel: siège lié au compte connecté (cookie de session)
 //	}
 //
 // Paramètres:
@@ -47,9 +258,18 @@ func NewGameManager() *GameManager {
 //   - r: Request contenant les données de la nouvelle partie
 //
 // Réponse:
-//   - 200 OK: État initial de la partie
-//   - 400 Bad Request: Paramètres invalides
+//   - 200 OK: État initial de la partie (dont son identifiant "id"), avec en plus:
+//   - "seats": jeton secret de chaque siège humain ({"player1": "...", "player2": "..."})
+//   - "invite": lien à envoyer au second joueur pour qu'il rejoigne la partie
+//   - 400 Bad Request: Paramètres invalides ou difficulté inconnue
+//   - 401 Unauthorized: "bindSeat" sans session valide
 //   - 405 Method Not Allowed: Méthode HTTP incorrecte
+//
+// Avec "bindSeat", le pseudo du siège est l'identifiant du compte.
+//
+// Avec "difficulty", les dimensions, les jetons pré-remplis et les
+// obstacles viennent du préréglage ; "winLength" et "gravity" le remplacent s'ils sont fournis.
+// Une partie dont ces règles diffèrent du préréglage n'est jamais classée.
 func (gm *GameManager) HandleNewGame(w http.ResponseWriter, r *http.Request) {
 	// Vérification de la méthode HTTP
 	if r.Method != "POST" {
@@ -59,10 +279,22 @@ func (gm *GameManager) HandleNewGame(w http.ResponseWriter, r *http.Request) {
 
 	// Structure pour décoder le JSON de la requête
 	var req struct {
-		Rows    int    `json:"rows"`    // Nombre de lignes souhaité
-		Cols    int    `json:"cols"`    // Nombre de colonnes souhaité
-		Player1 string `json:"player1"` // Pseudo du joueur 1
-		Player2 string `json:"player2"` // Pseudo du joueur 2
+		Difficulty    string      `json:"difficulty"`    // Nom d'un préréglage ("" pour un plateau personnalisé)
+		Rows          int         `json:"rows"`          // Nombre de lignes souhaité
+		Cols          int         `json:"cols"`          // Nombre de colonnes souhaité
+		Prefilled     int         `json:"prefilled"`     // Jetons pré-remplis d'un plateau personnalisé
+		Blockers      int         `json:"blockers"`      // Obstacles neutres aléatoires d'un plateau personnalisé
+		BlockerLayout []Move      `json:"blockerLayout"` // Obstacles neutres imposés d'un plateau personnalisé
+		WinLength     int         `json:"winLength"`     // Jetons à aligner pour gagner (0 = DefaultWinLength)
+		Gravity       GravityRule `json:"gravity"`       // Règle d'inversion de la gravité (vide = DefaultGravityPreset)
+		Seed          *int64      `json:"seed"`          // Graine du hasard de la partie (absente = tirée au hasard)
+		EvenStart     bool        `json:"evenStart"`     // Disposition initiale évaluée comme équilibrée par l'ordinateur
+		Player1       string      `json:"player1"`       // Pseudo du joueur 1
+		Player2       string      `json:"player2"`       // Pseudo du joueur 2
+		Bot           string      `json:"bot"`           // Joueur contrôlé par l'ordinateur ("" si aucun)
+		BotLevel      int         `json:"botLevel"`      // Niveau de l'ordinateur
+		TimeControl   TimeControl `json:"timeControl"`   // Cadence des pendules (vide = sans pendules)
+		BindSeat      string      `json:"bindSeat"`      // Siège lié au compte connecté ("" si aucun)
 	}
 
 	// Décodage du JSON
@@ -71,29 +303,135 @@ func (gm *GameManager) HandleNewGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validation: nombre de lignes (4 minimum pour pouvoir gagner, 10 maximum pour l'UI)
-	if req.Rows < 4 || req.Rows > 10 {
-		respondError(w, http.StatusBadRequest, "Nombre de lignes doit être entre 4 et 10")
+	// Préréglage de difficulté : il fixe le plateau et fournit les règles par défaut
+	var preset Difficulty
+	if req.Difficulty != "" {
+		var ok bool
+		preset, ok = gm.difficulties.Get(req.Difficulty)
+		if !ok {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Difficulté inconnue: %q", req.Difficulty))
+			return
+		}
+		req.Rows, req.Cols, req.Prefilled = preset.Rows, preset.Cols, preset.Prefilled
+		req.Blockers, req.BlockerLayout = preset.Blockers, preset.BlockerLayout
+		if req.WinLength == 0 {
+			req.WinLength = preset.WinLength
+		}
+		if req.Gravity == (GravityRule{}) {
+			req.Gravity = preset.Gravity
+		}
+	}
+
+	// Validation: dimensions (4 à 10 pour l'UI), alignement qui tient sur le
+	// plateau et nombre raisonnable de jetons pré-remplis
+	if req.WinLength == 0 {
+		req.WinLength = DefaultWinLength
+	}
+	if err := validateBoard(req.Rows, req.Cols, req.WinLength, req.Prefilled); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := validateBlockers(req.Rows, req.Cols, req.WinLength, req.Blockers, req.BlockerLayout); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Graine de la partie : celle du client pour recréer une disposition connue
+	seed := newSeed()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
 
-	// Validation: nombre de colonnes (4 minimum pour pouvoir gagner, 10 maximum pour l'UI)
-	if req.Cols < 4 || req.Cols > 10 {
-		respondError(w, http.StatusBadRequest, "Nombre de colonnes doit être entre 4 et 10")
+	// Validation: règle de gravité (préréglage connu ou paramètres cohérents)
+	gravity, err := ResolveGravityRule(req.Gravity, seed)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	// Seul un préréglage joué sans règle modifiée peut compter pour le classement
+	presetRules := false
+	if req.Difficulty != "" {
+		presetGravity, err := ResolveGravityRule(preset.Gravity, seed)
+		presetRules = err == nil && req.WinLength == preset.WinLength && gravity.sameAs(presetGravity)
+	}
+
+	// Siège lié au compte connecté : son identifiant devient le pseudo du siège
+	var account Profile
+	if req.BindSeat != "" {
+		if (req.BindSeat != "player1" && req.BindSeat != "player2") || req.BindSeat == req.Bot {
+			respondError(w, http.StatusBadRequest, "Le siège lié au compte doit être un siège humain (player1 ou player2)")
+			return
+		}
+		var ok bool
+		if account, ok = gm.accounts.FromRequest(r); !ok {
+			respondError(w, http.StatusUnauthorized, errNotLoggedIn.Error())
+			return
+		}
+		if req.BindSeat == "player1" {
+			req.Player1 = account.Username
+		} else {
+			req.Player2 = account.Username
+		}
+	}
+
 	// Validation: les pseudos ne doivent pas être vides
 	if req.Player1 == "" || req.Player2 == "" {
 		respondError(w, http.StatusBadRequest, "Les pseudos sont obligatoires")
 		return
 	}
 
-	// Création de la nouvelle partie
-	gm.game = NewGame(req.Rows, req.Cols, req.Player1, req.Player2)
+	// Validation: l'ordinateur occupe un siège existant avec un niveau connu
+	if req.Bot != "" {
+		if req.Bot != "player1" && req.Bot != "player2" {
+			respondError(w, http.StatusBadRequest, "L'ordinateur doit être player1 ou player2")
+			return
+		}
+		if req.BotLevel < BotLevelMin || req.BotLevel > BotLevelMax {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Le niveau de l'ordinateur doit être entre %d et %d", BotLevelMin, BotLevelMax))
+			return
+		}
+	}
 
-	// Envoi de l'état initial au client
-	respondJSON(w, http.StatusOK, gm.game.GetState())
+	// Validation: cadence des pendules cohérente
+	if err := validateTimeControl(req.TimeControl); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Création et enregistrement de la nouvelle partie
+	game := NewGameWithOptions(req.Rows, req.Cols, req.Player1, req.Player2, GameOptions{
+		WinLength:     req.WinLength,
+		Prefilled:     req.Prefilled,
+		Blockers:      req.Blockers,
+		BlockerLayout: req.BlockerLayout,
+		Gravity:       gravity,
+		Seed:          seed,
+		EvenStart:     req.EvenStart,
+		TimeControl:   req.TimeControl,
+	})
+	game.Difficulty = req.Difficulty
+	game.PresetRules = presetRules
+	game.Bot = req.Bot
+	game.BotLevel = req.BotLevel
+	if req.BindSeat != "" {
+		game.Users = map[string]string{req.BindSeat: account.ID}
+	}
+	game.assignSeats()
+	gm.addGame(game)
+
+	// Si l'ordinateur commence, il joue immédiatement
+	game.PlayBotTurn()
+	gm.commitGame(game)
+
+	// Envoi de l'état initial au client, avec les jetons de siège
+	// (seule cette réponse les contient)
+	state := game.GetState()
+	state["seats"] = game.SeatTokens
+	if token, ok := game.SeatTokens["player2"]; ok {
+		state["invite"] = "/game?join=" + game.ID + "&seat=" + token
+	}
+	respondJSON(w, http.StatusOK, state)
 }
 
 //#endregion
@@ -102,20 +440,27 @@ func (gm *GameManager) HandleNewGame(w http.ResponseWriter, r *http.Request) {
 
 // HandleDropPiece gère le placement d'un jeton dans une colonne
 //
-// Route: POST /api/game/drop
+// Route: POST /api/game/drop?id=<identifiant>
+// En-tête: X-Seat-Token: <jeton du siège du joueur actuel>
 // Body JSON attendu:
 //
 //	{
-//	  "col": 3
+//	  "col": 3,
+//	  "flipGravity": true  // optionnel: coup spécial (règle de gravité "special")
 //	}
 //
 // Paramètres:
 //   - w: ResponseWriter pour envoyer la réponse
 //   - r: Request contenant le numéro de colonne
 //
+// Si la partie se joue contre l'ordinateur, celui-ci répond immédiatement
+// et l'état renvoyé inclut déjà son coup.
+//
 // Réponse:
 //   - 200 OK: Nouvel état de la partie après le coup
-//   - 400 Bad Request: Coup invalide ou aucune partie en cours
+//   - 400 Bad Request: Coup invalide ou identifiant manquant
+//   - 403 Forbidden: Jeton de siège invalide ou ce n'est pas le tour de ce joueur
+//   - 404 Not Found: Partie introuvable
 //   - 405 Method Not Allowed: Méthode HTTP incorrecte
 func (gm *GameManager) HandleDropPiece(w http.ResponseWriter, r *http.Request) {
 	// Vérification de la méthode HTTP
@@ -124,15 +469,16 @@ func (gm *GameManager) HandleDropPiece(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Vérification qu'une partie est en cours
-	if gm.game == nil {
-		respondError(w, http.StatusBadRequest, "Aucune partie en cours")
+	// Récupération de la partie concernée
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
 		return
 	}
 
 	// Structure pour décoder le JSON de la requête
 	var req struct {
-		Col int `json:"col"` // Numéro de colonne où placer le jeton
+		Col         int  `json:"col"`         // Numéro de colonne où placer le jeton
+		FlipGravity bool `json:"flipGravity"` // Coup spécial inversant la gravité (règle "special")
 	}
 
 	// Décodage du JSON
@@ -141,16 +487,27 @@ func (gm *GameManager) HandleDropPiece(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Tentative de placement du jeton
-	err := gm.game.DropPiece(req.Col)
+	// Tentative de placement du jeton pour le détenteur du siège
+	err := game.PlayAs(r.Header.Get(seatTokenHeader), req.Col, req.FlipGravity)
+	if errors.Is(err, errInvalidSeat) || errors.Is(err, errNotYourTurn) {
+		respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if errors.Is(err, errTimeUp) {
+		gm.commitGame(game) // La défaite au temps vient d'être constatée
+	}
 	if err != nil {
-		// Erreur de jeu (colonne pleine, partie terminée, etc.)
+		// Erreur de jeu (colonne pleine, partie terminée, temps écoulé, etc.)
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	// Réponse de l'ordinateur s'il joue contre cet humain
+	game.PlayBotTurn()
+	gm.commitGame(game)
+
 	// Envoi du nouvel état au client
-	respondJSON(w, http.StatusOK, gm.game.GetState())
+	respondJSON(w, http.StatusOK, game.GetState())
 }
 
 //#endregion
@@ -159,7 +516,7 @@ func (gm *GameManager) HandleDropPiece(w http.ResponseWriter, r *http.Request) {
 
 // HandleGetState retourne l'état actuel du jeu
 //
-// Route: GET /api/game/state
+// Route: GET /api/game/state?id=<identifiant>
 //
 // Paramètres:
 //   - w: ResponseWriter pour envoyer la réponse
@@ -167,7 +524,8 @@ func (gm *GameManager) HandleDropPiece(w http.ResponseWriter, r *http.Request) {
 //
 // Réponse:
 //   - 200 OK: État actuel de la partie
-//   - 400 Bad Request: Aucune partie en cours
+//   - 400 Bad Request: Identifiant manquant
+//   - 404 Not Found: Partie introuvable
 //   - 405 Method Not Allowed: Méthode HTTP incorrecte
 func (gm *GameManager) HandleGetState(w http.ResponseWriter, r *http.Request) {
 	// Vérification de la méthode HTTP
@@ -176,19 +534,23 @@ func (gm *GameManager) HandleGetState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Vérification qu'une partie existe
-	if gm.game == nil {
-		respondError(w, http.StatusBadRequest, "Aucune partie en cours")
+	// Récupération de la partie concernée
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
 		return
 	}
 
 	// Envoi de l'état actuel
-	respondJSON(w, http.StatusOK, gm.game.GetState())
+	respondJSON(w, http.StatusOK, game.GetState())
 }
 
-// HandleReset réinitialise le jeu (supprime la partie en cours)
+// HandleReset réinitialise le jeu (supprime la partie désignée)
+//
+// Route: POST /api/game/reset?id=<identifiant>
+// En-tête: X-Seat-Token: <jeton de l'un des sièges>
 //
-// Route: POST /api/game/reset
+// Une partie classée en cours est d'abord perdue par abandon par le
+// joueur qui la supprime : son résultat est compté et archivé.
 //
 // Paramètres:
 //   - w: ResponseWriter pour envoyer la réponse
@@ -196,6 +558,9 @@ func (gm *GameManager) HandleGetState(w http.ResponseWriter, r *http.Request) {
 //
 // Réponse:
 //   - 200 OK: Message de confirmation
+//   - 400 Bad Request: Identifiant manquant
+//   - 403 Forbidden: Jeton de siège invalide
+//   - 404 Not Found: Partie introuvable
 //   - 405 Method Not Allowed: Méthode HTTP incorrecte
 func (gm *GameManager) HandleReset(w http.ResponseWriter, r *http.Request) {
 	// Vérification de la méthode HTTP
@@ -204,11 +569,209 @@ func (gm *GameManager) HandleReset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Suppression de la partie en cours
-	gm.game = nil
+	// Récupération de la partie concernée (réservée à ses joueurs)
+	game := gm.gameFromRequest(w, r)
+	if game == nil || !requireSeat(w, r, game) {
+		return
+	}
+
+	// Une partie classée en cours compte comme un abandon
+	if game.LeaveRanked(r.Header.Get(seatTokenHeader)) {
+		gm.commitGame(game)
+	}
+
+	// Suppression de la partie désignée
+	id := game.ID
+	if !gm.removeGame(id) {
+		respondError(w, http.StatusNotFound, "Partie introuvable")
+		return
+	}
+	if err := gm.store.Delete(id); err != nil {
+		log.Printf("Erreur de suppression de la partie %s: %v", id, err)
+	}
+	gm.hub.Publish(id, GameEvent{Type: "reset"})
+	gm.hub.Close(id)
 
 	// Confirmation de la réinitialisation
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Jeu réinitialisé"})
 }
 
 //#endregion
+
+//#region HANDLERS HTTP - RELECTURE
+
+// HandleReplay retourne la séquence complète d'une partie
+//
+// Route: GET /api/game/replay?id=<identifiant>
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request
+//
+// Réponse:
+//   - 200 OK: Disposition initiale et liste des coups (voir Replay)
+//   - 400 Bad Request: Identifiant manquant
+//   - 404 Not Found: Partie introuvable
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleReplay(w http.ResponseWriter, r *http.Request) {
+	// Vérification de la méthode HTTP
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	// Récupération de la partie concernée
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, game.GetReplay())
+}
+
+//#endregion
+
+//#region HANDLERS HTTP - ANNULATION ET RÉTABLISSEMENT
+
+// HandleUndo annule le dernier coup de la partie
+//
+// Route: POST /api/game/undo?id=<identifiant>
+// En-tête: X-Seat-Token: <jeton de l'un des sièges>
+//
+// Contre l'ordinateur, sa dernière réponse est annulée en même temps
+// pour rendre la main au joueur humain.
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request
+//
+// Réponse:
+//   - 200 OK: État de la partie après l'annulation
+//   - 400 Bad Request: Aucun coup à annuler ou identifiant manquant
+//   - 403 Forbidden: Jeton de siège invalide
+//   - 404 Not Found: Partie introuvable
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleUndo(w http.ResponseWriter, r *http.Request) {
+	// Vérification de la méthode HTTP
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	// Récupération de la partie concernée
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
+		return
+	}
+
+	if !requireSeat(w, r, game) {
+		return
+	}
+
+	// Annulation du coup
+	if err := game.Undo(); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	gm.commitGame(game)
+
+	// Envoi du nouvel état au client
+	respondJSON(w, http.StatusOK, game.GetState())
+}
+
+// HandleRedo rétablit le dernier coup annulé de la partie
+//
+// Route: POST /api/game/redo?id=<identifiant>
+// En-tête: X-Seat-Token: <jeton de l'un des sièges>
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request
+//
+// Réponse:
+//   - 200 OK: État de la partie après le rétablissement
+//   - 400 Bad Request: Aucun coup à rétablir ou identifiant manquant
+//   - 403 Forbidden: Jeton de siège invalide
+//   - 404 Not Found: Partie introuvable
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleRedo(w http.ResponseWriter, r *http.Request) {
+	// Vérification de la méthode HTTP
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	// Récupération de la partie concernée
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
+		return
+	}
+
+	if !requireSeat(w, r, game) {
+		return
+	}
+
+	// Rétablissement du coup
+	if err := game.Redo(); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	gm.commitGame(game)
+
+	// Envoi du nouvel état au client
+	respondJSON(w, http.StatusOK, game.GetState())
+}
+
+//#endregion
+
+//#region HANDLERS HTTP - TEMPS RÉEL
+
+// HandleWebSocket abonne un client aux événements d'une partie
+//
+// Route: GET /api/game/ws?id=<identifiant> (connexion WebSocket)
+//
+// Le client reçoit immédiatement l'état courant, puis un message JSON
+// (voir GameEvent) à chaque coup, inversion de gravité, fin de partie,
+// annulation ou suppression de la partie, quel que soit l'appareil
+// à l'origine de la modification.
+//
+// Paramètres:
+//   - w: ResponseWriter (détourné pour la connexion WebSocket)
+//   - r: Request de mise à niveau WebSocket
+//
+// Réponse:
+//   - 101 Switching Protocols: connexion établie
+//   - 400 Bad Request: Requête non WebSocket ou identifiant manquant
+//   - 403 Forbidden: Connexion ouverte depuis la page d'un autre site
+//   - 404 Not Found: Partie introuvable
+func (gm *GameManager) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// Récupération de la partie concernée
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
+		return
+	}
+
+	// Poignée de main WebSocket
+	conn, rw, err := wsUpgrade(w, r)
+	if errors.Is(err, errCrossOrigin) {
+		respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Abonnement puis envoi de l'état courant
+	client := &wsClient{conn: conn, rw: rw, send: make(chan []byte, 32)}
+	gm.hub.subscribe(game.ID, client)
+	go client.writeLoop()
+	gm.hub.sendTo(game.ID, client, GameEvent{Type: "state", State: game.GetState()})
+
+	// Lecture jusqu'à la déconnexion du client
+	client.readLoop(func() {
+		gm.hub.unsubscribe(game.ID, client)
+	})
+}
+
+//#endregion
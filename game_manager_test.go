@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//#region UTILITAIRES DE TEST
+
+// testDifficulties charge les préréglages de difficulté livrés avec le jeu
+func testDifficulties(t *testing.T) *DifficultyRegistry {
+	t.Helper()
+	reg, err := LoadDifficulties("config/difficulties.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+// newTestGame crée une partie via HandleNewGame et retourne son identifiant
+func newTestGame(t *testing.T, gm *GameManager, rows, cols int) string {
+	t.Helper()
+	body := fmt.Sprintf(`{"rows":%d,"cols":%d,"player1":"Alice","player2":"Bob"}`, rows, cols)
+	rec := httptest.NewRecorder()
+	gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("création de partie: %d %s", rec.Code, rec.Body.String())
+	}
+
+	var state struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+		t.Fatal(err)
+	}
+	return state.ID
+}
+
+// seatRequest construit une requête portant le jeton d'un siège de la partie
+func seatRequest(gm *GameManager, method, id, seat, path, body string) *http.Request {
+	req := httptest.NewRequest(method, path+"?id="+id, strings.NewReader(body))
+	req.Header.Set(seatTokenHeader, gm.getGame(id).SeatTokens[seat])
+	return req
+}
+
+//#endregion
+
+//#region TESTS DE CONCURRENCE
+
+// TestHandlersConcurrent sollicite tous les handlers en parallèle sur
+// plusieurs parties et vérifie que chaque partie reste cohérente.
+//
+// À lancer avec: go test -race
+func TestHandlersConcurrent(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	ids := []string{
+		newTestGame(t, gm, 10, 10),
+		newTestGame(t, gm, 10, 10),
+		newTestGame(t, gm, 10, 10),
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 24; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			id := ids[w%len(ids)]
+			for i := 0; i < 40; i++ {
+				// Les coups joués hors de son tour sont refusés (403)
+				body := fmt.Sprintf(`{"col":%d}`, (w+i)%10)
+				seat := []string{"player1", "player2"}[(w+i)%2]
+				gm.HandleDropPiece(httptest.NewRecorder(),
+					seatRequest(gm, "POST", id, seat, "/api/game/drop", body))
+				gm.HandleGetState(httptest.NewRecorder(),
+					httptest.NewRequest("GET", "/api/game/state?id="+id, nil))
+			}
+		}(w)
+	}
+
+	// Créations et suppressions concurrentes d'autres parties
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new",
+				strings.NewReader(`{"rows":6,"cols":7,"player1":"Alice","player2":"Bob"}`)))
+
+			var state struct {
+				ID string `json:"id"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+				t.Error(err)
+				return
+			}
+			gm.HandleReset(httptest.NewRecorder(),
+				seatRequest(gm, "POST", state.ID, "player1", "/api/game/reset", ""))
+		}()
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		g := gm.getGame(id)
+		g.mu.Lock()
+		p1, p2 := countTokens(g)
+		if p1+p2 != g.TurnCount || (p1-p2 != 0 && p1-p2 != 1) {
+			t.Errorf("partie %s incohérente: player1=%d player2=%d TurnCount=%d", id, p1, p2, g.TurnCount)
+		}
+		g.mu.Unlock()
+	}
+
+	// Seules les trois parties de départ doivent subsister
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	if len(gm.games) != len(ids) {
+		t.Fatalf("%d parties restantes, %d attendues", len(gm.games), len(ids))
+	}
+}
+
+// TestGamesAreIsolated vérifie qu'une nouvelle partie n'écrase pas les autres
+func TestGamesAreIsolated(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	first := newTestGame(t, gm, 10, 10)
+	second := newTestGame(t, gm, 10, 10)
+	if first == second {
+		t.Fatal("deux parties ont reçu le même identifiant")
+	}
+
+	rec := httptest.NewRecorder()
+	gm.HandleDropPiece(rec, seatRequest(gm, "POST", first, "player1", "/api/game/drop", `{"col":3}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("coup refusé: %d %s", rec.Code, rec.Body.String())
+	}
+
+	if gm.getGame(second).GetState()["turnCount"] != 0 {
+		t.Fatal("le coup a été joué sur la mauvaise partie")
+	}
+}
+
+// TestGamesPlayedSideBySide joue deux parties en alternance : chacune garde
+// son plateau, son tour et sa fin de partie, et supprimer l'une ne touche pas l'autre
+func TestGamesPlayedSideBySide(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	newGame := func() string {
+		rec := httptest.NewRecorder()
+		gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new", strings.NewReader(
+			`{"rows":6,"cols":7,"player1":"Alice","player2":"Bob","gravity":{"preset":"off"}}`)))
+		var state struct {
+			ID string `json:"id"`
+		}
+		json.NewDecoder(rec.Body).Decode(&state)
+		return state.ID
+	}
+	ids := []string{newGame(), newGame()}
+	if ids[0] == ids[1] {
+		t.Fatal("deux parties ont reçu le même identifiant")
+	}
+
+	// Première partie : player1 aligne la colonne 0 ; seconde : la colonne 6
+	cols := map[string][]int{ids[0]: {0, 1, 0, 1, 0, 1, 0}, ids[1]: {6, 5, 6, 5, 6}}
+	for turn := 0; turn < 7; turn++ {
+		for _, id := range ids {
+			if turn >= len(cols[id]) {
+				continue
+			}
+			seat := []string{"player1", "player2"}[turn%2]
+			rec := httptest.NewRecorder()
+			gm.HandleDropPiece(rec, seatRequest(gm, "POST", id, seat, "/api/game/drop", fmt.Sprintf(`{"col":%d}`, cols[id][turn])))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("partie %s, coup %d: %d %s", id, turn+1, rec.Code, rec.Body.String())
+			}
+		}
+	}
+
+	first, second := gm.getGame(ids[0]).GetState(), gm.getGame(ids[1]).GetState()
+	if first["gameOver"] != true || first["winner"] != "player1" || first["turnCount"] != 7 {
+		t.Fatalf("première partie: %v", first)
+	}
+	if second["gameOver"] != false || second["turnCount"] != 5 || second["currentPlayer"] != "player2" {
+		t.Fatalf("seconde partie: %v", second)
+	}
+	board := second["board"].([][]string)
+	if board[5][0] != "" || board[5][6] != "player1" || board[5][5] != "player2" {
+		t.Fatalf("plateau de la seconde partie: %v", board)
+	}
+
+	// Supprimer la partie terminée laisse l'autre jouable
+	gm.HandleReset(httptest.NewRecorder(), seatRequest(gm, "POST", ids[0], "player1", "/api/game/reset", ""))
+	if gm.getGame(ids[0]) != nil {
+		t.Fatal("partie supprimée toujours présente")
+	}
+	rec := httptest.NewRecorder()
+	gm.HandleDropPiece(rec, seatRequest(gm, "POST", ids[1], "player2", "/api/game/drop", `{"col":5}`))
+	if rec.Code != http.StatusOK || gm.getGame(ids[1]).GetState()["turnCount"] != 6 {
+		t.Fatalf("seconde partie après la suppression: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+//#endregion
+
+//#region TESTS DES SIÈGES
+
+// TestSeatTokensEnforceTurns vérifie que chaque joueur ne peut jouer
+// qu'avec son propre jeton et seulement à son tour.
+func TestSeatTokensEnforceTurns(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 10, 10)
+
+	drop := func(seat string, token string) int {
+		req := httptest.NewRequest("POST", "/api/game/drop?id="+id, strings.NewReader(`{"col":0}`))
+		if token == "" {
+			token = gm.getGame(id).SeatTokens[seat]
+		}
+		req.Header.Set(seatTokenHeader, token)
+		rec := httptest.NewRecorder()
+		gm.HandleDropPiece(rec, req)
+		return rec.Code
+	}
+
+	if code := drop("", "mauvais-jeton"); code != http.StatusForbidden {
+		t.Fatalf("jeton inconnu: %d, attendu 403", code)
+	}
+	if code := drop("player2", ""); code != http.StatusForbidden {
+		t.Fatalf("player2 au tour de player1: %d, attendu 403", code)
+	}
+	if code := drop("player1", ""); code != http.StatusOK {
+		t.Fatalf("player1 à son tour: %d, attendu 200", code)
+	}
+	if code := drop("player1", ""); code != http.StatusForbidden {
+		t.Fatalf("player1 deux fois de suite: %d, attendu 403", code)
+	}
+	if code := drop("player2", ""); code != http.StatusOK {
+		t.Fatalf("player2 à son tour: %d, attendu 200", code)
+	}
+}
+
+// TestNewGameReturnsInvite vérifie que la création renvoie les jetons et le lien d'invitation
+func TestNewGameReturnsInvite(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	rec := httptest.NewRecorder()
+	gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new",
+		strings.NewReader(`{"rows":6,"cols":7,"player1":"Alice","player2":"Bob"}`)))
+
+	var resp struct {
+		ID     string            `json:"id"`
+		Seats  map[string]string `json:"seats"`
+		Invite string            `json:"invite"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Seats["player1"] == "" || resp.Seats["player2"] == "" || resp.Seats["player1"] == resp.Seats["player2"] {
+		t.Fatalf("jetons de siège: %v", resp.Seats)
+	}
+	if want := "/game?join=" + resp.ID + "&seat=" + resp.Seats["player2"]; resp.Invite != want {
+		t.Fatalf("invitation %q, attendu %q", resp.Invite, want)
+	}
+
+	// Les jetons ne doivent jamais réapparaître dans l'état public
+	if _, ok := gm.getGame(resp.ID).GetState()["seats"]; ok {
+		t.Fatal("l'état public contient les jetons de siège")
+	}
+}
+
+// TestNewGameWinLength vérifie la validation du nombre de jetons à aligner
+func TestNewGameWinLength(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	for _, tc := range []struct {
+		body   string
+		status int
+		want   int
+	}{
+		{`{"rows":6,"cols":7,"player1":"Alice","player2":"Bob"}`, http.StatusOK, DefaultWinLength},
+		{`{"rows":6,"cols":7,"winLength":5,"player1":"Alice","player2":"Bob"}`, http.StatusOK, 5},
+		{`{"rows":6,"cols":7,"winLength":7,"player1":"Alice","player2":"Bob"}`, http.StatusOK, 7},
+		{`{"rows":6,"cols":7,"winLength":8,"player1":"Alice","player2":"Bob"}`, http.StatusBadRequest, 0},
+		{`{"rows":6,"cols":7,"winLength":2,"player1":"Alice","player2":"Bob"}`, http.StatusBadRequest, 0},
+	} {
+		rec := httptest.NewRecorder()
+		gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new", strings.NewReader(tc.body)))
+		if rec.Code != tc.status {
+			t.Fatalf("%s: statut %d, attendu %d", tc.body, rec.Code, tc.status)
+		}
+		if tc.status != http.StatusOK {
+			continue
+		}
+		var resp struct {
+			WinLength int `json:"winLength"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.WinLength != tc.want {
+			t.Fatalf("%s: winLength %d, attendu %d", tc.body, resp.WinLength, tc.want)
+		}
+	}
+}
+
+// TestNewGameSeed vérifie que la graine renvoyée recrée le même plateau de départ
+func TestNewGameSeed(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	newGame := func(body string) (seed int64, board [][]string) {
+		rec := httptest.NewRecorder()
+		gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new", strings.NewReader(body)))
+		var resp struct {
+			Seed  int64      `json:"seed"`
+			Board [][]string `json:"board"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Seed, resp.Board
+	}
+
+	seed, board := newGame(`{"difficulty":"hard","player1":"Alice","player2":"Bob"}`)
+	again, replayed := newGame(fmt.Sprintf(`{"difficulty":"hard","player1":"Alice","player2":"Bob","seed":%d}`, seed))
+	if again != seed {
+		t.Fatalf("graine %d, attendu %d", again, seed)
+	}
+	if !reflect.DeepEqual(board, replayed) {
+		t.Fatalf("plateaux différents pour la graine %d:\n%v\n%v", seed, board, replayed)
+	}
+}
+
+//#endregion
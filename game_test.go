@@ -0,0 +1,155 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+//#region TESTS DE CONCURRENCE
+
+// countTokens compte les jetons de chaque joueur sur le plateau
+func countTokens(g *Game) (p1, p2 int) {
+	return g.Board.Count("player1"), g.Board.Count("player2")
+}
+
+// TestDropPieceConcurrent joue des coups depuis de nombreuses goroutines
+// et vérifie que l'état final reste cohérent.
+//
+// À lancer avec: go test -race
+func TestDropPieceConcurrent(t *testing.T) {
+	// Dimensions personnalisées : aucun jeton pré-rempli
+	g := NewGame(10, 10, "Alice", "Bob")
+
+	var wg sync.WaitGroup
+	for w := 0; w < 32; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				// Les erreurs (colonne pleine, partie terminée) sont attendues
+				g.DropPiece((w + i) % g.Cols)
+				g.GetState()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Chaque coup accepté a posé exactement un jeton
+	p1, p2 := countTokens(g)
+	if p1+p2 != g.TurnCount {
+		t.Fatalf("jetons posés = %d, TurnCount = %d", p1+p2, g.TurnCount)
+	}
+
+	// Les joueurs ont strictement alterné (player1 commence)
+	if p1-p2 != 0 && p1-p2 != 1 {
+		t.Fatalf("alternance rompue: player1=%d, player2=%d", p1, p2)
+	}
+
+	// La gravité a été inversée une fois tous les 5 tours
+	if g.InverseGravity != ((g.TurnCount/5)%2 == 1) {
+		t.Fatalf("InverseGravity=%v incohérent avec TurnCount=%d", g.InverseGravity, g.TurnCount)
+	}
+
+	// La partie s'est forcément terminée
+	if !g.GameOver {
+		t.Fatalf("la partie devrait être terminée après %d tours", g.TurnCount)
+	}
+	if g.Winner != "draw" && g.Winner != g.CurrentPlayer {
+		t.Fatalf("Winner=%q incohérent avec CurrentPlayer=%q", g.Winner, g.CurrentPlayer)
+	}
+}
+
+// TestGetStateIsSnapshot vérifie que l'état retourné n'est pas modifié
+// par les coups joués ensuite.
+func TestGetStateIsSnapshot(t *testing.T) {
+	g := NewGame(10, 10, "Alice", "Bob")
+
+	state := g.GetState()
+	if err := g.DropPiece(0); err != nil {
+		t.Fatal(err)
+	}
+
+	board := state["board"].([][]string)
+	if board[9][0] != "" {
+		t.Fatalf("l'instantané a été modifié par DropPiece: %q", board[9][0])
+	}
+}
+
+//#endregion
+
+//#region TESTS DE LA LONGUEUR D'ALIGNEMENT
+
+// TestWinLength vérifie que la victoire dépend du nombre de jetons à aligner
+func TestWinLength(t *testing.T) {
+	// player1 aligne 3 jetons en bas des colonnes 0 à 2, player2 empile en colonne 5
+	moves := []int{0, 5, 1, 5, 2}
+
+	for _, tc := range []struct {
+		winLength int
+		gameOver  bool
+	}{
+		{3, true},
+		{4, false},
+		{5, false},
+	} {
+		g := NewGame(10, 10, "Alice", "Bob")
+		g.WinLength = tc.winLength
+		for _, col := range moves {
+			if err := g.DropPiece(col); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if g.GameOver != tc.gameOver {
+			t.Fatalf("alignement de %d: GameOver=%v, attendu %v", tc.winLength, g.GameOver, tc.gameOver)
+		}
+		if tc.gameOver && g.Winner != "player1" {
+			t.Fatalf("alignement de %d: Winner=%q, attendu player1", tc.winLength, g.Winner)
+		}
+	}
+}
+
+// TestDrawWhenNoAlignmentLeft vérifie que la partie est nulle dès qu'aucun
+// joueur ne peut plus aligner WinLength jetons, même si le plateau n'est pas plein
+func TestDrawWhenNoAlignmentLeft(t *testing.T) {
+	// Avec 10 jetons à aligner sur 4 lignes, seules les lignes comptent :
+	// une fois les colonnes 0 (player1) et 1 (player2) remplies, chaque ligne
+	// contient un jeton de chaque joueur.
+	g := NewGame(4, 10, "Alice", "Bob")
+	g.WinLength = 10
+	for i := 0; i < 8; i++ {
+		if g.GameOver {
+			t.Fatalf("partie terminée trop tôt, après %d coups", i)
+		}
+		if err := g.DropPiece(i % 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !g.GameOver || g.Winner != "draw" {
+		t.Fatalf("GameOver=%v Winner=%q, attendu une égalité", g.GameOver, g.Winner)
+	}
+}
+
+//#endregion
+
+//#region TESTS DU HASARD
+
+// TestSeedReproducesPrefill vérifie qu'une même graine recrée la même disposition initiale
+func TestSeedReproducesPrefill(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		a := NewGameWithOptions(7, 8, "Alice", "Bob", GameOptions{Prefilled: 7, Seed: seed})
+		b := NewGameWithOptions(7, 8, "Alice", "Bob", GameOptions{Prefilled: 7, Seed: seed})
+		if len(a.Prefilled) != 7 {
+			t.Fatalf("graine %d: %d jetons pré-remplis, attendu 7", seed, len(a.Prefilled))
+		}
+		for i := range a.Prefilled {
+			if a.Prefilled[i] != b.Prefilled[i] {
+				t.Fatalf("graine %d: jeton %d différent (%v, %v)", seed, i, a.Prefilled[i], b.Prefilled[i])
+			}
+		}
+	}
+}
+
+//#endregion
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+//#region RÈGLES DE GRAVITÉ
+
+// Modes d'inversion de la gravité
+const (
+	GravityOff      = "off"      // La gravité n'est jamais inversée
+	GravityPeriodic = "periodic" // Inversion tous les Period tours
+	GravityRandom   = "random"   // Inversion aléatoire (tirage reproductible à partir de Seed)
+	GravitySpecial  = "special"  // Inversion déclenchée par un coup spécial des joueurs
+)
+
+// DefaultGravityPreset est la règle historique : inversion tous les 5 tours
+const DefaultGravityPreset = "classic"
+
+// GravityRule décrit quand la gravité s'inverse au cours d'une partie
+//
+// La règle est choisie à la création de la partie, soit par le nom d'un
+// préréglage (Preset), soit en détaillant le mode et ses paramètres.
+type GravityRule struct {
+	Preset      string  `json:"preset,omitempty"`      // Préréglage d'origine ("" pour une règle personnalisée)
+	Mode        string  `json:"mode"`                  // GravityOff, GravityPeriodic, GravityRandom ou GravitySpecial
+	Period      int     `json:"period,omitempty"`      // periodic : nombre de tours entre deux inversions
+	Probability float64 `json:"probability,omitempty"` // random : probabilité d'inversion après chaque tour
+	Seed        int64   `json:"seed,omitempty"`        // random : graine du tirage
+	Charges     int     `json:"charges,omitempty"`     // special : coups spéciaux disponibles par joueur
+}
+
+// gravityPresets liste les règles disponibles par leur nom
+var gravityPresets = map[string]GravityRule{
+	"classic": {Mode: GravityPeriodic, Period: 5},
+	"off":     {Mode: GravityOff},
+	"chaos":   {Mode: GravityRandom, Probability: 0.2},
+	"special": {Mode: GravitySpecial, Charges: 1},
+}
+
+// ResolveGravityRule complète et valide la règle demandée par le client
+//
+// Une règle vide correspond au préréglage par défaut. Une règle aléatoire
+// sans graine prend celle de la partie, pour que la partie reste
+// reproductible (annulation, rechargement, relecture).
+//
+// Paramètres:
+//   - rule: règle reçue (préréglage ou mode détaillé)
+//   - seed: graine de la partie
+//
+// Retourne:
+//   - GravityRule: règle prête à être utilisée
+//   - error: si le préréglage est inconnu ou les paramètres invalides
+func ResolveGravityRule(rule GravityRule, seed int64) (GravityRule, error) {
+	if rule.Preset == "" && rule.Mode == "" {
+		rule.Preset = DefaultGravityPreset
+	}
+	if rule.Preset != "" {
+		preset, ok := gravityPresets[rule.Preset]
+		if !ok {
+			return GravityRule{}, fmt.Errorf("règle de gravité inconnue: %q", rule.Preset)
+		}
+		preset.Preset = rule.Preset
+		if preset.Mode == GravityRandom {
+			preset.Seed = rule.Seed // Graine imposée par le client (0 = celle de la partie)
+		}
+		rule = preset
+	}
+
+	switch rule.Mode {
+	case GravityOff:
+	case GravityPeriodic:
+		if rule.Period < 1 {
+			return GravityRule{}, errors.New("la période d'inversion doit être d'au moins 1 tour")
+		}
+	case GravityRandom:
+		if rule.Probability <= 0 || rule.Probability > 1 {
+			return GravityRule{}, errors.New("la probabilité d'inversion doit être comprise entre 0 (exclu) et 1")
+		}
+		if rule.Seed == 0 {
+			rule.Seed = seed
+		}
+	case GravitySpecial:
+		if rule.Charges < 1 {
+			return GravityRule{}, errors.New("chaque joueur doit disposer d'au moins un coup spécial")
+		}
+	default:
+		return GravityRule{}, fmt.Errorf("mode de gravité inconnu: %q", rule.Mode)
+	}
+	return rule, nil
+}
+
+// sameAs indique si deux règles résolues inversent la gravité de la même façon
+//
+// Le nom du préréglage d'origine n'est pas comparé : {"preset": "classic"}
+// et {"mode": "periodic", "period": 5} sont la même règle.
+func (r GravityRule) sameAs(o GravityRule) bool {
+	r.Preset, o.Preset = "", ""
+	return r == o
+}
+
+// flipsAfter indique si la gravité s'inverse automatiquement à la fin d'un tour
+//
+// Le tirage du mode aléatoire ne dépend que de la graine et du numéro du
+// tour : rejouer, annuler ou recharger la partie donne les mêmes inversions.
+//
+// Paramètres:
+//   - turn: numéro du tour qui vient d'être joué (TurnCount après le coup)
+//
+// Retourne:
+//   - bool: true si la gravité doit être inversée
+func (r GravityRule) flipsAfter(turn int) bool {
+	switch r.Mode {
+	case GravityPeriodic:
+		return turn%r.Period == 0
+	case GravityRandom:
+		return turnRoll(r.Seed, turn) < r.Probability
+	}
+	return false
+}
+
+// turnsUntilFlip compte les tours restants avant la prochaine inversion automatique
+//
+// Paramètres:
+//   - turn: nombre de tours déjà joués
+//   - limit: nombre maximal de tours à examiner (cases encore libres)
+//
+// Retourne:
+//   - int: nombre de tours (1 = le prochain coup inverse la gravité), -1 si aucune
+//     inversion automatique n'aura lieu dans la limite
+func (r GravityRule) turnsUntilFlip(turn, limit int) int {
+	if r.Mode == GravityPeriodic {
+		return r.Period - turn%r.Period
+	}
+	for k := 1; k <= limit; k++ {
+		if r.flipsAfter(turn + k) {
+			return k
+		}
+	}
+	return -1
+}
+
+// turnRoll retourne un nombre pseudo-aléatoire de [0, 1) propre à un tour
+//
+// Mélange splitmix64 de la graine et du numéro du tour : sans état, il
+// peut être recalculé à tout moment, y compris pendant la recherche de
+// l'ordinateur.
+func turnRoll(seed int64, turn int) float64 {
+	z := uint64(seed) + uint64(turn)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	z ^= z >> 31
+	return float64(z>>11) / (1 << 53)
+}
+
+// gravityFlipIn retourne le nombre de tours avant la prochaine inversion automatique
+//
+// L'appelant doit détenir g.mu.
+//
+// Retourne:
+//   - *int: nombre de tours (1 = après le prochain coup), nil si aucune
+//     inversion automatique n'est prévue (règle off, special, ou partie terminée)
+func (g *Game) gravityFlipIn() *int {
+	if g.GameOver {
+		return nil
+	}
+	empty := g.Rows*g.Cols - g.Board.Count("player1") - g.Board.Count("player2") - g.Board.Count(BlockedCell)
+	if n := g.Gravity.turnsUntilFlip(g.TurnCount, empty); n > 0 {
+		return &n
+	}
+	return nil
+}
+
+//#endregion
+
+//#region COUPS SPÉCIAUX
+
+// specialFlipsLeft retourne le nombre de coups spéciaux encore disponibles pour un joueur
+//
+// Les coups spéciaux déjà joués sont comptés dans l'historique : annuler
+// un coup spécial le rend donc à son auteur.
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) specialFlipsLeft(player string) int {
+	if g.Gravity.Mode != GravitySpecial {
+		return 0
+	}
+	left := g.Gravity.Charges
+	for _, m := range g.history {
+		if m.FlipGravity && m.Player == player {
+			left--
+		}
+	}
+	return left
+}
+
+//#endregion
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"testing"
+)
+
+//#region TESTS DES RÈGLES DE GRAVITÉ
+
+// playColumns joue une suite de coups sans inverser la gravité par coup spécial
+func playColumns(t *testing.T, g *Game, cols ...int) {
+	t.Helper()
+	for _, col := range cols {
+		if err := g.DropPiece(col); err != nil {
+			t.Fatalf("coup en colonne %d: %v", col, err)
+		}
+	}
+}
+
+// TestResolveGravityRule vérifie les préréglages et la validation des paramètres
+func TestResolveGravityRule(t *testing.T) {
+	rule, err := ResolveGravityRule(GravityRule{}, 7)
+	if err != nil || rule.Mode != GravityPeriodic || rule.Period != 5 || rule.Preset != DefaultGravityPreset {
+		t.Fatalf("règle par défaut %+v (%v), attendu le préréglage classique", rule, err)
+	}
+
+	rule, err = ResolveGravityRule(GravityRule{Preset: "chaos"}, 7)
+	if err != nil || rule.Seed != 7 {
+		t.Fatalf("le mode aléatoire doit recevoir la graine de la partie: %+v (%v)", rule, err)
+	}
+
+	for _, bad := range []GravityRule{
+		{Preset: "inconnu"},
+		{Mode: "inconnu"},
+		{Mode: GravityPeriodic},
+		{Mode: GravityRandom, Probability: 1.5},
+		{Mode: GravitySpecial},
+	} {
+		if _, err := ResolveGravityRule(bad, 7); err == nil {
+			t.Fatalf("règle %+v acceptée", bad)
+		}
+	}
+}
+
+// TestGravityOff vérifie que la gravité n'est jamais inversée sans règle
+func TestGravityOff(t *testing.T) {
+	g := NewGame(10, 10, "Alice", "Bob")
+	g.Gravity = gravityPresets["off"]
+	playColumns(t, g, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1)
+	if g.InverseGravity {
+		t.Fatal("gravité inversée alors que la règle est désactivée")
+	}
+	if flipIn := g.GetState()["gravityFlipIn"].(*int); flipIn != nil {
+		t.Fatalf("gravityFlipIn = %d, attendu nil", *flipIn)
+	}
+}
+
+// TestPeriodicGravityCountdown vérifie le décompte envoyé au client
+func TestPeriodicGravityCountdown(t *testing.T) {
+	g := NewGame(10, 10, "Alice", "Bob")
+	g.Gravity = GravityRule{Mode: GravityPeriodic, Period: 3}
+	for turn := 0; turn < 7; turn++ {
+		flipIn := *g.GetState()["gravityFlipIn"].(*int)
+		wasInverse := g.InverseGravity
+		playColumns(t, g, turn)
+		if flipped := g.InverseGravity != wasInverse; flipped != (flipIn == 1) {
+			t.Fatalf("tour %d: gravityFlipIn=%d mais inversion=%v", turn+1, flipIn, flipped)
+		}
+	}
+}
+
+// TestRandomGravityIsReproducible vérifie que la graine détermine les inversions
+// et que le décompte annoncé correspond aux inversions réelles
+func TestRandomGravityIsReproducible(t *testing.T) {
+	rule := GravityRule{Mode: GravityRandom, Probability: 0.3, Seed: 42}
+	a, b := NewGame(10, 10, "Alice", "Bob"), NewGame(10, 10, "Alice", "Bob")
+	a.Gravity, b.Gravity = rule, rule
+	a.WinLength, b.WinLength = 10, 10 // Aucune victoire possible : la partie dure 40 tours
+
+	flips := 0
+	for turn := 0; turn < 40; turn++ {
+		flipIn := a.GetState()["gravityFlipIn"].(*int)
+		wasInverse := a.InverseGravity
+		playColumns(t, a, turn%10)
+		playColumns(t, b, turn%10)
+		if a.InverseGravity != b.InverseGravity {
+			t.Fatalf("tour %d: inversions différentes avec la même graine", turn+1)
+		}
+		flipped := a.InverseGravity != wasInverse
+		if flipped {
+			flips++
+		}
+		if flipped != (flipIn != nil && *flipIn == 1) {
+			t.Fatalf("tour %d: décompte %v incohérent avec l'inversion %v", turn+1, flipIn, flipped)
+		}
+	}
+	if flips == 0 || flips == 40 {
+		t.Fatalf("%d inversions sur 40 tours avec une probabilité de 0.3", flips)
+	}
+}
+
+// TestSpecialFlip vérifie le coup spécial : il inverse la gravité, consomme
+// la charge du joueur et la lui rend s'il est annulé
+func TestSpecialFlip(t *testing.T) {
+	g := NewGame(10, 10, "Alice", "Bob")
+	g.Gravity = gravityPresets["special"]
+	g.assignSeats()
+	p1 := g.SeatTokens["player1"]
+
+	if err := g.PlayAs(p1, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if !g.InverseGravity {
+		t.Fatal("le coup spécial n'a pas inversé la gravité")
+	}
+	playColumns(t, g, 1)
+	if err := g.PlayAs(p1, 2, true); err == nil {
+		t.Fatal("second coup spécial accepté avec une seule charge")
+	}
+
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if g.InverseGravity || g.GetState()["specialFlips"].(map[string]int)["player1"] != 1 {
+		t.Fatal("l'annulation du coup spécial n'a pas rendu la charge ni la gravité")
+	}
+}
+
+//#endregion
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+//#region PARAMÈTRES DES INDICES
+
+const (
+	// hintTime est la durée d'analyse accordée à un indice
+	hintTime = 500 * time.Millisecond
+
+	// hintDepth est la profondeur d'analyse d'un indice (en demi-coups) :
+	// assez pour voir les doubles menaces, assez peu pour répondre vite
+	hintDepth = 8
+)
+
+// Raisons d'un indice
+const (
+	HintWin          = "win"           // Le coup gagne immédiatement
+	HintDoubleThreat = "double-threat" // Le coup crée deux menaces : l'adversaire ne peut pas tout bloquer
+	HintBlock        = "block"         // Le coup empêche l'adversaire de gagner au tour suivant
+	HintBest         = "best"          // Meilleur coup selon l'analyse, sans menace immédiate
+)
+
+// hintMessages associe à chaque raison le message affiché au joueur
+var hintMessages = map[string]string{
+	HintWin:          "Ce coup gagne la partie !",
+	HintDoubleThreat: "Ce coup crée une double menace : votre adversaire ne pourra pas tout bloquer.",
+	HintBlock:        "Ce coup empêche votre adversaire de gagner au prochain tour.",
+	HintBest:         "Meilleur coup selon l'analyse.",
+}
+
+// Hint est un coup conseillé au joueur dont c'est le tour
+type Hint struct {
+	Col     int    `json:"col"`     // Colonne conseillée
+	Reason  string `json:"reason"`  // HintWin, HintDoubleThreat, HintBlock ou HintBest
+	Message string `json:"message"` // Explication courte pour le joueur
+}
+
+//#endregion
+
+//#region CALCUL DE L'INDICE
+
+// HintAs conseille un coup au joueur détenteur d'un jeton de siège
+//
+// L'analyse porte sur une copie de la partie (plateau et gravité
+// actuels) : la partie reste jouable pendant le calcul.
+//
+// Paramètres:
+//   - token: jeton de siège présenté par le client
+//
+// Retourne:
+//   - Hint: colonne conseillée et raison
+//   - error: errInvalidSeat, errNotYourTurn, ou une erreur si la partie est
+//     terminée ou classée (aucune aide pendant une partie classée)
+func (g *Game) HintAs(token string) (Hint, error) {
+	g.mu.Lock()
+	seat, over, ranked, sim := g.seatOf(token), g.GameOver, g.ranked(), g.clone()
+	g.mu.Unlock()
+
+	switch {
+	case seat == "":
+		return Hint{}, errInvalidSeat
+	case over:
+		return Hint{}, errors.New("la partie est terminée")
+	case ranked:
+		return Hint{}, errors.New("pas d'indice dans une partie classée")
+	case seat != sim.CurrentPlayer:
+		return Hint{}, errNotYourTurn
+	}
+	return sim.hint(), nil
+}
+
+// hint choisit le coup conseillé et sa raison à partir de l'analyse du solveur
+//
+// Retourne:
+//   - Hint: colonne conseillée (la partie ne doit pas être terminée)
+func (g *Game) hint() Hint {
+	analysis := g.Analyze(hintTime, hintDepth)
+
+	// Un autre coup perdrait dès la réponse adverse : le coup conseillé pare la menace
+	reason := HintBest
+	for _, move := range analysis.Moves {
+		if move.Result == ResultLoss && move.Plies == 2 {
+			reason = HintBlock
+		}
+	}
+
+	for _, move := range analysis.Moves {
+		if move.Col != analysis.Best {
+			continue
+		}
+		switch {
+		case move.Result == ResultWin && move.Plies == 1:
+			reason = HintWin
+		case move.Result == ResultWin && move.Plies == 3:
+			reason = HintDoubleThreat
+		case move.Result == ResultLoss && move.Plies == 2:
+			reason = HintBest // Aucune parade possible
+		}
+	}
+	return Hint{Col: analysis.Best, Reason: reason, Message: hintMessages[reason]}
+}
+
+//#endregion
+
+//#region HANDLER HTTP
+
+// HandleHint conseille un coup au joueur dont c'est le tour
+//
+// Route: GET /api/game/hint?id=<identifiant>
+// En-tête: X-Seat-Token: <jeton du joueur dont c'est le tour>
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request
+//
+// Réponse:
+//   - 200 OK: {"col": 3, "reason": "block", "message": "..."}
+//   - 400 Bad Request: Partie terminée ou classée, ou identifiant manquant
+//   - 403 Forbidden: Jeton de siège invalide ou ce n'est pas le tour du joueur
+//   - 404 Not Found: Partie introuvable
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleHint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
+		return
+	}
+
+	hint, err := game.HintAs(r.Header.Get(seatTokenHeader))
+	if errors.Is(err, errInvalidSeat) || errors.Is(err, errNotYourTurn) {
+		respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, hint)
+}
+
+//#endregion
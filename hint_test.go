@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//#region TESTS DES INDICES
+
+// hintPosition prépare une partie sans gravité inversée à partir de jetons posés
+func hintPosition(current string, tokens map[[2]int]string) *Game {
+	g := newEmptyGame(6, 7)
+	g.Gravity, _ = ResolveGravityRule(GravityRule{Preset: "off"}, 0)
+	for cell, player := range tokens {
+		g.Board.Set(cell[0], cell[1], player)
+	}
+	g.CurrentPlayer = current
+	g.TurnCount = len(tokens)
+	return g
+}
+
+// TestHintReasons vérifie la colonne et la raison conseillées dans les
+// situations typiques
+func TestHintReasons(t *testing.T) {
+	tests := []struct {
+		name   string
+		game   *Game
+		cols   []int
+		reason string
+	}{
+		{
+			name: "victoire immédiate",
+			game: hintPosition("player1", map[[2]int]string{
+				{5, 0}: "player1", {5, 1}: "player1", {5, 2}: "player1",
+				{4, 0}: "player2", {4, 1}: "player2", {4, 2}: "player2",
+			}),
+			cols:   []int{3},
+			reason: HintWin,
+		},
+		{
+			name: "blocage",
+			game: hintPosition("player2", map[[2]int]string{
+				{5, 0}: "player1", {5, 1}: "player1", {5, 2}: "player1",
+				{5, 6}: "player2", {4, 6}: "player2",
+			}),
+			cols:   []int{3},
+			reason: HintBlock,
+		},
+		{
+			name: "double menace",
+			game: hintPosition("player1", map[[2]int]string{
+				{5, 2}: "player1", {5, 3}: "player1",
+				{4, 2}: "player2", {4, 3}: "player2",
+			}),
+			cols:   []int{1, 4},
+			reason: HintDoubleThreat,
+		},
+	}
+
+	for _, tt := range tests {
+		hint := tt.game.hint()
+		if hint.Reason != tt.reason || hint.Message == "" {
+			t.Fatalf("%s: raison %q, attendu %q", tt.name, hint.Reason, tt.reason)
+		}
+		found := false
+		for _, col := range tt.cols {
+			found = found || hint.Col == col
+		}
+		if !found {
+			t.Fatalf("%s: colonne %d, attendu l'une de %v", tt.name, hint.Col, tt.cols)
+		}
+	}
+}
+
+// TestHandleHint vérifie que seul le joueur dont c'est le tour reçoit un indice
+func TestHandleHint(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 7)
+
+	rec := httptest.NewRecorder()
+	gm.HandleHint(rec, seatRequest(gm, "GET", id, "player1", "/api/game/hint", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("indice: %d %s", rec.Code, rec.Body.String())
+	}
+	var hint Hint
+	if err := json.NewDecoder(rec.Body).Decode(&hint); err != nil {
+		t.Fatal(err)
+	}
+	if hint.Col < 0 || hint.Col >= 7 || hint.Reason == "" {
+		t.Fatalf("indice inattendu: %+v", hint)
+	}
+
+	for _, seat := range []string{"player2", "inconnu"} {
+		rec := httptest.NewRecorder()
+		gm.HandleHint(rec, seatRequest(gm, "GET", id, seat, "/api/game/hint", ""))
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("indice accordé hors de son tour: %d", rec.Code)
+		}
+	}
+
+	// Aucune aide pendant une partie classée
+	id, _, _ = newRatedGame(t, gm, "easy")
+	rec = httptest.NewRecorder()
+	gm.HandleHint(rec, seatRequest(gm, "GET", id, "player1", "/api/game/hint", ""))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("indice dans une partie classée: %d", rec.Code)
+	}
+}
+
+//#endregion
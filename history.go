@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+//#region ÉTAT D'UN TOUR
+
+// turnState regroupe tout ce qu'un coup modifie en dehors du plateau
+//
+// Capturé avant et après chaque coup, il permet d'annuler ou de rétablir
+// le coup exactement, y compris lorsqu'il a déclenché une inversion
+// de gravité ou terminé la partie.
+type turnState struct {
+	CurrentPlayer  string `json:"currentPlayer"`
+	TurnCount      int    `json:"turnCount"`
+	InverseGravity bool   `json:"inverseGravity"`
+	GameOver       bool   `json:"gameOver"`
+	Winner         string `json:"winner"`
+	Reason         string `json:"reason"`
+	LastMove       *Move  `json:"lastMove"`
+}
+
+// MoveRecord est un coup inscrit dans l'historique de la partie
+type MoveRecord struct {
+	Turn           int       `json:"turn"`           // Numéro du tour (1 = premier coup joué)
+	Row            int       `json:"row"`            // Ligne où le jeton s'est posé
+	Col            int       `json:"col"`            // Colonne jouée
+	Player         string    `json:"player"`         // Joueur ayant joué ("player1" ou "player2")
+	InverseGravity bool      `json:"inverseGravity"` // true si la gravité était inversée au moment du coup
+	FlipGravity    bool      `json:"flipGravity"`    // true si le coup était un coup spécial inversant la gravité
+	before         turnState // État avant le coup (restauré par l'annulation)
+	after          turnState // État après le coup (restauré par le rétablissement)
+}
+
+// Token est un jeton posé sur le plateau hors du déroulement de la partie
+// (obstacles et jetons pré-remplis au démarrage)
+type Token struct {
+	Row    int    `json:"row"`    // Ligne du jeton
+	Col    int    `json:"col"`    // Colonne du jeton
+	Player string `json:"player"` // Propriétaire du jeton ("player1", "player2" ou BlockedCell)
+}
+
+// saveTurnState capture l'état courant de la partie (hors plateau)
+func (g *Game) saveTurnState() turnState {
+	return turnState{
+		CurrentPlayer:  g.CurrentPlayer,
+		TurnCount:      g.TurnCount,
+		InverseGravity: g.InverseGravity,
+		GameOver:       g.GameOver,
+		Winner:         g.Winner,
+		Reason:         g.Reason,
+		LastMove:       g.LastMove,
+	}
+}
+
+// loadTurnState restaure un état capturé par saveTurnState
+func (g *Game) loadTurnState(s turnState) {
+	g.CurrentPlayer = s.CurrentPlayer
+	g.TurnCount = s.TurnCount
+	g.InverseGravity = s.InverseGravity
+	g.GameOver = s.GameOver
+	g.Winner = s.Winner
+	g.Reason = s.Reason
+	g.LastMove = s.LastMove
+}
+
+//#endregion
+
+//#region ANNULER ET RÉTABLIR
+
+// Undo annule le dernier coup joué
+//
+// Contre l'ordinateur, son coup et celui de l'humain qui le précède sont
+// annulés ensemble pour rendre la main à l'humain. En partie chronométrée,
+// le temps déjà consommé n'est pas rendu : seul le tour repart de zéro.
+// Une partie terminée ainsi rouverte quitte l'archive à son prochain
+// enregistrement (voir archiveGame).
+//
+// Retourne:
+//   - error: nil si au moins un coup a été annulé, une erreur sinon
+func (g *Game) Undo() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.canUndo() {
+		return errors.New("aucun coup à annuler")
+	}
+	g.undoMove()
+	for g.Bot != "" && g.CurrentPlayer == g.Bot && g.undoMove() {
+	}
+	g.DrawOffer = ""
+	g.restartClock(time.Now())
+	return nil
+}
+
+// Redo rétablit le dernier coup annulé
+//
+// Contre l'ordinateur, sa réponse est rétablie en même temps.
+//
+// Retourne:
+//   - error: nil si au moins un coup a été rétabli, une erreur sinon
+func (g *Game) Redo() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.canRedo() || !g.redoMove() {
+		return errors.New("aucun coup à rétablir")
+	}
+	for g.Bot != "" && g.CurrentPlayer == g.Bot && g.redoMove() {
+	}
+	g.DrawOffer = ""
+	g.restartClock(time.Now())
+	return nil
+}
+
+// canUndo indique si un coup peut être annulé
+//
+// Contre l'ordinateur, seul un historique contenant un coup humain compte :
+// annuler le premier coup de l'ordinateur n'aurait aucun sens puisqu'il
+// le rejouerait aussitôt. Une fin de partie décidée hors du plateau
+// (abandon, nulle acceptée, défaite au temps) est définitive, et une
+// partie classée ne revient jamais sur un coup.
+func (g *Game) canUndo() bool {
+	if g.conceded() || g.ranked() {
+		return false
+	}
+	for _, m := range g.history {
+		if m.Player != g.Bot {
+			return true
+		}
+	}
+	return false
+}
+
+// canRedo indique si un coup annulé peut être rétabli
+//
+// Les mêmes fins de partie définitives que pour canUndo l'interdisent.
+func (g *Game) canRedo() bool {
+	return !g.conceded() && !g.ranked() && len(g.redoStack) > 0
+}
+
+// undoMove retire le dernier coup de l'historique et le place dans la pile de rétablissement
+//
+// L'appelant doit détenir g.mu.
+//
+// Retourne:
+//   - bool: false si l'historique est vide
+func (g *Game) undoMove() bool {
+	if len(g.history) == 0 {
+		return false
+	}
+	m := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+
+	g.Board.Clear(m.Row, m.Col)
+	g.loadTurnState(m.before)
+	g.redoStack = append(g.redoStack, m)
+	return true
+}
+
+// redoMove rejoue le dernier coup annulé
+//
+// L'état enregistré après le coup est restauré tel quel (plutôt que de
+// rejouer la colonne) pour retrouver exactement la même partie.
+//
+// L'appelant doit détenir g.mu.
+//
+// Retourne:
+//   - bool: false si aucun coup n'a été annulé
+func (g *Game) redoMove() bool {
+	if len(g.redoStack) == 0 {
+		return false
+	}
+	m := g.redoStack[len(g.redoStack)-1]
+	g.redoStack = g.redoStack[:len(g.redoStack)-1]
+
+	g.Board.Set(m.Row, m.Col, m.Player)
+	g.loadTurnState(m.after)
+	g.history = append(g.history, m)
+	return true
+}
+
+//#endregion
+
+//#region DIFFUSION DES COUPS
+
+// unpublishedMoves retourne les coups joués depuis le dernier appel
+//
+// Après une annulation, seuls les coups encore présents dans
+// l'historique sont pris en compte.
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) unpublishedMoves() []MoveRecord {
+	if g.published > len(g.history) {
+		g.published = len(g.history)
+	}
+	moves := append([]MoveRecord{}, g.history[g.published:]...)
+	g.published = len(g.history)
+	return moves
+}
+
+// unpublishedGameOver indique si la fin de partie n'a pas encore été diffusée
+//
+// Une partie terminée n'est annoncée qu'une fois, même si elle est
+// enregistrée à nouveau ; une annulation qui la rouvre permet de
+// l'annoncer de nouveau à la prochaine fin.
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) unpublishedGameOver() bool {
+	ended := g.GameOver && !g.publishedOver
+	g.publishedOver = g.GameOver
+	return ended
+}
+
+//#endregion
+
+//#region RELECTURE DE PARTIE
+
+// Replay contient tout ce qu'il faut pour rejouer une partie coup par coup
+//
+// En partant d'un plateau vide, le client pose les jetons de Prefilled
+// puis applique Moves dans l'ordre.
+type Replay struct {
+	ID           string       `json:"id"`           // Identifiant de la partie
+	Rows         int          `json:"rows"`         // Nombre de lignes du plateau
+	Cols         int          `json:"cols"`         // Nombre de colonnes du plateau
+	WinLength    int          `json:"winLength"`    // Nombre de jetons à aligner pour gagner
+	Player1      string       `json:"player1"`      // Pseudo du joueur 1
+	Player2      string       `json:"player2"`      // Pseudo du joueur 2
+	Prefilled    []Token      `json:"prefilled"`    // Obstacles et jetons pré-remplis au démarrage
+	Moves        []MoveRecord `json:"moves"`        // Coups joués, dans l'ordre
+	GameOver     bool         `json:"gameOver"`     // true si la partie est terminée
+	Winner       string       `json:"winner"`       // Gagnant ("player1", "player2", "draw", ou "")
+	WinningCells []Move       `json:"winningCells"` // Cases de l'alignement gagnant, après le dernier coup
+}
+
+// GetReplay retourne la séquence complète de la partie
+//
+// Les coups annulés (en attente de rétablissement) n'en font pas partie.
+//
+// Retourne:
+//   - Replay: disposition initiale et coups joués, copiés
+func (g *Game) GetReplay() Replay {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.replay()
+}
+
+// replay contient la logique de GetReplay, sans verrouillage
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) replay() Replay {
+	return Replay{
+		ID:           g.ID,
+		Rows:         g.Rows,
+		Cols:         g.Cols,
+		WinLength:    g.WinLength,
+		Player1:      g.Player1,
+		Player2:      g.Player2,
+		Prefilled:    append([]Token{}, g.Prefilled...),
+		Moves:        append([]MoveRecord{}, g.history...),
+		GameOver:     g.GameOver,
+		Winner:       g.Winner,
+		WinningCells: g.winningCells(),
+	}
+}
+
+//#endregion
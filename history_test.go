@@ -0,0 +1,162 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+//#region TESTS D'ANNULATION
+
+// TestUndoAcrossGravityFlip annule puis rétablit les coups autour d'une
+// inversion de gravité et vérifie que chaque état est restauré à l'identique.
+func TestUndoAcrossGravityFlip(t *testing.T) {
+	g := NewGame(10, 10, "Alice", "Bob")
+
+	// Chaque état successif, de l'état initial à celui après 7 coups
+	states := []map[string]interface{}{g.GetState()}
+	for _, col := range []int{0, 1, 2, 3, 4, 5, 6} {
+		if err := g.DropPiece(col); err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, g.GetState())
+	}
+	if !g.InverseGravity {
+		t.Fatal("la gravité devrait être inversée après 5 tours")
+	}
+
+	// Annulation jusqu'à l'état initial
+	for i := len(states) - 2; i >= 0; i-- {
+		if err := g.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		assertSameState(t, states[i], g.GetState())
+	}
+	if g.Undo() == nil {
+		t.Fatal("l'annulation devrait échouer sans coup joué")
+	}
+
+	// Rétablissement jusqu'à l'état final
+	for i := 1; i < len(states); i++ {
+		if err := g.Redo(); err != nil {
+			t.Fatal(err)
+		}
+		assertSameState(t, states[i], g.GetState())
+	}
+	if g.Redo() == nil {
+		t.Fatal("le rétablissement devrait échouer sans coup annulé")
+	}
+}
+
+// TestUndoWinningMove vérifie que l'annulation d'un coup gagnant relance la partie
+func TestUndoWinningMove(t *testing.T) {
+	g := NewGame(10, 10, "Alice", "Bob")
+	// player1 complète la ligne du bas au 11e tour, après deux inversions de gravité
+	for _, col := range []int{0, 9, 1, 9, 2, 9, 5, 9, 5, 8, 3} {
+		if err := g.DropPiece(col); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.Winner != "player1" {
+		t.Fatalf("player1 devrait avoir gagné, Winner=%q", g.Winner)
+	}
+	if cells := g.GetState()["winningCells"].([]Move); len(cells) != 4 {
+		t.Fatalf("%d cases gagnantes, attendu 4", len(cells))
+	}
+
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if g.GameOver || g.Winner != "" || g.CurrentPlayer != "player1" {
+		t.Fatalf("état après annulation: GameOver=%v Winner=%q CurrentPlayer=%q", g.GameOver, g.Winner, g.CurrentPlayer)
+	}
+	if cells := g.GetState()["winningCells"].([]Move); len(cells) != 0 {
+		t.Fatalf("cases gagnantes après annulation: %v", cells)
+	}
+}
+
+// TestNewMoveClearsRedo vérifie qu'un nouveau coup empêche de rétablir les coups annulés
+func TestNewMoveClearsRedo(t *testing.T) {
+	g := NewGame(10, 10, "Alice", "Bob")
+	g.DropPiece(0)
+	g.Undo()
+	g.DropPiece(1)
+	if g.Redo() == nil {
+		t.Fatal("le rétablissement devrait être impossible après un nouveau coup")
+	}
+}
+
+// TestUndoAgainstBot vérifie que l'annulation rend la main à l'humain
+func TestUndoAgainstBot(t *testing.T) {
+	g := NewGame(10, 10, "Alice", "Ordinateur")
+	g.Bot, g.BotLevel = "player2", 1
+	g.DropPiece(3)
+	g.PlayBotTurn()
+
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if g.TurnCount != 0 || g.CurrentPlayer != "player1" {
+		t.Fatalf("TurnCount=%d CurrentPlayer=%q après annulation", g.TurnCount, g.CurrentPlayer)
+	}
+
+	if err := g.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if g.TurnCount != 2 || g.CurrentPlayer != "player1" {
+		t.Fatalf("TurnCount=%d CurrentPlayer=%q après rétablissement", g.TurnCount, g.CurrentPlayer)
+	}
+}
+
+// assertSameState compare deux états en ignorant les indicateurs d'historique
+func assertSameState(t *testing.T, want, got map[string]interface{}) {
+	t.Helper()
+	for key, value := range want {
+		if key == "canUndo" || key == "canRedo" {
+			continue
+		}
+		if !reflect.DeepEqual(value, got[key]) {
+			t.Fatalf("%s: %v, attendu %v", key, got[key], value)
+		}
+	}
+}
+
+//#endregion
+
+//#region TESTS DE RELECTURE
+
+// TestReplayRebuildsBoard rejoue une partie à partir de sa relecture
+// et vérifie que le plateau obtenu est identique à celui de la partie.
+func TestReplayRebuildsBoard(t *testing.T) {
+	g := NewGameWithOptions(7, 8, "Alice", "Bob", GameOptions{Prefilled: 7, Seed: newSeed()}) // Comme "Difficile"
+	for _, col := range []int{0, 1, 2, 3, 4, 5, 6, 7, 0, 1, 2} {
+		g.DropPiece(col)
+	}
+
+	replay := g.GetReplay()
+	if len(replay.Prefilled) != 7 {
+		t.Fatalf("%d jetons pré-remplis enregistrés, 7 attendus", len(replay.Prefilled))
+	}
+
+	board := NewBitboard(replay.Rows, replay.Cols)
+	for _, token := range replay.Prefilled {
+		board.Set(token.Row, token.Col, token.Player)
+	}
+	for i, move := range replay.Moves {
+		if move.Turn != i+1 {
+			t.Fatalf("coup %d numéroté %d", i+1, move.Turn)
+		}
+		if want := (i/5)%2 == 1; move.InverseGravity != want {
+			t.Fatalf("tour %d: gravité inversée %v, attendu %v", move.Turn, move.InverseGravity, want)
+		}
+		if row := board.LandingRow(move.Col, move.InverseGravity); row != move.Row {
+			t.Fatalf("tour %d: ligne %d enregistrée, %d recalculée", move.Turn, move.Row, row)
+		}
+		board.Set(move.Row, move.Col, move.Player)
+	}
+
+	if !reflect.DeepEqual(board.Grid(), g.Board.Grid()) {
+		t.Fatal("le plateau rejoué diffère du plateau de la partie")
+	}
+}
+
+//#endregion
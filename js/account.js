@@ -0,0 +1,98 @@
+/**
+ * PUISSANCE 4 - MODULE COMPTES
+ *
+ * Ce fichier gère la page des comptes : inscription, connexion et
+ * déconnexion. Le serveur dépose le cookie de session ; ce script se
+ * contente d'afficher l'état du compte.
+ */
+
+//#region AFFICHAGE DU COMPTE
+
+/**
+ * Affiche le compte connecté, ou le formulaire de connexion
+ *
+ * @param {Object|null} profile - Profil renvoyé par le serveur (null si déconnecté)
+ * @param {string} profile.username - Identifiant du compte
+ * @param {string} profile.skin - Skin préféré ('' si aucun)
+ */
+function showAccount(profile) {
+    document.getElementById('accountInfo').style.display = profile ? 'block' : 'none';
+    document.getElementById('accountForm').style.display = profile ? 'none' : 'block';
+    document.getElementById('accountError').textContent = '';
+
+    if (profile) {
+        document.getElementById('accountName').textContent = profile.username;
+        document.getElementById('accountSkin').innerHTML = profile.skin
+            ? `Jeton préféré : <img class="account-skin" src="/static/tokens/${profile.skin}.png" alt="${profile.skin}">`
+            : 'Aucun jeton préféré (il sera retenu à votre prochaine partie)';
+    }
+}
+
+/**
+ * Charge le compte connecté depuis le serveur
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois le compte affiché
+ */
+async function loadAccount() {
+    const response = await fetch('/api/account/me');
+    showAccount(response.ok ? await response.json() : null);
+}
+
+//#endregion
+
+//#region CONNEXION ET DÉCONNEXION
+
+/**
+ * Envoie l'identifiant et le mot de passe pour se connecter ou créer un compte
+ *
+ * @async
+ * @param {string} action - 'login' ou 'register'
+ * @returns {Promise<void>} Promesse résolue une fois la réponse traitée
+ */
+async function submitCredentials(action) {
+    const response = await fetch(`/api/account/${action}`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({
+            username: document.getElementById('username').value.trim(),
+            password: document.getElementById('password').value
+        })
+    });
+    const json = await response.json();
+
+    if (!response.ok) {
+        document.getElementById('accountError').textContent = json.error || 'Erreur serveur';
+        return;
+    }
+    document.getElementById('password').value = '';
+    showAccount(json);
+}
+
+/**
+ * Ferme la session en cours
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois le formulaire réaffiché
+ */
+async function logout() {
+    await fetch('/api/account/logout', { method: 'POST' });
+    showAccount(null);
+}
+
+//#endregion
+
+//#region DÉMARRAGE AUTOMATIQUE
+
+/**
+ * Branche le formulaire et affiche l'état du compte au chargement de la page
+ */
+document.addEventListener('DOMContentLoaded', function() {
+    document.getElementById('accountForm').addEventListener('submit', function(event) {
+        event.preventDefault();
+        submitCredentials(event.submitter.dataset.action);
+    });
+    loadAccount();
+});
+
+//#endregion
@@ -0,0 +1,134 @@
+/**
+ * PUISSANCE 4 - MODULE ARCHIVE
+ *
+ * Ce fichier gère la page de l'archive : recherche des parties terminées
+ * (/api/games), pagination et ouverture d'une partie en relecture.
+ */
+
+//#region ÉTAT DE LA RECHERCHE
+
+/**
+ * Page affichée (à partir de 1)
+ * @type {number}
+ */
+let archivePage = 1;
+
+/**
+ * Nombre total de pages pour la recherche en cours
+ * @type {number}
+ */
+let archivePages = 1;
+
+//#endregion
+
+//#region AFFICHAGE DES PARTIES
+
+/**
+ * Décrit le résultat d'une partie archivée
+ *
+ * @param {Object} game - Résumé renvoyé par /api/games
+ * @returns {string} Gagnant et cause de la fin ('Alice (abandon)', 'Nulle', etc.)
+ */
+function describeResult(game) {
+    const reasons = {
+        'resignation': 'abandon',
+        'timeout': 'au temps',
+        'agreed-draw': 'acceptée',
+        'no-alignment': 'bloquée'
+    };
+    const detail = reasons[game.reason] ? ` (${reasons[game.reason]})` : '';
+    if (game.winner === 'draw') {
+        return `Nulle${detail}`;
+    }
+    return `${game.winner === 'player1' ? game.player1 : game.player2}${detail}`;
+}
+
+/**
+ * Affiche une page de résultats
+ *
+ * Les pseudos sont insérés en texte : ils sont choisis par les joueurs.
+ *
+ * @param {Array<Object>} games - Parties de la page
+ */
+function renderArchive(games) {
+    const rows = document.getElementById('archiveRows');
+    rows.innerHTML = '';
+    games.forEach(game => {
+        const row = document.createElement('tr');
+        [
+            new Date(game.endedAt).toLocaleString('fr-FR'),
+            `${game.player1} – ${game.player2}`,
+            `${game.rows}x${game.cols}${game.difficulty ? ` (${game.difficulty})` : ''}`,
+            describeResult(game),
+            game.turnCount
+        ].forEach(text => {
+            const cell = document.createElement('td');
+            cell.textContent = text;
+            row.appendChild(cell);
+        });
+
+        const link = document.createElement('a');
+        link.className = 'account-link';
+        link.href = `/game?archive=${encodeURIComponent(game.id)}`;
+        link.textContent = '🎬 Revoir';
+        const cell = document.createElement('td');
+        cell.appendChild(link);
+        row.appendChild(cell);
+
+        rows.appendChild(row);
+    });
+}
+
+//#endregion
+
+//#region RECHERCHE
+
+/**
+ * Charge une page de résultats avec les filtres du formulaire
+ *
+ * @async
+ * @param {number} page - Page demandée (à partir de 1)
+ * @returns {Promise<void>} Promesse résolue une fois la page affichée
+ */
+async function searchArchive(page) {
+    const params = new URLSearchParams();
+    new FormData(document.getElementById('archiveFilters')).forEach((value, key) => {
+        if (value) params.set(key, value);
+    });
+    params.set('page', page);
+
+    const response = await fetch(`/api/games?${params}`);
+    const json = await response.json();
+    const error = document.getElementById('archiveError');
+    if (!response.ok) {
+        error.textContent = json.error || 'Erreur serveur';
+        return;
+    }
+    error.textContent = '';
+
+    archivePage = json.page;
+    archivePages = Math.max(1, Math.ceil(json.total / json.pageSize));
+    renderArchive(json.games);
+    document.getElementById('pageLabel').textContent = `Page ${archivePage} / ${archivePages} · ${json.total} partie(s)`;
+    document.getElementById('previousPage').disabled = archivePage <= 1;
+    document.getElementById('nextPage').disabled = archivePage >= archivePages;
+}
+
+//#endregion
+
+//#region DÉMARRAGE AUTOMATIQUE
+
+/**
+ * Branche les filtres et la pagination, puis affiche les parties les plus récentes
+ */
+document.addEventListener('DOMContentLoaded', function() {
+    document.getElementById('archiveFilters').addEventListener('submit', function(event) {
+        event.preventDefault();
+        searchArchive(1);
+    });
+    document.getElementById('previousPage').addEventListener('click', () => searchArchive(archivePage - 1));
+    document.getElementById('nextPage').addEventListener('click', () => searchArchive(archivePage + 1));
+    searchArchive(1);
+});
+
+//#endregion
@@ -23,18 +23,36 @@ const API_URL = 'http://localhost:8080/api';
 // Récupération de la difficulté depuis sessionStorage
 const difficulty = sessionStorage.getItem('difficulty');
 
-// Si aucune donnée n'est présente, redirection vers la page d'accueil
-if (!difficulty) {
+// Paramètres d'invitation (/game?join=<id>&seat=<jeton>[&as=<siège>]) pour rejoindre une partie en ligne
+const inviteParams = new URLSearchParams(window.location.search);
+const joinGameId = inviteParams.get('join');
+
+// Partie archivée ouverte en relecture depuis /archive (/game?archive=<id>)
+const archiveGameId = inviteParams.get('archive');
+
+// Si aucune donnée n'est présente (et qu'il ne s'agit ni d'une invitation
+// ni d'une partie archivée), redirection vers la page d'accueil
+if (!difficulty && !joinGameId && !archiveGameId) {
     window.location.href = '/';
 }
 
-// Application du thème visuel correspondant à la difficulté
-document.body.className = difficulty;
+// Application du thème visuel et du fond correspondant à la difficulté
+document.body.className = sessionStorage.getItem('theme') || difficulty || 'easy';
+if (sessionStorage.getItem('background')) {
+    document.body.style.setProperty('--map-background', `url('${sessionStorage.getItem('background')}')`);
+}
 
 //#endregion
 
 //#region VARIABLES D'ÉTAT DU JEU
 
+/**
+ * Identifiant de la partie sur le serveur
+ * Attribué par /api/game/new, il permet à chaque onglet de jouer sa propre partie
+ * @type {string|null} Identifiant de la partie (null tant qu'elle n'est pas créée)
+ */
+let gameId = null;
+
 /**
  * Nombre de lignes du plateau de jeu
  * @type {number} Nombre de lignes du plateau
@@ -66,6 +84,13 @@ let board = [];
  */
 let gameOver = false;
 
+/**
+ * Indique si la fin de partie a déjà été affichée
+ * Évite un double affichage quand l'état arrive à la fois par fetch et par WebSocket
+ * @type {boolean} true si le message de fin (et les feux d'artifice) ont été affichés
+ */
+let gameOverShown = false;
+
 /**
  * Skins sélectionnés par chaque joueur
  * @type {{player1: string, player2: string}} Objet contenant les skins choisis
@@ -84,6 +109,82 @@ let playerPseudos = {
     player2: sessionStorage.getItem('player2Pseudo')
 };
 
+/**
+ * Jetons secrets des sièges détenus par ce navigateur
+ * En local les deux sièges sont détenus ; en ligne, un seul
+ * @type {{player1?: string, player2?: string}} Jeton de chaque siège détenu
+ */
+let seatTokens = {};
+
+/**
+ * Siège joué par ce navigateur en partie en ligne
+ * @type {string|null} 'player1', 'player2' ou null en local (les deux joueurs sur le même écran)
+ */
+let mySeat = null;
+
+/**
+ * Indique si la partie est jouée en ligne (second joueur invité par lien)
+ * @type {boolean} true si le joueur 1 a choisi une partie en ligne
+ */
+const onlineMode = sessionStorage.getItem('online') === '1';
+
+/**
+ * Nombre de jetons à aligner pour gagner (choisi sur /skins)
+ * @type {number} Longueur d'alignement demandée à la création de la partie
+ */
+let winLength = parseInt(sessionStorage.getItem('winLength')) || 4;
+
+/**
+ * Préréglage de la règle d'inversion de la gravité (choisi sur /skins)
+ * @type {string} 'classic', 'off', 'chaos' ou 'special'
+ */
+const gravityPreset = sessionStorage.getItem('gravity') || 'classic';
+
+/**
+ * Graine imposée pour la disposition initiale (choisie sur /skins)
+ * @type {number|null} Graine envoyée à /api/game/new, null pour une graine aléatoire
+ */
+const requestedSeed = sessionStorage.getItem('seed') !== null ? parseInt(sessionStorage.getItem('seed')) : null;
+
+/**
+ * Demande une disposition initiale jugée équilibrée par l'ordinateur (choisi sur /skins)
+ * @type {boolean} true si l'option "Départ équilibré" est cochée
+ */
+const evenStart = sessionStorage.getItem('evenStart') === '1';
+
+/**
+ * Joueur ayant proposé la nulle, en attente de réponse
+ * @type {string} 'player1', 'player2' ou '' si aucune proposition
+ */
+let drawOfferedBy = '';
+
+/**
+ * Cadence des pendules (choisie sur /skins)
+ * @type {Object|null} Cadence envoyée à /api/game/new ({base, increment} ou {perMove}), null sans pendules
+ */
+const requestedTimeControl = JSON.parse(sessionStorage.getItem('timeControl') || 'null');
+
+/**
+ * Temps restant de chaque joueur lors du dernier état reçu
+ * @type {{player1: number, player2: number, running: boolean, receivedAt: number}|null} Temps en millisecondes (null sans pendules)
+ */
+let clocks = null;
+
+/**
+ * Indique si le prochain coup est un coup spécial qui inverse la gravité
+ * @type {boolean} true quand le joueur a armé son coup spécial
+ */
+let specialFlipArmed = false;
+
+/**
+ * Joueur contrôlé par l'ordinateur et son niveau
+ * @type {{player: string, level: number}} Siège de l'ordinateur ('' si aucun) et niveau (1 à 5)
+ */
+let botConfig = {
+    player: sessionStorage.getItem('bot') || '',
+    level: parseInt(sessionStorage.getItem('botLevel')) || 0
+};
+
 //#endregion
 
 //#region COMMUNICATION AVEC LE BACKEND
@@ -97,10 +198,11 @@ let playerPseudos = {
  * @param {string} endpoint - Point d'API à appeler (ex: '/game/new')
  * @param {string} [method='GET'] - Méthode HTTP à utiliser (GET, POST, etc.)
  * @param {Object|null} [data=null] - Données JSON à envoyer dans le corps de la requête (pour POST)
+ * @param {string} [seatToken=''] - Jeton de siège envoyé dans l'en-tête X-Seat-Token
  * @returns {Promise<Object>} Promesse contenant la réponse JSON du serveur
  * @throws {Error} Erreur levée si la requête échoue ou si le serveur retourne une erreur
  */
-async function callAPI(endpoint, method = 'GET', data = null) {
+async function callAPI(endpoint, method = 'GET', data = null, seatToken = '') {
     // Configuration de base de la requête
     const options = {
         method: method,
@@ -109,6 +211,11 @@ async function callAPI(endpoint, method = 'GET', data = null) {
         },
     };
 
+    // Jeton du siège pour les actions réservées aux joueurs
+    if (seatToken) {
+        options.headers['X-Seat-Token'] = seatToken;
+    }
+
     // Ajout du body pour les requêtes POST
     if (data) {
         options.body = JSON.stringify(data);
@@ -139,23 +246,25 @@ async function callAPI(endpoint, method = 'GET', data = null) {
  * Initialise une nouvelle partie
  *
  * Cette fonction :
- * 1. Appelle le backend pour créer une nouvelle partie
+ * 1. Appelle le backend pour créer une nouvelle partie (ou rejoint celle de l'invitation)
  * 2. Récupère l'état initial (avec jetons pré-remplis)
  * 3. Crée la grille visuelle HTML
  * 4. Affiche le joueur actuel
+ * 5. S'abonne aux mises à jour en temps réel
  *
  * @async
  * @returns {Promise<void>} Promesse résolue une fois l'initialisation terminée
  */
 async function initBoard() {
     try {
-        // Création de la partie sur le serveur
-        const state = await callAPI('/game/new', 'POST', {
-            rows: ROWS,
-            cols: COLS,
-            player1: playerPseudos.player1,
-            player2: playerPseudos.player2
-        });
+        // Une partie archivée est seulement relue : ni coups ni mises à jour en temps réel
+        if (archiveGameId) {
+            await openArchivedGame();
+            return;
+        }
+
+        // Création de la partie sur le serveur, ou récupération de la partie rejointe
+        const state = joinGameId ? await joinGame() : await createGame();
 
         // Mise à jour de l'état local avec la réponse du serveur
         updateLocalState(state);
@@ -166,6 +275,9 @@ async function initBoard() {
         // Affichage du joueur actuel
         updatePlayerDisplay();
 
+        // Abonnement aux mises à jour en temps réel de cette partie
+        connectLiveUpdates();
+
         // Réinitialisation du message
         document.getElementById('message').textContent = '';
         document.getElementById('message').className = 'message';
@@ -182,19 +294,27 @@ async function initBoard() {
  * retourné par le backend Go
  *
  * @param {Object} state - État du jeu retourné par le backend
+ * @param {string} state.id - Identifiant de la partie sur le serveur
  * @param {Array<Array<string>>} state.board - Grille du plateau de jeu
  * @param {string} state.currentPlayer - Identifiant du joueur dont c'est le tour
  * @param {boolean} state.gameOver - Indique si la partie est terminée
  * @param {number} state.rows - Nombre de lignes du plateau
  * @param {number} state.cols - Nombre de colonnes du plateau
  * @param {boolean} state.inverseGravity - Indique si la gravité inversée est active
+ * @param {boolean} state.canUndo - Indique si un coup peut être annulé
+ * @param {boolean} state.canRedo - Indique si un coup annulé peut être rétabli
  */
 function updateLocalState(state) {
+    gameId = state.id;
     board = state.board;
     currentPlayer = state.currentPlayer;
     gameOver = state.gameOver;
     ROWS = state.rows;
     COLS = state.cols;
+    winLength = state.winLength;
+
+    // Rappel de la règle d'alignement de cette partie
+    document.getElementById('winRule').textContent = `Alignez ${winLength} jetons pour gagner · Graine ${state.seed}`;
 
     // Gestion de l'affichage de la gravité inversée
     if (state.inverseGravity) {
@@ -202,6 +322,23 @@ function updateLocalState(state) {
     } else {
         document.body.classList.remove('inverse-gravity');
     }
+
+    // Décompte et coup spécial selon la règle de gravité
+    updateGravityRule(state);
+
+    // Activation des boutons d'annulation selon l'historique
+    document.getElementById('undoButton').disabled = !state.canUndo;
+    document.getElementById('redoButton').disabled = !state.canRedo;
+
+    // L'indice précédent ne vaut plus pour la nouvelle position
+    clearHint();
+    document.getElementById('hintButton').disabled = state.gameOver;
+
+    // Temps restant de chaque joueur
+    updateClocks(state);
+
+    // Abandon et proposition de nulle
+    updateDrawOffer(state);
 }
 
 /**
@@ -253,12 +390,16 @@ function createBoardUI() {
  * 2. Crée une image avec le skin approprié
  * 3. Insère l'image dans la cellule
  *
+ * Un obstacle neutre ('blocked') n'a pas de skin : seule sa classe CSS
+ * est ajoutée.
+ *
  * @param {HTMLElement} cell - Élément HTML DOM de la cellule cible
- * @param {string} player - Identifiant du joueur possédant le jeton ('player1' ou 'player2')
+ * @param {string} player - Contenu de la case ('player1', 'player2' ou 'blocked')
  */
 function addTokenToCell(cell, player) {
     // Ajout de la classe CSS pour le style
     cell.classList.add(player);
+    if (player === 'blocked') return;
 
     // Création de l'image du jeton
     const img = document.createElement('img');
@@ -273,6 +414,105 @@ function addTokenToCell(cell, player) {
 
 //#endregion
 
+//#region SIÈGES ET INVITATION
+
+/**
+ * Crée la partie sur le serveur à partir des choix faits sur /skins
+ *
+ * Les jetons de siège reçus sont conservés ; en ligne, seul celui du
+ * joueur 1 est utilisé et le lien d'invitation est affiché.
+ *
+ * @async
+ * @returns {Promise<Object>} État initial de la partie
+ */
+async function createGame() {
+    // La difficulté choisie fixe les dimensions et les jetons pré-remplis
+    const request = {
+        difficulty: difficulty,
+        winLength: winLength,
+        gravity: { preset: gravityPreset },
+        evenStart: evenStart,
+        player1: playerPseudos.player1,
+        player2: playerPseudos.player2,
+        bot: botConfig.player,
+        botLevel: botConfig.level
+    };
+    if (requestedSeed !== null) {
+        request.seed = requestedSeed;
+    }
+    if (requestedTimeControl) {
+        request.timeControl = requestedTimeControl;
+    }
+    if (sessionStorage.getItem('bindSeat')) {
+        request.bindSeat = sessionStorage.getItem('bindSeat');
+    }
+    const state = await callAPI('/game/new', 'POST', request);
+
+    seatTokens = state.seats;
+    if (onlineMode && state.invite) {
+        mySeat = 'player1';
+        seatTokens = { player1: state.seats.player1 };
+        document.getElementById('inviteLink').value = `${window.location.origin}${state.invite}`;
+        document.getElementById('inviteBox').style.display = 'block';
+    }
+    return state;
+}
+
+/**
+ * Rejoint la partie d'une invitation en tant que joueur 2
+ *
+ * Une partie trouvée par la file d'attente (/lobby) précise le siège
+ * dans le paramètre "as" (le joueur 1 est tiré au sort). Un joueur
+ * connecté lie son siège à son compte (/api/game/bind).
+ * Les skins n'ayant pas été choisis sur ce navigateur, des skins
+ * par défaut sont utilisés.
+ *
+ * @async
+ * @returns {Promise<Object>} État actuel de la partie rejointe
+ */
+async function joinGame() {
+    gameId = joinGameId;
+    mySeat = inviteParams.get('as') === 'player1' ? 'player1' : 'player2';
+    seatTokens = { [mySeat]: inviteParams.get('seat') };
+
+    // Compte connecté : le siège lui est lié pour que la partie soit classée
+    // (refusé sans session ou une fois la partie commencée)
+    let state;
+    try {
+        state = await callAPI(`/game/bind?id=${gameId}`, 'POST', null, seatTokens[mySeat]);
+    } catch (error) {
+        state = await callAPI(`/game/state?id=${gameId}`);
+    }
+    playerPseudos = { player1: state.player1, player2: state.player2 };
+    selectedSkins = {
+        player1: selectedSkins.player1 || 'skin1',
+        player2: selectedSkins.player2 || 'skin2'
+    };
+    return state;
+}
+
+/**
+ * Retourne le jeton de siège à présenter pour jouer le coup d'un joueur
+ *
+ * @param {string} player - Joueur qui joue ('player1' ou 'player2')
+ * @returns {string} Jeton du siège (chaîne vide si ce navigateur ne le détient pas)
+ */
+function seatTokenFor(player) {
+    return seatTokens[player] || '';
+}
+
+/**
+ * Retourne un jeton de siège quelconque détenu par ce navigateur
+ * (pour les actions ouvertes aux deux joueurs : annuler, rétablir, supprimer)
+ *
+ * @returns {string} Jeton d'un siège détenu (chaîne vide si aucun)
+ */
+function anySeatToken() {
+    return Object.values(seatTokens)[0] || '';
+}
+
+//#endregion
+
 //#region LOGIQUE DE JEU
 
 /**
@@ -280,8 +520,8 @@ function addTokenToCell(cell, player) {
  *
  * Cette fonction est le cœur de la mécanique de jeu :
  * 1. Envoie le coup au backend
- * 2. Reçoit le nouvel état
- * 3. Anime la chute du jeton
+ * 2. Reçoit le nouvel état (incluant la réponse de l'ordinateur éventuel)
+ * 3. Anime la chute des nouveaux jetons
  * 4. Vérifie la fin de partie
  *
  * @async
@@ -292,9 +532,12 @@ async function dropPiece(col) {
     // Ne rien faire si la partie est terminée
     if (gameOver) return;
 
+    // En ligne, on ne joue que pendant son propre tour
+    if (mySeat && currentPlayer !== mySeat) return;
+
     try {
         // Envoi du coup au backend
-        const state = await callAPI('/game/drop', 'POST', { col: col });
+        const state = await callAPI(`/game/drop?id=${gameId}`, 'POST', { col: col, flipGravity: specialFlipArmed }, seatTokenFor(currentPlayer));
 
         // Vérification d'erreur (colonne pleine, etc.)
         if (state.error) {
@@ -302,22 +545,12 @@ async function dropPiece(col) {
             return;
         }
 
-        // Récupération des informations sur le coup joué
-        const lastMove = state.lastMove;
-        const row = lastMove.row;
-
-        // Récupération de la cellule HTML correspondante
-        const cell = document.getElementById(`cell-${row}-${col}`);
-
-        // Le backend indique quel joueur a joué
-        const playerWhoMoved = state.board[row][col];
-
-        // Ajout des classes pour le style et l'animation
-        cell.classList.add(playerWhoMoved);
-        cell.classList.add('dropping');
+        // Le coup spécial éventuel a été joué
+        specialFlipArmed = false;
 
-        // Ajout de l'image du jeton
-        addTokenToCell(cell, state.board[row][col]);
+        // Animation des jetons apparus depuis l'état précédent
+        // (le coup joué et, contre l'ordinateur, sa réponse)
+        animateNewTokens(board, state.board);
 
         // Mise à jour de l'état local
         updateLocalState(state);
@@ -325,11 +558,6 @@ async function dropPiece(col) {
         // Mise à jour de l'affichage du joueur actuel
         updatePlayerDisplay();
 
-        // Retrait de la classe d'animation après 600ms
-        setTimeout(() => {
-            cell.classList.remove('dropping');
-        }, 600);
-
         // Vérification de fin de partie après l'animation
         setTimeout(() => {
             if (state.gameOver) {
@@ -342,6 +570,29 @@ async function dropPiece(col) {
     }
 }
 
+/**
+ * Anime l'apparition des jetons présents dans le nouvel état mais pas dans l'ancien
+ *
+ * @param {Array<Array<string>>} oldBoard - Plateau avant le coup
+ * @param {Array<Array<string>>} newBoard - Plateau retourné par le backend
+ */
+function animateNewTokens(oldBoard, newBoard) {
+    for (let row = 0; row < ROWS; row++) {
+        for (let col = 0; col < COLS; col++) {
+            if (oldBoard[row][col] || !newBoard[row][col]) continue;
+
+            const cell = document.getElementById(`cell-${row}-${col}`);
+            cell.classList.add('dropping');
+            addTokenToCell(cell, newBoard[row][col]);
+
+            // Retrait de la classe d'animation après 600ms
+            setTimeout(() => {
+                cell.classList.remove('dropping');
+            }, 600);
+        }
+    }
+}
+
 /**
  * Gère la fin de partie (victoire ou égalité)
  *
@@ -349,18 +600,39 @@ async function dropPiece(col) {
  * 1. Affiche un message approprié (victoire ou égalité)
  * 2. Lance les feux d'artifice en cas de victoire
  * 3. Désactive le plateau de jeu
+ * 4. Propose la relecture de la partie
  *
  * @param {Object} state - État du jeu retourné par le backend
  * @param {string} state.winner - Identifiant du gagnant ('player1', 'player2' ou 'draw' en cas d'égalité)
+ * @param {string} state.reason - Cause de la fin ('alignment', 'full-board', 'no-alignment', 'resignation', 'agreed-draw' ou 'timeout')
+ * @param {Array<{row: number, col: number}>} state.winningCells - Cases de l'alignement gagnant
  */
 function handleGameOver(state) {
     gameOver = true;
+
+    // Mise en évidence de l'alignement gagnant (à chaque appel : le plateau a pu être redessiné)
+    highlightWinningCells(state.winningCells);
+
+    if (gameOverShown) return;
+    gameOverShown = true;
+
     const message = document.getElementById('message');
+    document.getElementById('replayButton').style.display = '';
     
     if (state.winner === 'draw') {
-        // Cas d'égalité (plateau plein sans gagnant)
-        message.textContent = '⚖️ Match nul ! ⚖️';
+        // Cas d'égalité (plateau plein, plus aucun alignement possible ou nulle acceptée)
+        message.textContent = state.reason === 'agreed-draw' ? '🤝 Nulle acceptée ! 🤝' : '⚖️ Match nul ! ⚖️';
         message.className = 'message';
+    } else if (state.reason === 'timeout' || state.reason === 'resignation') {
+        // Cas de défaite au temps ou par abandon
+        const winner = state.winner === 'player1' ? playerPseudos.player1 : playerPseudos.player2;
+        const loser = state.winner === 'player1' ? playerPseudos.player2 : playerPseudos.player1;
+
+        message.textContent = state.reason === 'timeout'
+            ? `⏱️ ${loser} a perdu au temps, victoire de ${winner} !`
+            : `🏳️ ${loser} abandonne, victoire de ${winner} !`;
+        message.className = 'message winner';
+        createFireworks();
     } else {
         // Cas de victoire
         const winner = state.winner === 'player1' ? playerPseudos.player1 : playerPseudos.player2;
@@ -374,10 +646,479 @@ function handleGameOver(state) {
     }
 }
 
+/**
+ * Met en évidence les cases de l'alignement gagnant
+ *
+ * Les cases s'allument l'une après l'autre, en partant du coup gagnant.
+ *
+ * @param {Array<{row: number, col: number}>} [cells=[]] - Cases gagnantes (vide en cas d'égalité)
+ */
+function highlightWinningCells(cells = []) {
+    cells.forEach((cell, i) => {
+        const element = document.getElementById(`cell-${cell.row}-${cell.col}`);
+        element.style.animationDelay = `${i * 80}ms`;
+        element.classList.add('winning');
+    });
+}
+
+/**
+ * Annule le dernier coup (et la réponse de l'ordinateur le cas échéant)
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois le plateau redessiné
+ */
+async function undoMove() {
+    await applyHistoryAction('/game/undo');
+}
+
+/**
+ * Rétablit le dernier coup annulé
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois le plateau redessiné
+ */
+async function redoMove() {
+    await applyHistoryAction('/game/redo');
+}
+
+/**
+ * Appelle une action d'historique et redessine tout le plateau
+ *
+ * Le message de fin de partie est effacé si la partie reprend.
+ *
+ * @async
+ * @param {string} endpoint - Point d'API à appeler ('/game/undo' ou '/game/redo')
+ * @returns {Promise<void>} Promesse résolue une fois le plateau redessiné
+ */
+async function applyHistoryAction(endpoint) {
+    try {
+        const state = await callAPI(`${endpoint}?id=${gameId}`, 'POST', null, anySeatToken());
+
+        updateLocalState(state);
+        createBoardUI();
+        updatePlayerDisplay();
+
+        if (state.gameOver) {
+            handleGameOver(state);
+        } else {
+            clearGameOver();
+        }
+    } catch (error) {
+        console.error('Erreur lors de l\'annulation:', error);
+    }
+}
+
+/**
+ * Efface l'affichage de fin de partie quand celle-ci reprend (après une annulation)
+ */
+function clearGameOver() {
+    gameOverShown = false;
+    document.getElementById('message').textContent = '';
+    document.getElementById('message').className = 'message';
+    document.getElementById('replayButton').style.display = 'none';
+}
+
+//#endregion
+
+//#region INDICES
+
+/**
+ * Demande au serveur le coup conseillé au joueur dont c'est le tour
+ *
+ * La colonne conseillée est mise en évidence et la raison (gagne,
+ * bloque l'adversaire, double menace) affichée sous le plateau.
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois l'indice affiché
+ */
+async function requestHint() {
+    if (gameOver) return;
+
+    // En ligne, on ne demande un indice que pendant son propre tour
+    if (mySeat && currentPlayer !== mySeat) return;
+
+    try {
+        const hint = await callAPI(`/game/hint?id=${gameId}`, 'GET', null, seatTokenFor(currentPlayer));
+
+        clearHint();
+        document.querySelectorAll('#board .column')[hint.col].classList.add('hinted');
+        document.getElementById('hintMessage').textContent = `💡 Colonne ${hint.col + 1} : ${hint.message}`;
+    } catch (error) {
+        console.error('Erreur lors de la demande d\'indice:', error);
+    }
+}
+
+/**
+ * Retire la mise en évidence de l'indice affiché
+ */
+function clearHint() {
+    document.querySelectorAll('#board .column.hinted').forEach(column => column.classList.remove('hinted'));
+    document.getElementById('hintMessage').textContent = '';
+}
+
+//#endregion
+
+//#region MISES À JOUR EN TEMPS RÉEL
+
+/**
+ * Connexion WebSocket aux événements de la partie
+ * @type {WebSocket|null} Connexion active (null si aucune)
+ */
+let liveSocket = null;
+
+/**
+ * Ouvre la connexion WebSocket de la partie en cours
+ *
+ * Les coups joués depuis un autre écran (ou par l'adversaire distant)
+ * arrivent ainsi sans avoir à interroger /api/game/state.
+ */
+function connectLiveUpdates() {
+    const url = `${API_URL.replace(/^http/, 'ws')}/game/ws?id=${gameId}`;
+    liveSocket = new WebSocket(url);
+
+    liveSocket.onmessage = (message) => {
+        const event = JSON.parse(message.data);
+        switch (event.type) {
+            case 'state':
+                applyRemoteState(event.state);
+                break;
+            case 'reset':
+                document.getElementById('message').textContent = 'Partie supprimée';
+                gameOver = true;
+                break;
+        }
+    };
+
+    liveSocket.onerror = (error) => {
+        console.error('Erreur WebSocket:', error);
+    };
+}
+
+/**
+ * Applique un état poussé par le serveur
+ *
+ * Les nouveaux jetons sont animés ; si des jetons ont disparu (annulation),
+ * le plateau est entièrement redessiné. Un état déjà connu (réponse à notre
+ * propre coup) ne change rien à l'affichage.
+ *
+ * @param {Object} state - État du jeu retourné par le backend
+ */
+function applyRemoteState(state) {
+    // Pendant une relecture, l'affichage appartient au mode relecture
+    if (replayData) return;
+
+    const removed = board.some((row, r) => row.some((cell, c) => cell && !state.board[r][c]));
+
+    if (removed) {
+        updateLocalState(state);
+        createBoardUI();
+    } else {
+        animateNewTokens(board, state.board);
+        updateLocalState(state);
+    }
+    updatePlayerDisplay();
+
+    if (state.gameOver) {
+        setTimeout(() => handleGameOver(state), 600);
+    } else {
+        clearGameOver();
+    }
+}
+
+//#endregion
+
+//#region RELECTURE DE PARTIE
+
+/**
+ * Relecture fournie par le backend (null hors du mode relecture)
+ * @type {Object|null} Jetons pré-remplis et liste des coups de la partie
+ */
+let replayData = null;
+
+/**
+ * Nombre de coups affichés dans la relecture
+ * @type {number} De 0 (disposition initiale) à replayData.moves.length
+ */
+let replayStep = 0;
+
+/**
+ * Démarre la relecture de la partie terminée
+ *
+ * Récupère la séquence des coups puis affiche la position finale,
+ * à partir de laquelle les joueurs peuvent remonter coup par coup.
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois la relecture affichée
+ */
+async function startReplay() {
+    try {
+        replayData = await callAPI(`/game/replay?id=${gameId}`);
+        document.getElementById('replayButton').style.display = 'none';
+        document.getElementById('replayControls').style.display = 'flex';
+        replayStepTo(replayData.moves.length);
+    } catch (error) {
+        console.error('Erreur lors du chargement de la relecture:', error);
+    }
+}
+
+/**
+ * Affiche la position après un nombre donné de coups
+ *
+ * Le plateau est reconstruit depuis les jetons pré-remplis en appliquant
+ * les coups un par un ; le dernier coup affiché est animé.
+ *
+ * @param {number} step - Nombre de coups à appliquer (borné à la partie)
+ */
+function replayStepTo(step) {
+    const moves = replayData.moves;
+    replayStep = Math.max(0, Math.min(step, moves.length));
+
+    // Reconstruction du plateau
+    board = Array.from({ length: ROWS }, () => Array(COLS).fill(''));
+    replayData.prefilled.forEach(token => {
+        board[token.row][token.col] = token.player;
+    });
+    for (let i = 0; i < replayStep; i++) {
+        board[moves[i].row][moves[i].col] = moves[i].player;
+    }
+    createBoardUI();
+
+    // Mise en évidence du dernier coup et de la gravité au moment de ce coup
+    const lastMove = moves[replayStep - 1];
+    if (lastMove) {
+        document.getElementById(`cell-${lastMove.row}-${lastMove.col}`).classList.add('dropping');
+        document.body.classList.toggle('inverse-gravity', lastMove.inverseGravity);
+    } else {
+        document.body.classList.remove('inverse-gravity');
+    }
+
+    // Alignement gagnant sur la position finale
+    if (replayStep === moves.length) {
+        highlightWinningCells(replayData.winningCells);
+    }
+
+    document.getElementById('replayLabel').textContent = `Coup ${replayStep} / ${moves.length}`;
+}
+
+/**
+ * Ouvre une partie de l'archive directement en mode relecture
+ *
+ * La partie archivée contient les mêmes champs que /api/game/replay :
+ * elle sert telle quelle de données de relecture. Les commandes de jeu
+ * sont masquées puisque la partie est terminée.
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois la position finale affichée
+ */
+async function openArchivedGame() {
+    const archived = await callAPI(`/games/${encodeURIComponent(archiveGameId)}`);
+
+    ROWS = archived.rows;
+    COLS = archived.cols;
+    playerPseudos = { player1: archived.player1, player2: archived.player2 };
+    selectedSkins = {
+        player1: selectedSkins.player1 || 'skin1',
+        player2: selectedSkins.player2 || 'skin2'
+    };
+    document.body.className = archived.difficulty || 'easy';
+    document.getElementById('winRule').textContent =
+        `Alignez ${archived.winLength} jetons pour gagner · Graine ${archived.seed} · ` +
+        `Partie du ${new Date(archived.endedAt).toLocaleString('fr-FR')}`;
+    document.querySelectorAll('.history-controls').forEach(controls => {
+        controls.style.display = 'none';
+    });
+    updatePlayerDisplay();
+
+    replayData = archived;
+    document.getElementById('replayControls').style.display = 'flex';
+    replayStepTo(archived.moves.length);
+    handleGameOver(archived);
+    document.getElementById('replayButton').style.display = 'none';
+}
+
+//#endregion
+
+//#region ABANDON ET NULLE
+
+/**
+ * Siège qui abandonne ou propose la nulle depuis ce navigateur
+ *
+ * En ligne, c'est le siège de ce navigateur ; en partie locale,
+ * celui du joueur dont c'est le tour.
+ *
+ * @returns {string} 'player1' ou 'player2'
+ */
+function actingSeat() {
+    return mySeat || currentPlayer;
+}
+
+/**
+ * Abandonne la partie : l'adversaire est déclaré vainqueur
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois la fin de partie affichée
+ */
+async function resignGame() {
+    if (gameOver || !confirm('Abandonner la partie ?')) return;
+
+    try {
+        const state = await callAPI(`/game/resign?id=${gameId}`, 'POST', null, seatTokenFor(actingSeat()));
+        updateLocalState(state);
+        handleGameOver(state);
+    } catch (error) {
+        console.error('Erreur lors de l\'abandon:', error);
+    }
+}
+
+/**
+ * Propose la nulle à l'adversaire (l'ordinateur répond aussitôt)
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois la proposition envoyée
+ */
+async function offerDraw() {
+    await sendDrawAction(actingSeat(), 'offer');
+}
+
+/**
+ * Accepte ou refuse la nulle proposée par l'adversaire
+ *
+ * @async
+ * @param {string} action - 'accept' ou 'decline'
+ * @returns {Promise<void>} Promesse résolue une fois la réponse envoyée
+ */
+async function answerDraw(action) {
+    await sendDrawAction(mySeat || (drawOfferedBy === 'player1' ? 'player2' : 'player1'), action);
+}
+
+/**
+ * Envoie une action de proposition de nulle au serveur
+ *
+ * @async
+ * @param {string} seat - Siège qui agit ('player1' ou 'player2')
+ * @param {string} action - 'offer', 'accept' ou 'decline'
+ * @returns {Promise<void>} Promesse résolue une fois l'état mis à jour
+ */
+async function sendDrawAction(seat, action) {
+    try {
+        const state = await callAPI(`/game/draw?id=${gameId}`, 'POST', { action: action }, seatTokenFor(seat));
+        updateLocalState(state);
+        if (state.gameOver) {
+            handleGameOver(state);
+        }
+    } catch (error) {
+        document.getElementById('hintMessage').textContent = `🤝 ${error.message}`;
+        console.error('Erreur lors de la proposition de nulle:', error);
+    }
+}
+
+/**
+ * Affiche la proposition de nulle en attente et active les boutons
+ *
+ * @param {Object} state - État de la partie reçu du serveur
+ * @param {string} state.drawOffer - Joueur proposant la nulle ('' si aucune proposition)
+ */
+function updateDrawOffer(state) {
+    drawOfferedBy = state.drawOffer;
+    document.getElementById('resignButton').disabled = state.gameOver;
+    document.getElementById('drawButton').disabled = state.gameOver || state.drawOffer !== '';
+
+    const box = document.getElementById('drawOffer');
+    if (!state.drawOffer) {
+        box.style.display = 'none';
+        return;
+    }
+    box.style.display = 'block';
+
+    // En ligne, seul l'adversaire peut accepter ; l'auteur peut retirer sa proposition
+    const mine = mySeat === state.drawOffer;
+    document.getElementById('drawOfferText').textContent = mine
+        ? 'Proposition de nulle envoyée…'
+        : `${playerPseudos[state.drawOffer]} propose la nulle.`;
+    document.getElementById('acceptDrawButton').style.display = mine ? 'none' : '';
+}
+
 //#endregion
 
 //#region AFFICHAGE ET INTERFACE
 
+/**
+ * Met à jour l'affichage de la règle de gravité
+ *
+ * Affiche le nombre de tours avant la prochaine inversion automatique
+ * et, en règle "coup spécial", le bouton et les charges du joueur actuel.
+ *
+ * @param {Object} state - État de la partie reçu du serveur
+ * @param {number|null} state.gravityFlipIn - Tours avant la prochaine inversion (null si aucune prévue)
+ * @param {Object} state.gravity - Règle de gravité de la partie
+ * @param {Object} state.specialFlips - Coups spéciaux restants par joueur
+ */
+function updateGravityRule(state) {
+    const countdown = document.getElementById('gravityCountdown');
+    if (state.gravityFlipIn === 1) {
+        countdown.textContent = 'La gravité s\'inverse après ce coup !';
+    } else if (state.gravityFlipIn) {
+        countdown.textContent = `Inversion de la gravité dans ${state.gravityFlipIn} tours`;
+    } else {
+        countdown.textContent = '';
+    }
+
+    const button = document.getElementById('specialFlipButton');
+    const flipsLeft = state.specialFlips[state.currentPlayer];
+    button.style.display = state.gravity.mode === 'special' && !state.gameOver ? 'inline-block' : 'none';
+    button.disabled = flipsLeft < 1 || (mySeat && state.currentPlayer !== mySeat);
+    button.textContent = `🔄 Coup spécial (${flipsLeft})`;
+    button.classList.toggle('armed', specialFlipArmed);
+}
+
+/**
+ * Arme ou désarme le coup spécial : le prochain jeton posé inversera la gravité
+ */
+function toggleSpecialFlip() {
+    specialFlipArmed = !specialFlipArmed;
+    document.getElementById('specialFlipButton').classList.toggle('armed', specialFlipArmed);
+}
+
+/**
+ * Enregistre le temps restant reçu du serveur et affiche les pendules
+ *
+ * @param {Object} state - État de la partie reçu du serveur
+ * @param {Object|null} state.clocks - Temps restant par joueur en millisecondes (null sans pendules)
+ * @param {boolean} state.clockRunning - Indique si la pendule du joueur actuel tourne
+ */
+function updateClocks(state) {
+    if (!state.clocks) {
+        clocks = null;
+        document.getElementById('clocks').style.display = 'none';
+        return;
+    }
+    clocks = { ...state.clocks, running: state.clockRunning, receivedAt: Date.now() };
+    document.getElementById('clocks').style.display = 'flex';
+    renderClocks();
+}
+
+/**
+ * Affiche le temps restant de chaque joueur
+ *
+ * La pendule du joueur actuel est décomptée localement depuis le dernier
+ * état reçu : la défaite au temps reste décidée par le serveur.
+ */
+function renderClocks() {
+    if (!clocks) return;
+
+    ['player1', 'player2'].forEach(player => {
+        let left = clocks[player];
+        if (clocks.running && player === currentPlayer) {
+            left = Math.max(0, left - (Date.now() - clocks.receivedAt));
+        }
+        const seconds = Math.ceil(left / 1000);
+        const element = document.getElementById(`clock-${player}`);
+        element.textContent = `${playerPseudos[player]} ${Math.floor(seconds / 60)}:${String(seconds % 60).padStart(2, '0')}`;
+        element.classList.toggle('active', clocks.running && player === currentPlayer);
+        element.classList.toggle('low', left < 10000);
+    });
+}
+
 /**
  * Met à jour l'affichage du joueur actuel
  *
@@ -402,12 +1143,25 @@ function updatePlayerDisplay() {
  * Réinitialise complètement le jeu
  *
  * Cette fonction :
- * 1. Efface toutes les données de sessionStorage
- * 2. Redirige vers la page de sélection de difficulté
+ * 1. Supprime la partie en cours sur le serveur
+ * 2. Efface toutes les données de sessionStorage
+ * 3. Redirige vers la page de sélection de difficulté
  *
  * Appelée lorsque l'utilisateur clique sur "Nouvelle Partie"
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue avant la redirection
  */
-function resetGame() {
+async function resetGame() {
+    // Libération de la partie côté serveur (ignorée si elle n'existe plus)
+    if (gameId) {
+        try {
+            await callAPI(`/game/reset?id=${gameId}`, 'POST', null, anySeatToken());
+        } catch (error) {
+            console.error('Erreur lors de la suppression de la partie:', error);
+        }
+    }
+
     sessionStorage.clear();
     window.location.href = '/';
 }
@@ -418,10 +1172,12 @@ function resetGame() {
 
 /**
  * Initialise le jeu au chargement de la page
- * Lance automatiquement initBoard() quand le DOM est prêt
+ * Lance automatiquement initBoard() quand le DOM est prêt,
+ * puis le décompte local des pendules
  */
 window.addEventListener('DOMContentLoaded', function() {
     initBoard();
+    setInterval(renderClocks, 250);
 });
 
 //#endregion
@@ -0,0 +1,130 @@
+/**
+ * PUISSANCE 4 - MODULE CLASSEMENT
+ *
+ * Ce fichier remplit la page du classement : meilleurs joueurs du préréglage
+ * choisi (/api/leaderboard) et statistiques du joueur sélectionné
+ * (/api/players/{id}/stats).
+ */
+
+//#region CONSTANTES
+
+/**
+ * Libellés des difficultés et des sièges dans les statistiques
+ * @constant {Object<string, string>}
+ */
+const STAT_LABELS = {
+    easy: 'Facile',
+    normal: 'Normal',
+    hard: 'Difficile',
+    custom: 'Personnalisé',
+    player1: 'En premier',
+    player2: 'En second'
+};
+
+//#endregion
+
+//#region MEILLEURS JOUEURS
+
+/**
+ * Charge et affiche les meilleurs joueurs d'un préréglage
+ *
+ * @async
+ * @param {string} preset - Nom du préréglage de difficulté ('easy', 'normal'...)
+ * @returns {Promise<void>} Promesse résolue une fois le tableau affiché
+ */
+async function loadLeaderboard(preset) {
+    document.querySelectorAll('.preset-tab').forEach(tab => {
+        tab.classList.toggle('active', tab.dataset.preset === preset);
+    });
+
+    const response = await fetch(`/api/leaderboard?preset=${encodeURIComponent(preset)}`);
+    const json = await response.json();
+    const rows = document.getElementById('leaderboardRows');
+    rows.innerHTML = '';
+
+    const players = response.ok ? json.players : [];
+    document.getElementById('leaderboardEmpty').style.display = players.length ? 'none' : 'block';
+    players.forEach(player => {
+        const row = document.createElement('tr');
+        row.className = 'leaderboard-row';
+        row.innerHTML = `<td>${player.rank}</td><td></td><td>${player.rating}</td>` +
+            `<td>${player.games}</td><td>${player.wins} / ${player.losses} / ${player.draws}</td>`;
+        row.children[1].textContent = player.username; // Identifiant choisi par le joueur : jamais interprété en HTML
+        row.addEventListener('click', () => loadPlayerStats(player.id));
+        rows.appendChild(row);
+    });
+}
+
+//#endregion
+
+//#region STATISTIQUES D'UN JOUEUR
+
+/**
+ * Formate un taux de victoire en pourcentage
+ *
+ * @param {number} rate - Taux entre 0 et 1
+ * @returns {string} Pourcentage arrondi ('67 %')
+ */
+function formatRate(rate) {
+    return `${Math.round(rate * 100)} %`;
+}
+
+/**
+ * Remplit un tableau de bilans (une ligne par difficulté ou par siège)
+ *
+ * @param {string} id - Identifiant du tbody à remplir
+ * @param {Object<string, Object>} records - Bilans indexés par catégorie
+ */
+function renderRecords(id, records) {
+    const body = document.getElementById(id);
+    body.innerHTML = '';
+    Object.entries(records).forEach(([key, record]) => {
+        const row = document.createElement('tr');
+        row.innerHTML = `<td>${STAT_LABELS[key] || key}</td><td>${record.games}</td>` +
+            `<td>${record.wins} / ${record.losses} / ${record.draws}</td><td>${formatRate(record.winRate)}</td>`;
+        body.appendChild(row);
+    });
+}
+
+/**
+ * Charge et affiche les statistiques d'un joueur
+ *
+ * @async
+ * @param {string} id - Identifiant du compte
+ * @returns {Promise<void>} Promesse résolue une fois les statistiques affichées
+ */
+async function loadPlayerStats(id) {
+    const response = await fetch(`/api/players/${encodeURIComponent(id)}/stats`);
+    if (!response.ok) {
+        return;
+    }
+    const stats = await response.json();
+
+    document.getElementById('statsName').textContent = stats.player.username;
+    document.getElementById('statsSummary').textContent =
+        `${stats.games} partie(s) dont ${stats.ranked.games} classée(s) : ${stats.wins} victoire(s), ` +
+        `${stats.losses} défaite(s), ${stats.draws} nulle(s) (${formatRate(stats.winRate)}) · ` +
+        `${stats.averageTurns.toFixed(1)} coups par partie en moyenne`;
+    renderRecords('statsByDifficulty', stats.byDifficulty);
+    renderRecords('statsBySeat', stats.bySeat);
+    document.getElementById('playerStats').style.display = 'block';
+}
+
+//#endregion
+
+//#region DÉMARRAGE AUTOMATIQUE
+
+/**
+ * Branche les onglets des préréglages et affiche le premier au chargement
+ */
+document.addEventListener('DOMContentLoaded', function() {
+    const tabs = document.querySelectorAll('.preset-tab');
+    tabs.forEach(tab => {
+        tab.addEventListener('click', () => loadLeaderboard(tab.dataset.preset));
+    });
+    if (tabs.length) {
+        loadLeaderboard(tabs[0].dataset.preset);
+    }
+});
+
+//#endregion
@@ -0,0 +1,198 @@
+/**
+ * PUISSANCE 4 - MODULE PARTIE RAPIDE
+ *
+ * Ce fichier gère la file d'attente des parties rapides en ligne :
+ * inscription (/api/lobby/join), attente sur le WebSocket /api/lobby/ws,
+ * puis redirection vers la partie créée par le serveur.
+ */
+
+//#region ÉTAT DE LA RECHERCHE
+
+/**
+ * Ticket de la recherche en cours (null si aucune)
+ * @type {string|null}
+ */
+let lobbyTicket = null;
+
+/**
+ * Connexion WebSocket de l'attente (null si aucune)
+ * @type {WebSocket|null}
+ */
+let lobbySocket = null;
+
+/**
+ * Skin préféré du compte connecté ('' pour un invité)
+ * @type {string}
+ */
+let preferredSkin = '';
+
+//#endregion
+
+//#region AFFICHAGE
+
+/**
+ * Bascule entre le formulaire d'inscription et l'attente
+ *
+ * @param {boolean} waiting - true pendant la recherche d'un adversaire
+ * @param {string} [text=''] - Message d'attente
+ */
+function showWaiting(waiting, text = '') {
+    document.getElementById('lobbyForm').style.display = waiting ? 'none' : 'flex';
+    document.getElementById('lobbyWaiting').style.display = waiting ? 'block' : 'none';
+    document.getElementById('lobbyStatus').textContent = text;
+}
+
+/**
+ * Applique le thème et le fond de la difficulté choisie
+ */
+function applyTheme() {
+    const option = document.getElementById('lobbyDifficulty').selectedOptions[0];
+    if (!option) return;
+    document.body.className = option.dataset.theme || 'easy';
+    document.body.style.setProperty('--map-background', `url('${option.dataset.background}')`);
+}
+
+//#endregion
+
+//#region FILE D'ATTENTE
+
+/**
+ * Ouvre la partie trouvée par le serveur
+ *
+ * Le thème, le fond et les skins sont retenus pour la page de jeu ;
+ * l'adversaire reçoit un skin différent du nôtre.
+ *
+ * @param {Object} match - Partie trouvée (voir LobbyMatch côté serveur)
+ */
+function openMatch(match) {
+    const option = document.getElementById('lobbyDifficulty').selectedOptions[0];
+    const mySkin = preferredSkin || 'skin1';
+    const opponent = match.seat === 'player1' ? 'player2' : 'player1';
+
+    sessionStorage.clear();
+    sessionStorage.setItem('difficulty', match.difficulty);
+    sessionStorage.setItem('theme', option.dataset.theme);
+    sessionStorage.setItem('background', option.dataset.background);
+    sessionStorage.setItem(`${match.seat}Skin`, mySkin);
+    sessionStorage.setItem(`${opponent}Skin`, mySkin === 'skin2' ? 'skin1' : 'skin2');
+
+    showWaiting(true, `Adversaire trouvé : ${match.opponent} !`);
+    window.location.href = match.url;
+}
+
+/**
+ * Attend l'adversaire sur le WebSocket du ticket
+ */
+function waitForMatch() {
+    const protocol = window.location.protocol === 'https:' ? 'wss' : 'ws';
+    lobbySocket = new WebSocket(`${protocol}://${window.location.host}/api/lobby/ws?ticket=${lobbyTicket}`);
+
+    lobbySocket.onmessage = (message) => {
+        const event = JSON.parse(message.data);
+        switch (event.type) {
+            case 'match':
+                openMatch(event.match);
+                break;
+            case 'cancelled':
+                stopWaiting('Recherche relancée depuis un autre onglet');
+                break;
+        }
+    };
+
+    lobbySocket.onerror = (error) => {
+        console.error('Erreur WebSocket:', error);
+        stopWaiting('Connexion perdue, relancez la recherche');
+    };
+}
+
+/**
+ * Inscrit le joueur dans la file d'attente
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois l'inscription traitée
+ */
+async function joinLobby() {
+    const response = await fetch('/api/lobby/join', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({
+            difficulty: document.getElementById('lobbyDifficulty').value,
+            pseudo: document.getElementById('lobbyPseudo').value.trim(),
+            byRating: document.getElementById('byRating').checked
+        })
+    });
+    const json = await response.json();
+    if (!response.ok) {
+        document.getElementById('lobbyError').textContent = json.error || 'Erreur serveur';
+        return;
+    }
+    document.getElementById('lobbyError').textContent = '';
+
+    lobbyTicket = json.ticket;
+    if (json.match) {
+        openMatch(json.match);
+        return;
+    }
+    const rating = json.rating ? ` · Elo ${json.rating}` : '';
+    showWaiting(true, `Recherche d'un adversaire… (${json.waiting} en attente${rating})`);
+    waitForMatch();
+}
+
+/**
+ * Arrête l'attente et réaffiche le formulaire
+ *
+ * @param {string} [reason=''] - Message affiché sous le formulaire
+ */
+function stopWaiting(reason = '') {
+    if (lobbySocket) {
+        lobbySocket.onerror = null;
+        lobbySocket.close();
+        lobbySocket = null;
+    }
+    lobbyTicket = null;
+    showWaiting(false);
+    document.getElementById('lobbyError').textContent = reason;
+}
+
+/**
+ * Annule la recherche en cours
+ *
+ * @async
+ * @returns {Promise<void>} Promesse résolue une fois la recherche annulée
+ */
+async function leaveLobby() {
+    if (lobbyTicket) {
+        await fetch(`/api/lobby/leave?ticket=${lobbyTicket}`, { method: 'POST' });
+    }
+    stopWaiting();
+}
+
+//#endregion
+
+//#region DÉMARRAGE AUTOMATIQUE
+
+/**
+ * Charge le compte connecté (pseudo imposé, classement possible)
+ * et branche le formulaire
+ */
+document.addEventListener('DOMContentLoaded', async function() {
+    document.getElementById('lobbyDifficulty').addEventListener('change', applyTheme);
+    document.getElementById('lobbyForm').addEventListener('submit', function(event) {
+        event.preventDefault();
+        joinLobby();
+    });
+    document.getElementById('cancelButton').addEventListener('click', leaveLobby);
+    applyTheme();
+
+    const response = await fetch('/api/account/me');
+    if (response.ok) {
+        const profile = await response.json();
+        const pseudo = document.getElementById('lobbyPseudo');
+        pseudo.value = profile.username;
+        pseudo.disabled = true;
+        preferredSkin = profile.skin;
+        document.getElementById('byRatingLabel').style.display = 'inline';
+    }
+});
+
+//#endregion
@@ -16,8 +16,11 @@ if (!difficulty) {
     window.location.href = '/';
 }
 
-// Application du thème visuel correspondant à la difficulté
-document.body.className = difficulty;
+// Application du thème visuel et du fond correspondant à la difficulté
+document.body.className = sessionStorage.getItem('theme') || difficulty;
+if (sessionStorage.getItem('background')) {
+    document.body.style.setProperty('--map-background', `url('${sessionStorage.getItem('background')}')`);
+}
 
 //#endregion
 
@@ -41,6 +44,12 @@ let playerPseudos = {
     player2: ''   // Pseudo du joueur 2
 };
 
+/**
+ * Compte connecté (joueur 1)
+ * @type {Object|null} Profil renvoyé par /api/account/me (null si personne n'est connecté)
+ */
+let account = null;
+
 //#endregion
 
 //#region GESTION DES ÉVÉNEMENTS ET VALIDATION
@@ -58,10 +67,58 @@ document.addEventListener('DOMContentLoaded', function() {
     const player1PseudoInput = document.getElementById('player1Pseudo');
     const player2PseudoInput = document.getElementById('player2Pseudo');
     const pseudoError = document.getElementById('pseudoError');
+    const botEnabledInput = document.getElementById('botEnabled');
+    const botLevelSelect = document.getElementById('botLevel');
+    const onlineEnabledInput = document.getElementById('onlineEnabled');
+    const winLengthSelect = document.getElementById('winLength');
+    const gravityPresetSelect = document.getElementById('gravityPreset');
+    const seedInput = document.getElementById('seed');
+    const evenStartInput = document.getElementById('evenStart');
+    const timeControlSelect = document.getElementById('timeControl');
 
     //#endregion
 
-    //#region Gestion des pseudos
+    //#region Règle d'alignement
+
+    // Un alignement plus long que le plateau ne pourrait jamais être réalisé
+    const maxWinLength = Math.max(parseInt(sessionStorage.getItem('rows')), parseInt(sessionStorage.getItem('cols')));
+    winLengthSelect.querySelectorAll('option').forEach(option => {
+        option.disabled = parseInt(option.value) > maxWinLength;
+    });
+
+    //#endregion
+
+    //#region Compte connecté
+
+    /**
+     * Charge le compte connecté : son identifiant devient le pseudo du joueur 1
+     * et son jeton préféré est présélectionné
+     *
+     * @async
+     * @returns {Promise<void>} Promesse résolue une fois le compte appliqué
+     */
+    async function loadAccount() {
+        const response = await fetch('/api/account/me');
+        if (!response.ok) return;
+        account = await response.json();
+
+        player1PseudoInput.value = account.username;
+        player1PseudoInput.readOnly = true;
+        playerPseudos.player1 = account.username;
+        document.getElementById('accountStatus').textContent = `Connecté : ${account.username}`;
+
+        const preferred = document.querySelector(`#player1Skins .skin-option[data-skin="${account.skin}"]`);
+        if (preferred) {
+            preferred.click();
+        }
+        checkIfReady();
+    }
+
+    loadAccount();
+
+    //#endregion
+
+        //#region Gestion des pseudos
 
     /**
      * Gestionnaire de saisie pour le pseudo du joueur 1
@@ -81,6 +138,19 @@ document.addEventListener('DOMContentLoaded', function() {
         checkIfReady();
     });
 
+    /**
+     * Gestionnaire de la case "Contrôlé par l'ordinateur"
+     * Remplace le pseudo du joueur 2 par "Ordinateur" et active le choix du niveau
+     */
+    botEnabledInput.addEventListener('change', function() {
+        botLevelSelect.disabled = !this.checked;
+        onlineEnabledInput.disabled = this.checked;
+        player2PseudoInput.disabled = this.checked;
+        player2PseudoInput.value = this.checked ? 'Ordinateur' : '';
+        playerPseudos.player2 = player2PseudoInput.value;
+        checkIfReady();
+    });
+
     //#endregion
 
     //#region Gestion de la sélection des skins - Joueur 1
@@ -224,12 +294,57 @@ document.addEventListener('DOMContentLoaded', function() {
      * Gestionnaire du bouton de démarrage de la partie
      * Sauvegarde toutes les données dans sessionStorage et redirige vers la page de jeu
      */
-    startButton.addEventListener('click', function() {
+    startButton.addEventListener('click', async function() {
         // Sauvegarde tous les choix dans sessionStorage
         sessionStorage.setItem('player1Skin', selectedSkins.player1);
         sessionStorage.setItem('player2Skin', selectedSkins.player2);
         sessionStorage.setItem('player1Pseudo', playerPseudos.player1);
         sessionStorage.setItem('player2Pseudo', playerPseudos.player2);
+        sessionStorage.setItem('winLength', winLengthSelect.value);
+        sessionStorage.setItem('gravity', gravityPresetSelect.value);
+        sessionStorage.setItem('evenStart', evenStartInput.checked ? '1' : '0');
+
+        // Cadence des pendules (ou suppression pour une partie sans limite de temps)
+        if (timeControlSelect.value !== '') {
+            sessionStorage.setItem('timeControl', timeControlSelect.value);
+        } else {
+            sessionStorage.removeItem('timeControl');
+        }
+
+        // Graine imposée (ou suppression pour une disposition aléatoire)
+        if (seedInput.value !== '') {
+            sessionStorage.setItem('seed', seedInput.value);
+        } else {
+            sessionStorage.removeItem('seed');
+        }
+
+        // Sauvegarde de l'adversaire ordinateur (ou suppression s'il n'y en a pas)
+        if (botEnabledInput.checked) {
+            sessionStorage.setItem('bot', 'player2');
+            sessionStorage.setItem('botLevel', botLevelSelect.value);
+        } else {
+            sessionStorage.removeItem('bot');
+            sessionStorage.removeItem('botLevel');
+        }
+
+        // Partie en ligne : le joueur 2 rejoindra par le lien d'invitation
+        if (onlineEnabledInput.checked && !botEnabledInput.checked) {
+            sessionStorage.setItem('online', '1');
+        } else {
+            sessionStorage.removeItem('online');
+        }
+
+        // Compte connecté : le siège du joueur 1 lui est lié et son jeton retenu
+        if (account) {
+            sessionStorage.setItem('bindSeat', 'player1');
+            await fetch('/api/account/skin', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ skin: selectedSkins.player1 })
+            });
+        } else {
+            sessionStorage.removeItem('bindSeat');
+        }
 
         // Redirection vers la page de jeu
         window.location.href = '/game';
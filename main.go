@@ -19,6 +19,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 )
 
 //#region POINT D'ENTRÉE PRINCIPAL
@@ -26,7 +27,7 @@ import (
 // main est la fonction principale du serveur
 //
 // Cette fonction:
-// 1. Crée un gestionnaire de parties
+// 1. Crée un gestionnaire de parties (et recharge les parties enregistrées)
 // 2. Configure les routes pour les fichiers statiques
 // 3. Configure les routes pour les pages HTML
 // 4. Configure les routes de l'API
@@ -34,9 +35,53 @@ import (
 func main() {
 	//#region Initialisation
 
+	// Choix du stockage des parties via la variable d'environnement STORAGE
+	//   - memory (par défaut): parties perdues au redémarrage
+	//   - file: un fichier JSON par partie dans DATA_DIR/games
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = "data"
+	}
+	store, err := NewGameStore(os.Getenv("STORAGE"), dataDir)
+	if err != nil {
+		log.Fatal("Erreur de stockage: ", err)
+	}
+
+	// Chargement des préréglages de difficulté (variable d'environnement DIFFICULTIES)
+	difficultiesPath := os.Getenv("DIFFICULTIES")
+	if difficultiesPath == "" {
+		difficultiesPath = "config/difficulties.json"
+	}
+	difficulties, err := LoadDifficulties(difficultiesPath)
+	if err != nil {
+		log.Fatal("Erreur de chargement des difficultés: ", err)
+	}
+
+	// Comptes des joueurs, enregistrés comme les parties (DATA_DIR/users.json en stockage "file")
+	accounts, err := NewAccounts(os.Getenv("STORAGE"), dataDir)
+	if err != nil {
+		log.Fatal("Erreur de chargement des comptes: ", err)
+	}
+
 	// Création du gestionnaire de parties
-	// Il maintiendra l'état de la partie en cours
-	gameManager := NewGameManager()
+	// Il maintiendra l'état de toutes les parties en cours
+	gameManager := NewGameManager(store, difficulties, accounts)
+
+	// Durée maximale d'une analyse /api/analyze (variable d'environnement ANALYZE_MAX_TIME, ex. "30s")
+	if maxTime := os.Getenv("ANALYZE_MAX_TIME"); maxTime != "" {
+		d, err := time.ParseDuration(maxTime)
+		if err != nil || d <= 0 {
+			log.Fatal("ANALYZE_MAX_TIME invalide: ", maxTime)
+		}
+		gameManager.analyzeMaxTime = d
+	}
+
+	// Rechargement des parties enregistrées avant le redémarrage
+	loaded, err := gameManager.LoadGames()
+	if err != nil {
+		log.Fatal("Erreur de chargement des parties: ", err)
+	}
+	log.Printf(" %d partie(s) rechargée(s)\n", loaded)
 
 	//#endregion
 
@@ -104,32 +149,181 @@ func main() {
 		tmpl.Execute(w, nil)
 	})
 
+	// Page des comptes: inscription, connexion et déconnexion
+	// Route: GET /account
+	// Template: templates/account.html
+	http.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		tmpl, err := template.ParseFiles("templates/account.html")
+		if err != nil {
+			http.Error(w, "Erreur de chargement du template", http.StatusInternalServerError)
+			log.Println("Erreur template:", err)
+			return
+		}
+		tmpl.Execute(w, nil)
+	})
+
+	// Page du classement: meilleurs joueurs et statistiques par joueur
+	// Route: GET /leaderboard
+	// Template: templates/leaderboard.html (un onglet par préréglage classé)
+	http.HandleFunc("/leaderboard", func(w http.ResponseWriter, r *http.Request) {
+		tmpl, err := template.ParseFiles("templates/leaderboard.html")
+		if err != nil {
+			http.Error(w, "Erreur de chargement du template", http.StatusInternalServerError)
+			log.Println("Erreur template:", err)
+			return
+		}
+		tmpl.Execute(w, difficulties.List())
+	})
+
+	// Page de l'archive: recherche des parties terminées et ouverture en relecture
+	// Route: GET /archive
+	// Template: templates/archive.html (difficultés proposées dans les filtres)
+	http.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		tmpl, err := template.ParseFiles("templates/archive.html")
+		if err != nil {
+			http.Error(w, "Erreur de chargement du template", http.StatusInternalServerError)
+			log.Println("Erreur template:", err)
+			return
+		}
+		tmpl.Execute(w, difficulties.List())
+	})
+
+	// Page de la partie rapide: recherche d'un adversaire en ligne
+	// Route: GET /lobby
+	// Template: templates/lobby.html (difficultés proposées)
+	http.HandleFunc("/lobby", func(w http.ResponseWriter, r *http.Request) {
+		tmpl, err := template.ParseFiles("templates/lobby.html")
+		if err != nil {
+			http.Error(w, "Erreur de chargement du template", http.StatusInternalServerError)
+			log.Println("Erreur template:", err)
+			return
+		}
+		tmpl.Execute(w, difficulties.List())
+	})
+
 	//#endregion
 
 	//#region Configuration des routes - API REST
 
+	// API: Préréglages de difficulté
+	// Route: GET /api/difficulties
+	// Réponse: Liste des difficultés (dimensions, jetons pré-remplis, gravité, fond)
+	http.HandleFunc("/api/difficulties", gameManager.HandleDifficulties)
+
+	// API: Comptes des joueurs
+	// Routes: POST /api/account/register, POST /api/account/login, POST /api/account/logout
+	// Body: {username, password} (inscription et connexion)
+	// Réponse: Profil du compte (id, username, skin), cookie de session
+	http.HandleFunc("/api/account/register", accounts.HandleRegister)
+	http.HandleFunc("/api/account/login", accounts.HandleLogin)
+	http.HandleFunc("/api/account/logout", accounts.HandleLogout)
+
+	// API: Compte connecté et skin préféré
+	// Routes: GET /api/account/me, POST /api/account/skin
+	// Body: {skin} (skin préféré)
+	// Réponse: Profil du compte connecté (401 sans session)
+	http.HandleFunc("/api/account/me", accounts.HandleMe)
+	http.HandleFunc("/api/account/skin", accounts.HandleSkin)
+
+	// API: Classement Elo et statistiques d'un joueur
+	// Routes: GET /api/players/{id}/rating, GET /api/players/{id}/stats
+	// Réponse: Classement et évolution sur chaque préréglage (easy, normal, hard),
+	// ou bilan de toutes ses parties archivées par préréglage et par siège
+	http.HandleFunc("/api/players/", gameManager.HandlePlayers)
+
+	// API: Meilleurs joueurs d'un préréglage
+	// Route: GET /api/leaderboard?preset=easy&limit=20
+	// Réponse: Joueurs triés par classement décroissant
+	http.HandleFunc("/api/leaderboard", accounts.HandleLeaderboard)
+
+	// API: Archive des parties terminées
+	// Routes: GET /api/games?player=&difficulty=&result=&from=&to=&page=&pageSize=, GET /api/games/{id}
+	// Réponse: Page de parties archivées (résumés), ou partie archivée complète pour la relecture
+	http.HandleFunc("/api/games", gameManager.HandleArchive)
+	http.HandleFunc("/api/games/", gameManager.HandleArchivedGame)
+
+	// API: File d'attente des parties rapides en ligne
+	// Routes: POST /api/lobby/join, GET /api/lobby/status?ticket=, POST /api/lobby/leave?ticket=
+	// Body: {difficulty, pseudo, byRating} (inscription)
+	// Réponse: État du ticket, avec la partie créée ("match") une fois l'adversaire trouvé
+	http.HandleFunc("/api/lobby/join", gameManager.HandleLobbyJoin)
+	http.HandleFunc("/api/lobby/status", gameManager.HandleLobbyStatus)
+	http.HandleFunc("/api/lobby/leave", gameManager.HandleLobbyLeave)
+
+	// API: Attente d'un adversaire en temps réel
+	// Route: GET /api/lobby/ws?ticket=<ticket> (WebSocket)
+	// Messages: attente, partie trouvée
+	http.HandleFunc("/api/lobby/ws", gameManager.HandleLobbyWebSocket)
+
 	// API: Créer une nouvelle partie
 	// Route: POST /api/game/new
-	// Body: {rows, cols, player1, player2}
-	// Réponse: État initial de la partie
+	// Body: {difficulty | rows, cols, player1, player2, ...}
+	// Réponse: État initial de la partie (avec son identifiant "id")
 	http.HandleFunc("/api/game/new", gameManager.HandleNewGame)
 
 	// API: Placer un jeton
-	// Route: POST /api/game/drop
+	// Route: POST /api/game/drop?id=<identifiant>
 	// Body: {col}
 	// Réponse: Nouvel état de la partie
 	http.HandleFunc("/api/game/drop", gameManager.HandleDropPiece)
 
 	// API: Obtenir l'état actuel
-	// Route: GET /api/game/state
+	// Route: GET /api/game/state?id=<identifiant>
 	// Réponse: État actuel de la partie
 	http.HandleFunc("/api/game/state", gameManager.HandleGetState)
 
 	// API: Réinitialiser le jeu
-	// Route: POST /api/game/reset
+	// Route: POST /api/game/reset?id=<identifiant>
 	// Réponse: Message de confirmation
 	http.HandleFunc("/api/game/reset", gameManager.HandleReset)
 
+	// API: Annuler le dernier coup
+	// Route: POST /api/game/undo?id=<identifiant>
+	// Réponse: État de la partie après l'annulation
+	http.HandleFunc("/api/game/undo", gameManager.HandleUndo)
+
+	// API: Rétablir le dernier coup annulé
+	// Route: POST /api/game/redo?id=<identifiant>
+	// Réponse: État de la partie après le rétablissement
+	http.HandleFunc("/api/game/redo", gameManager.HandleRedo)
+
+	// API: Abandonner la partie
+	// Route: POST /api/game/resign?id=<identifiant>
+	// Réponse: État final de la partie (victoire de l'adversaire)
+	http.HandleFunc("/api/game/resign", gameManager.HandleResign)
+
+	// API: Proposer, accepter ou refuser la nulle
+	// Route: POST /api/game/draw?id=<identifiant>
+	// Body: {"action": "offer" | "accept" | "decline"}
+	// Réponse: Nouvel état de la partie (proposition en attente dans "drawOffer")
+	http.HandleFunc("/api/game/draw", gameManager.HandleDraw)
+
+	// API: Lier son siège au compte connecté (joueur qui rejoint une invitation)
+	// Route: POST /api/game/bind?id=<identifiant>
+	// Réponse: État de la partie (pseudo du siège et comptes liés)
+	http.HandleFunc("/api/game/bind", gameManager.HandleBindSeat)
+
+	// API: Coup conseillé au joueur dont c'est le tour
+	// Route: GET /api/game/hint?id=<identifiant>
+	// Réponse: Colonne conseillée et raison (gagne, bloque, double menace)
+	http.HandleFunc("/api/game/hint", gameManager.HandleHint)
+
+	// API: Relecture de la partie
+	// Route: GET /api/game/replay?id=<identifiant>
+	// Réponse: Jetons pré-remplis et liste des coups joués
+	http.HandleFunc("/api/game/replay", gameManager.HandleReplay)
+
+	// API: Analyse d'une position (solveur)
+	// Route: POST /api/analyze
+	// Body: {rows, cols, board, currentPlayer, inverseGravity, turnCount, gravity, ...}
+	// Réponse: Verdict de chaque colonne (victoire, défaite, nulle) et meilleur coup
+	http.HandleFunc("/api/analyze", gameManager.HandleAnalyze)
+
+	// API: Mises à jour en temps réel
+	// Route: GET /api/game/ws?id=<identifiant> (WebSocket)
+	// Messages: état, coups, inversions de gravité, fin de partie
+	http.HandleFunc("/api/game/ws", gameManager.HandleWebSocket)
+
 	//#endregion
 
 	//#region Démarrage du serveur
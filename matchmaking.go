@@ -0,0 +1,511 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//#region FILE D'ATTENTE DES PARTIES RAPIDES
+
+const (
+	// lobbyRatingWindow est l'écart de classement maximal entre deux joueurs
+	// qui demandent un adversaire de niveau proche
+	lobbyRatingWindow = 200
+
+	// lobbyIdleTimeout retire de la file un joueur en attente qui ne donne
+	// plus de nouvelles (ni WebSocket ouvert, ni /api/lobby/status)
+	lobbyIdleTimeout = 30 * time.Second
+
+	// lobbyMatchTTL est la durée de conservation d'un ticket apparié,
+	// le temps que le client récupère sa partie
+	lobbyMatchTTL = 5 * time.Minute
+)
+
+// lobbyTimeControl est la cadence des parties rapides : un joueur qui
+// abandonne la partie sans la quitter finit par la perdre au temps
+var lobbyTimeControl = TimeControl{Base: 300, Increment: 5}
+
+// LobbyMatch décrit la partie trouvée pour un ticket
+//
+// Il contient le jeton du siège du joueur : il n'est envoyé qu'au
+// détenteur du ticket.
+type LobbyMatch struct {
+	GameID     string `json:"gameId"`
+	Seat       string `json:"seat"`      // Siège du joueur ("player1" ou "player2")
+	SeatToken  string `json:"seatToken"` // Jeton secret de ce siège
+	Opponent   string `json:"opponent"`  // Pseudo de l'adversaire
+	Difficulty string `json:"difficulty"`
+	URL        string `json:"url"` // Page de la partie (/game?join=<id>&seat=<jeton>&as=<siège>)
+}
+
+// LobbyStatus est l'état d'un ticket renvoyé par les routes /api/lobby/*
+type LobbyStatus struct {
+	Ticket     string      `json:"ticket"`
+	Status     string      `json:"status"` // "waiting" ou "matched"
+	Difficulty string      `json:"difficulty"`
+	Pseudo     string      `json:"pseudo"`
+	Rating     int         `json:"rating,omitempty"` // Classement sur le format (comptes seulement)
+	Waiting    int         `json:"waiting"`          // Joueurs en attente sur cette difficulté
+	Match      *LobbyMatch `json:"match,omitempty"`
+}
+
+// lobbyTicket est l'inscription d'un joueur dans la file d'attente
+type lobbyTicket struct {
+	id         string      // Identifiant secret (il donne accès au jeton de siège)
+	difficulty string      // Préréglage demandé
+	pseudo     string      // Pseudo affiché (nom du compte s'il est connecté)
+	userID     string      // Compte connecté ("" pour un invité)
+	rating     int         // Classement du compte sur le format du préréglage
+	byRating   bool        // Adversaire de niveau proche exigé
+	lastSeen   time.Time   // Dernière nouvelle du client
+	connected  int         // WebSocket ouverts sur ce ticket
+	match      *LobbyMatch // Partie trouvée (nil tant qu'il attend)
+}
+
+// Lobby est la file d'attente des parties rapides en ligne
+//
+// Un joueur s'inscrit pour une difficulté ; dès qu'un adversaire
+// compatible est en attente, une partie est créée et les deux joueurs
+// sont prévenus (WebSocket /api/lobby/ws ou /api/lobby/status).
+type Lobby struct {
+	mu      sync.Mutex              // Protège les tickets et la file
+	tickets map[string]*lobbyTicket // Tickets en attente ou appariés, par identifiant
+	queue   []*lobbyTicket          // Tickets en attente, par ordre d'arrivée
+}
+
+// newLobby crée une file d'attente vide
+func newLobby() *Lobby {
+	return &Lobby{tickets: make(map[string]*lobbyTicket)}
+}
+
+// lobbyChannel retourne le canal de l'EventHub propre à un ticket
+func lobbyChannel(ticket string) string {
+	return "lobby:" + ticket
+}
+
+// status retourne l'état public d'un ticket
+//
+// L'appelant doit détenir l.mu.
+func (l *Lobby) status(t *lobbyTicket) LobbyStatus {
+	s := LobbyStatus{Ticket: t.id, Status: "waiting", Difficulty: t.difficulty, Pseudo: t.pseudo, Rating: t.rating, Match: t.match}
+	if t.match != nil {
+		s.Status = "matched"
+	}
+	for _, waiting := range l.queue {
+		if waiting.difficulty == t.difficulty {
+			s.Waiting++
+		}
+	}
+	return s
+}
+
+// remove retire un ticket de la file d'attente (il reste connu s'il est apparié)
+//
+// L'appelant doit détenir l.mu.
+func (l *Lobby) remove(t *lobbyTicket) {
+	for i, waiting := range l.queue {
+		if waiting == t {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			break
+		}
+	}
+	if t.match == nil {
+		delete(l.tickets, t.id)
+	}
+}
+
+// purge oublie les joueurs en attente silencieux et les tickets appariés trop anciens
+//
+// L'appelant doit détenir l.mu.
+func (l *Lobby) purge(now time.Time) {
+	for _, t := range l.tickets {
+		switch {
+		case t.match == nil && t.connected == 0 && now.Sub(t.lastSeen) > lobbyIdleTimeout:
+			l.remove(t)
+		case t.match != nil && now.Sub(t.lastSeen) > lobbyMatchTTL:
+			delete(l.tickets, t.id)
+		}
+	}
+}
+
+// compatible indique si deux joueurs en attente peuvent s'affronter
+//
+// Ils doivent demander la même difficulté et ne pas être le même compte ;
+// si l'un d'eux exige un adversaire de niveau proche, les deux doivent
+// être connectés avec des classements à moins de lobbyRatingWindow.
+func compatible(a, b *lobbyTicket) bool {
+	if a.difficulty != b.difficulty || (a.userID != "" && a.userID == b.userID) {
+		return false
+	}
+	if !a.byRating && !b.byRating {
+		return true
+	}
+	return a.userID != "" && b.userID != "" && abs(a.rating-b.rating) <= lobbyRatingWindow
+}
+
+// opponentFor cherche l'adversaire d'un nouveau ticket
+//
+// Le premier arrivé compatible est choisi ; avec byRating, c'est le plus
+// proche au classement (le premier arrivé en cas d'égalité).
+//
+// L'appelant doit détenir l.mu.
+func (l *Lobby) opponentFor(t *lobbyTicket) *lobbyTicket {
+	var best *lobbyTicket
+	for _, waiting := range l.queue {
+		if !compatible(t, waiting) {
+			continue
+		}
+		if !t.byRating {
+			return waiting
+		}
+		if best == nil || abs(t.rating-waiting.rating) < abs(t.rating-best.rating) {
+			best = waiting
+		}
+	}
+	return best
+}
+
+//#endregion
+
+//#region CRÉATION DES PARTIES APPARIÉES
+
+// startLobbyGame crée la partie de deux joueurs appariés et les prévient
+//
+// Le premier joueur est tiré au sort. Les sièges des joueurs connectés
+// sont liés à leur compte : entre deux comptes, la partie compte pour
+// le classement du préréglage.
+//
+// La partie se joue à la cadence lobbyTimeControl, et la pendule du
+// premier joueur démarre dès l'appariement : une partie que personne
+// ne rejoint se termine au temps au lieu de rester ouverte.
+//
+// L'appelant doit détenir gm.lobby.mu.
+//
+// Paramètres:
+//   - preset: préréglage de la partie
+//   - a, b: tickets appariés (retirés de la file en cas de succès)
+//
+// Retourne:
+//   - error: si la règle de gravité du préréglage est invalide
+func (gm *GameManager) startLobbyGame(preset Difficulty, a, b *lobbyTicket) error {
+	if rand.Intn(2) == 1 {
+		a, b = b, a
+	}
+
+	seed := newSeed()
+	gravity, err := ResolveGravityRule(preset.Gravity, seed)
+	if err != nil {
+		return err
+	}
+	winLength := preset.WinLength
+	if winLength == 0 {
+		winLength = DefaultWinLength
+	}
+
+	game := NewGameWithOptions(preset.Rows, preset.Cols, a.pseudo, b.pseudo, GameOptions{
+		WinLength:     winLength,
+		Prefilled:     preset.Prefilled,
+		Blockers:      preset.Blockers,
+		BlockerLayout: preset.BlockerLayout,
+		Gravity:       gravity,
+		Seed:          seed,
+		TimeControl:   lobbyTimeControl,
+	})
+	game.Difficulty = preset.Name
+	game.PresetRules = true
+	game.TurnStarted = time.Now()
+	users := make(map[string]string)
+	for seat, t := range map[string]*lobbyTicket{"player1": a, "player2": b} {
+		if t.userID != "" {
+			users[seat] = t.userID
+		}
+	}
+	if len(users) > 0 {
+		game.Users = users
+	}
+	game.assignSeats()
+	gm.addGame(game)
+	gm.commitGame(game)
+
+	now := time.Now()
+	for seat, pair := range map[string][2]*lobbyTicket{"player1": {a, b}, "player2": {b, a}} {
+		t, opponent := pair[0], pair[1]
+		token := game.SeatTokens[seat]
+		t.match = &LobbyMatch{
+			GameID:     game.ID,
+			Seat:       seat,
+			SeatToken:  token,
+			Opponent:   opponent.pseudo,
+			Difficulty: preset.Name,
+			URL:        "/game?join=" + game.ID + "&seat=" + token + "&as=" + seat,
+		}
+		t.lastSeen = now
+		gm.lobby.remove(t)
+		gm.hub.Publish(lobbyChannel(t.id), GameEvent{Type: "match", Match: t.match})
+	}
+	return nil
+}
+
+//#endregion
+
+//#region HANDLERS HTTP - FILE D'ATTENTE
+
+// lobbyTicketFromRequest récupère le ticket désigné par le paramètre "ticket"
+//
+// En cas d'erreur, la réponse (400 ou 404) est déjà envoyée.
+//
+// L'appelant doit détenir gm.lobby.mu.
+func (gm *GameManager) lobbyTicketFromRequest(w http.ResponseWriter, r *http.Request) *lobbyTicket {
+	id := r.URL.Query().Get("ticket")
+	if id == "" {
+		respondError(w, http.StatusBadRequest, "Ticket manquant")
+		return nil
+	}
+	t := gm.lobby.tickets[id]
+	if t == nil {
+		respondError(w, http.StatusNotFound, "Ticket introuvable (expiré ou annulé)")
+		return nil
+	}
+	return t
+}
+
+// HandleLobbyJoin inscrit un joueur dans la file d'attente d'une difficulté
+//
+// Route: POST /api/lobby/join
+// Body JSON attendu:
+//
+//	{
+//	  "difficulty": "easy", // préréglage (voir /api/difficulties)
+//	  "pseudo": "Alice",    // pseudo d'un invité (ignoré avec une session : nom du compte)
+//	  "byRating": true      // optionnel: adversaire à moins de 200 points de classement
+//	}
+//
+// Si un adversaire compatible attend déjà, la partie est créée
+// immédiatement ; sinon le client attend la partie sur /api/lobby/ws
+// (ou en interrogeant /api/lobby/status). Un compte n'a qu'un ticket en
+// attente : une nouvelle inscription remplace la précédente.
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request contenant la difficulté et le pseudo
+//
+// Réponse:
+//   - 200 OK: État du ticket (voir LobbyStatus), avec "match" si un adversaire a été trouvé
+//   - 400 Bad Request: Difficulté inconnue ou pseudo manquant
+//   - 401 Unauthorized: "byRating" sans session valide
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+//   - 500 Internal Server Error: Préréglage inutilisable
+func (gm *GameManager) HandleLobbyJoin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	var req struct {
+		Difficulty string `json:"difficulty"` // Préréglage demandé
+		Pseudo     string `json:"pseudo"`     // Pseudo d'un invité
+		ByRating   bool   `json:"byRating"`   // Adversaire de niveau proche exigé
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Format JSON invalide")
+		return
+	}
+
+	preset, ok := gm.difficulties.Get(req.Difficulty)
+	if !ok {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Difficulté inconnue: %q", req.Difficulty))
+		return
+	}
+
+	// Joueur connecté : son compte fournit le pseudo et le classement
+	t := &lobbyTicket{id: newSecret(16), difficulty: preset.Name, pseudo: strings.TrimSpace(req.Pseudo), byRating: req.ByRating, lastSeen: time.Now()}
+	if account, ok := gm.accounts.FromRequest(r); ok {
+		t.pseudo, t.userID = account.Username, account.ID
+		if _, ratings, ok := gm.accounts.Ratings(account.ID); ok {
+			t.rating = ratings[preset.Name].Rating
+		}
+	} else if req.ByRating {
+		respondError(w, http.StatusUnauthorized, errNotLoggedIn.Error())
+		return
+	}
+	if t.pseudo == "" {
+		respondError(w, http.StatusBadRequest, "Le pseudo est obligatoire")
+		return
+	}
+
+	gm.lobby.mu.Lock()
+	defer gm.lobby.mu.Unlock()
+	gm.lobby.purge(t.lastSeen)
+
+	// Une nouvelle inscription d'un compte remplace son ticket en attente
+	if t.userID != "" {
+		for _, waiting := range append([]*lobbyTicket{}, gm.lobby.queue...) {
+			if waiting.userID == t.userID {
+				gm.lobby.remove(waiting)
+				gm.hub.Publish(lobbyChannel(waiting.id), GameEvent{Type: "cancelled"})
+			}
+		}
+	}
+
+	gm.lobby.tickets[t.id] = t
+	if opponent := gm.lobby.opponentFor(t); opponent != nil {
+		if err := gm.startLobbyGame(preset, t, opponent); err != nil {
+			delete(gm.lobby.tickets, t.id)
+			log.Println("Erreur de création d'une partie rapide:", err)
+			respondError(w, http.StatusInternalServerError, "Préréglage inutilisable")
+			return
+		}
+	} else {
+		gm.lobby.queue = append(gm.lobby.queue, t)
+	}
+	respondJSON(w, http.StatusOK, gm.lobby.status(t))
+}
+
+// HandleLobbyStatus retourne l'état d'un ticket
+//
+// Route: GET /api/lobby/status?ticket=<ticket>
+//
+// Pour les clients sans WebSocket : interroger cette route au moins
+// toutes les 30 secondes garde le joueur dans la file.
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request contenant le ticket
+//
+// Réponse:
+//   - 200 OK: État du ticket, avec "match" une fois l'adversaire trouvé
+//   - 400 Bad Request: Ticket manquant
+//   - 404 Not Found: Ticket inconnu, expiré ou annulé
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleLobbyStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	gm.lobby.mu.Lock()
+	defer gm.lobby.mu.Unlock()
+	gm.lobby.purge(time.Now())
+	t := gm.lobbyTicketFromRequest(w, r)
+	if t == nil {
+		return
+	}
+	if t.match == nil {
+		t.lastSeen = time.Now()
+	}
+	respondJSON(w, http.StatusOK, gm.lobby.status(t))
+}
+
+// HandleLobbyLeave retire un joueur de la file d'attente
+//
+// Route: POST /api/lobby/leave?ticket=<ticket>
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request contenant le ticket
+//
+// Réponse:
+//   - 200 OK: Message de confirmation
+//   - 400 Bad Request: Ticket manquant
+//   - 404 Not Found: Ticket inconnu, expiré ou déjà annulé
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+//   - 409 Conflict: Adversaire déjà trouvé (la partie est créée)
+func (gm *GameManager) HandleLobbyLeave(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	gm.lobby.mu.Lock()
+	defer gm.lobby.mu.Unlock()
+	t := gm.lobbyTicketFromRequest(w, r)
+	if t == nil {
+		return
+	}
+	if t.match != nil {
+		respondError(w, http.StatusConflict, "Adversaire déjà trouvé")
+		return
+	}
+	gm.lobby.remove(t)
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Recherche annulée"})
+}
+
+// HandleLobbyWebSocket prévient un joueur en attente dès que sa partie est créée
+//
+// Route: GET /api/lobby/ws?ticket=<ticket> (connexion WebSocket)
+//
+// Le client reçoit immédiatement "waiting" ou "match" (partie déjà
+// trouvée), puis "match" une fois apparié, ou "cancelled" si le même
+// compte s'est inscrit ailleurs. Fermer la connexion pendant l'attente
+// retire le joueur de la file.
+//
+// Paramètres:
+//   - w: ResponseWriter (détourné pour la connexion WebSocket)
+//   - r: Request de mise à niveau WebSocket
+//
+// Réponse:
+//   - 101 Switching Protocols: connexion établie
+//   - 400 Bad Request: Requête non WebSocket ou ticket manquant
+//   - 403 Forbidden: Connexion depuis une autre origine
+//   - 404 Not Found: Ticket inconnu, expiré ou annulé
+func (gm *GameManager) HandleLobbyWebSocket(w http.ResponseWriter, r *http.Request) {
+	gm.lobby.mu.Lock()
+	t := gm.lobbyTicketFromRequest(w, r)
+	gm.lobby.mu.Unlock()
+	if t == nil {
+		return
+	}
+
+	// La poignée de main se fait hors du verrou : un client lent ne doit
+	// pas bloquer toute la file d'attente
+	conn, rw, err := wsUpgrade(w, r)
+	if errors.Is(err, errCrossOrigin) {
+		respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	client := &wsClient{conn: conn, rw: rw, send: make(chan []byte, 4)}
+	go client.writeLoop()
+
+	// Abonnement puis envoi de l'état courant, sous le verrou de la file :
+	// un appariement ne peut pas se glisser entre les deux. Le ticket a pu
+	// être annulé ou oublié pendant la poignée de main.
+	channel := lobbyChannel(t.id)
+	gm.lobby.mu.Lock()
+	if gm.lobby.tickets[t.id] != t {
+		gm.lobby.mu.Unlock()
+		close(client.send) // writeLoop ferme proprement la connexion
+		return
+	}
+	gm.hub.subscribe(channel, client)
+	event := GameEvent{Type: "waiting"}
+	if t.match != nil {
+		event = GameEvent{Type: "match", Match: t.match}
+	}
+	gm.hub.sendTo(channel, client, event)
+	t.connected++
+	gm.lobby.mu.Unlock()
+
+	// Lecture jusqu'à la déconnexion du client
+	client.readLoop(func() {
+		gm.hub.unsubscribe(channel, client)
+		gm.lobby.mu.Lock()
+		defer gm.lobby.mu.Unlock()
+		t.connected--
+		if t.match == nil && t.connected == 0 {
+			gm.lobby.remove(t)
+		}
+	})
+}
+
+//#endregion
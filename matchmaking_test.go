@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//#region OUTILS DE TEST
+
+// joinLobby inscrit un joueur via HandleLobbyJoin (cookie de session facultatif)
+func joinLobby(t *testing.T, gm *GameManager, body string, cookie *http.Cookie) (int, LobbyStatus) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/lobby/join", strings.NewReader(body))
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	gm.HandleLobbyJoin(rec, req)
+	var status LobbyStatus
+	json.NewDecoder(rec.Body).Decode(&status)
+	return rec.Code, status
+}
+
+// lobbyStatus interroge HandleLobbyStatus pour un ticket
+func lobbyStatus(gm *GameManager, ticket string) (int, LobbyStatus) {
+	rec := httptest.NewRecorder()
+	gm.HandleLobbyStatus(rec, httptest.NewRequest("GET", "/api/lobby/status?ticket="+ticket, nil))
+	var status LobbyStatus
+	json.NewDecoder(rec.Body).Decode(&status)
+	return rec.Code, status
+}
+
+//#endregion
+
+//#region TESTS DE LA FILE D'ATTENTE
+
+// TestLobbyPairsPlayers vérifie l'appariement de deux invités et la partie créée
+func TestLobbyPairsPlayers(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+
+	code, first := joinLobby(t, gm, `{"difficulty":"easy","pseudo":" Alice "}`, nil)
+	if code != http.StatusOK || first.Status != "waiting" || first.Waiting != 1 || first.Pseudo != "Alice" || first.Match != nil {
+		t.Fatalf("premier joueur: %d %+v", code, first)
+	}
+
+	// Une autre difficulté ne convient pas
+	if _, other := joinLobby(t, gm, `{"difficulty":"hard","pseudo":"Carol"}`, nil); other.Status != "waiting" {
+		t.Fatalf("difficulté différente appariée: %+v", other)
+	}
+
+	code, second := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Bob"}`, nil)
+	if code != http.StatusOK || second.Status != "matched" || second.Match == nil || second.Waiting != 0 {
+		t.Fatalf("second joueur: %d %+v", code, second)
+	}
+	_, first = lobbyStatus(gm, first.Ticket)
+	if first.Status != "matched" || first.Match == nil || first.Match.GameID != second.Match.GameID {
+		t.Fatalf("premier joueur après l'appariement: %+v", first)
+	}
+
+	game := gm.getGame(second.Match.GameID)
+	if game == nil || game.Difficulty != "easy" || game.Rows != 6 || game.Cols != 7 || game.Users != nil {
+		t.Fatalf("partie créée: %+v", game)
+	}
+	if first.Match.Seat == second.Match.Seat || first.Match.Opponent != "Bob" || second.Match.Opponent != "Alice" {
+		t.Fatalf("sièges: %+v / %+v", first.Match, second.Match)
+	}
+	for pseudo, match := range map[string]*LobbyMatch{"Alice": first.Match, "Bob": second.Match} {
+		if game.SeatTokens[match.Seat] != match.SeatToken || game.GetState()[match.Seat] != pseudo ||
+			match.URL != "/game?join="+game.ID+"&seat="+match.SeatToken+"&as="+match.Seat {
+			t.Errorf("%s: %+v", pseudo, match)
+		}
+	}
+
+	// Le premier joueur est tiré au sort
+	seats := map[string]bool{}
+	for i := 0; i < 40 && len(seats) < 2; i++ {
+		joinLobby(t, gm, `{"difficulty":"normal","pseudo":"Dan"}`, nil)
+		_, s := joinLobby(t, gm, `{"difficulty":"normal","pseudo":"Eve"}`, nil)
+		seats[s.Match.Seat] = true
+	}
+	if len(seats) != 2 {
+		t.Error("le second inscrit a toujours le même siège")
+	}
+
+	for body, want := range map[string]int{
+		`{"difficulty":"extreme","pseudo":"Alice"}`:              http.StatusBadRequest,
+		`{"difficulty":"easy","pseudo":"  "}`:                    http.StatusBadRequest,
+		`{"difficulty":"easy","pseudo":"Alice","byRating":true}`: http.StatusUnauthorized,
+		`{`: http.StatusBadRequest,
+	} {
+		if code, _ := joinLobby(t, gm, body, nil); code != want {
+			t.Errorf("%s: %d, attendu %d", body, code, want)
+		}
+	}
+}
+
+// TestLobbyAccounts vérifie les parties classées, l'écart de classement
+// et le remplacement du ticket d'un compte
+func TestLobbyAccounts(t *testing.T) {
+	accounts := newAccounts()
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), accounts)
+	alice, bob := loginCookie(t, accounts, "alice"), loginCookie(t, accounts, "bob")
+	carol, dave := loginCookie(t, accounts, "carol"), loginCookie(t, accounts, "dave")
+	profile, _ := accounts.FromRequest(func() *http.Request {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.AddCookie(dave)
+		return r
+	}())
+	accounts.users[profile.ID].Ratings = map[string]*Rating{"easy": {Rating: 1800}}
+
+	// Une nouvelle inscription du même compte remplace la précédente
+	_, old := joinLobby(t, gm, `{"difficulty":"easy","byRating":true}`, alice)
+	_, waiting := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"ignoré","byRating":true}`, alice)
+	if code, _ := lobbyStatus(gm, old.Ticket); code != http.StatusNotFound {
+		t.Fatalf("ancien ticket: %d", code)
+	}
+	if waiting.Status != "waiting" || waiting.Pseudo != "alice" || waiting.Rating != initialRating || waiting.Waiting != 1 {
+		t.Fatalf("ticket d'alice: %+v", waiting)
+	}
+
+	// Un invité, ou un compte trop loin au classement, ne convient pas à alice
+	if _, guest := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Invité"}`, nil); guest.Status != "waiting" {
+		t.Fatalf("invité apparié à une recherche par classement: %+v", guest)
+	}
+	if _, strong := joinLobby(t, gm, `{"difficulty":"easy","byRating":true}`, dave); strong.Status != "waiting" || strong.Rating != 1800 {
+		t.Fatalf("écart de classement ignoré: %+v", strong)
+	}
+
+	// Sans l'exiger, carol est appariée au premier arrivé qui l'accepte :
+	// alice, de même classement
+	_, s := joinLobby(t, gm, `{"difficulty":"easy"}`, carol)
+	if s.Match == nil || s.Match.Opponent != "alice" {
+		t.Fatalf("carol: %+v", s)
+	}
+	game := gm.getGame(s.Match.GameID)
+	game.mu.Lock()
+	ranked := game.ranked()
+	game.mu.Unlock()
+	if !ranked || len(game.Users) != 2 {
+		t.Fatalf("partie entre deux comptes non classée: %+v", game.Users)
+	}
+
+	// bob ne trouve personne à son niveau ; un second invité prend le premier
+	if _, s := joinLobby(t, gm, `{"difficulty":"easy","byRating":true}`, bob); s.Status != "waiting" || s.Waiting != 3 {
+		t.Fatalf("bob: %+v", s)
+	}
+	if _, s := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Invité 2"}`, nil); s.Match == nil || s.Match.Opponent != "Invité" {
+		t.Fatalf("second invité: %+v", s)
+	}
+}
+
+// TestLobbyLeave vérifie l'annulation et l'oubli des joueurs silencieux
+func TestLobbyLeave(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	leave := func(ticket string) int {
+		rec := httptest.NewRecorder()
+		gm.HandleLobbyLeave(rec, httptest.NewRequest("POST", "/api/lobby/leave?ticket="+ticket, nil))
+		return rec.Code
+	}
+
+	_, alice := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Alice"}`, nil)
+	if code := leave(alice.Ticket); code != http.StatusOK {
+		t.Fatalf("annulation: %d", code)
+	}
+	if code := leave(alice.Ticket); code != http.StatusNotFound {
+		t.Fatalf("double annulation: %d", code)
+	}
+	if _, bob := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Bob"}`, nil); bob.Status != "waiting" {
+		t.Fatalf("apparié à un joueur parti: %+v", bob)
+	}
+
+	// Un joueur en attente sans nouvelles depuis trop longtemps est oublié
+	gm.lobby.mu.Lock()
+	for _, ticket := range gm.lobby.queue {
+		ticket.lastSeen = time.Now().Add(-2 * lobbyIdleTimeout)
+	}
+	gm.lobby.mu.Unlock()
+	_, carol := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Carol"}`, nil)
+	if carol.Status != "waiting" || carol.Waiting != 1 {
+		t.Fatalf("apparié à un joueur silencieux: %+v", carol)
+	}
+
+	// Une fois apparié, il est trop tard pour annuler
+	joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Dan"}`, nil)
+	if code := leave(carol.Ticket); code != http.StatusConflict {
+		t.Fatalf("annulation après l'appariement: %d", code)
+	}
+	if code := leave(""); code != http.StatusBadRequest {
+		t.Fatalf("ticket manquant: %d", code)
+	}
+}
+
+// TestLobbyWebSocket vérifie que le joueur en attente est prévenu en temps réel
+// et qu'il quitte la file en fermant la connexion
+func TestLobbyWebSocket(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/lobby/ws", gm.HandleLobbyWebSocket)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	_, alice := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Alice"}`, nil)
+	conn, r := dialWebSocket(t, server, "/api/lobby/ws?ticket="+alice.Ticket)
+	if event := readEvent(t, conn, r); event.Type != "waiting" {
+		t.Fatalf("premier événement: %+v", event)
+	}
+
+	_, bob := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Bob"}`, nil)
+	event := readEvent(t, conn, r)
+	if event.Type != "match" || event.Match == nil || event.Match.GameID != bob.Match.GameID || event.Match.Opponent != "Bob" {
+		t.Fatalf("partie trouvée: %+v", event)
+	}
+
+	// Fermer la connexion pendant l'attente retire le joueur de la file
+	_, carol := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Carol"}`, nil)
+	conn, r = dialWebSocket(t, server, "/api/lobby/ws?ticket="+carol.Ticket)
+	readEvent(t, conn, r)
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if code, _ := lobbyStatus(gm, carol.Ticket); code == http.StatusNotFound {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("ticket conservé après la déconnexion")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp, err := http.Get(server.URL + "/api/lobby/ws?ticket=inconnu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("ticket inconnu: %d", resp.StatusCode)
+	}
+
+	// Une connexion depuis une autre origine est refusée sans toucher au ticket
+	_, dan := joinLobby(t, gm, `{"difficulty":"normal","pseudo":"Dan"}`, nil)
+	req := httptest.NewRequest("GET", "/api/lobby/ws?ticket="+dan.Ticket, nil)
+	req.Host = "localhost:8080"
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Origin", "http://evil.example")
+	rec := httptest.NewRecorder()
+	gm.HandleLobbyWebSocket(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("autre origine: %d, attendu 403", rec.Code)
+	}
+	if _, s := lobbyStatus(gm, dan.Ticket); s.Status != "waiting" {
+		t.Fatalf("ticket après un refus: %+v", s)
+	}
+}
+
+// TestLobbyGameIsTimed vérifie qu'une partie rapide abandonnée se termine au temps
+func TestLobbyGameIsTimed(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Alice"}`, nil)
+	_, bob := joinLobby(t, gm, `{"difficulty":"easy","pseudo":"Bob"}`, nil)
+	game := gm.getGame(bob.Match.GameID)
+
+	// La pendule du premier joueur tourne dès l'appariement, surveillée par le serveur
+	game.mu.Lock()
+	running, watched := game.clockRunning(), game.clockTimer != nil
+	game.TurnStarted = time.Now().Add(-lobbyTimeControl.initial() - time.Second)
+	first := game.CurrentPlayer
+	game.mu.Unlock()
+	if game.TimeControl != lobbyTimeControl || !running || !watched {
+		t.Fatalf("pendules de la partie rapide: %+v, en marche %v, surveillées %v", game.TimeControl, running, watched)
+	}
+
+	// Personne n'a rejoint la partie : le premier joueur la perd au temps
+	if !game.CheckTime() {
+		t.Fatal("partie abandonnée toujours ouverte")
+	}
+	if state := game.GetState(); state["winner"] != opponentOf(first) || state["reason"] != ReasonTimeout {
+		t.Fatalf("fin de partie: %v %v", state["winner"], state["reason"])
+	}
+}
+
+//#endregion
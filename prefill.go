@@ -0,0 +1,233 @@
+package main
+
+//#region PARAMÈTRES DU PRÉ-REMPLISSAGE
+
+const (
+	// prefillAttempts est le nombre de dispositions tirées avant de retirer un jeton
+	prefillAttempts = 200
+
+	// evenStartDepth est la profondeur (en demi-coups) de l'évaluation d'équilibre
+	evenStartDepth = 4
+
+	// evenStartMargin est l'écart de score toléré pour une disposition équilibrée
+	// (échelle de evaluate : 1, 4 puis 16 points par fenêtre selon son remplissage)
+	evenStartMargin = 12
+)
+
+//#endregion
+
+//#region OBSTACLES NEUTRES
+
+// addBlockers pose les obstacles neutres au démarrage, avant les jetons pré-remplis
+//
+// Les cases de layout sont murées telles quelles ; les count obstacles
+// suivants tombent au hasard en respectant la gravité, comme des jetons.
+// Un tirage qui ne laisse plus aucun alignement possible est refait ; si
+// aucun ne convient après prefillAttempts essais, le nombre d'obstacles
+// aléatoires est réduit d'un.
+//
+// Paramètres:
+//   - layout: cases imposées (déjà validées par validateBlockers)
+//   - count: nombre d'obstacles supplémentaires placés au hasard
+func (g *Game) addBlockers(layout []Move, count int) {
+	for _, m := range layout {
+		g.Board.Set(m.Row, m.Col, BlockedCell)
+		g.Prefilled = append(g.Prefilled, Token{Row: m.Row, Col: m.Col, Player: BlockedCell})
+	}
+
+	for ; count > 0; count-- {
+		for attempt := 0; attempt < prefillAttempts; attempt++ {
+			owners := make([]string, count)
+			for i := range owners {
+				owners[i] = BlockedCell
+			}
+			tokens := g.dropTokens(owners)
+			if g.Board.CanAlign("player1", g.WinLength) && g.Board.CanAlign("player2", g.WinLength) {
+				g.Prefilled = append(g.Prefilled, tokens...)
+				return
+			}
+			for _, t := range tokens {
+				g.Board.Clear(t.Row, t.Col)
+			}
+		}
+	}
+}
+
+//#endregion
+
+//#region GÉNÉRATION DE LA DISPOSITION INITIALE
+
+// addPrefilledBlocks ajoute des jetons au plateau au démarrage
+//
+// Les jetons sont placés aléatoirement en respectant la gravité, puis la
+// disposition n'est gardée que si elle est équitable (voir isFairStart).
+// Sinon une autre disposition est tirée ; si aucune ne convient après
+// prefillAttempts essais, le nombre de jetons est réduit d'un.
+//
+// Tout le hasard provient du générateur de la partie : une même graine
+// donne toujours la même disposition.
+//
+// Paramètres:
+//   - count: nombre de jetons à placer
+//   - even: true pour n'accepter que des dispositions jugées équilibrées par l'ordinateur
+func (g *Game) addPrefilledBlocks(count int, even bool) {
+	for ; count > 0; count-- {
+		for attempt := 0; attempt < prefillAttempts; attempt++ {
+			tokens := g.drawPrefill(count)
+			if g.isFairStart(even) {
+				g.Prefilled = append(g.Prefilled, tokens...)
+				return
+			}
+			for _, t := range tokens {
+				g.Board.Clear(t.Row, t.Col)
+			}
+		}
+	}
+}
+
+// drawPrefill place une disposition aléatoire de jetons sur le plateau
+//
+// Les jetons sont répartis équitablement entre les deux joueurs ; avec un
+// nombre impair, le jeton supplémentaire revient au joueur 2 pour
+// compenser l'avantage du joueur 1, qui commence.
+//
+// Paramètres:
+//   - count: nombre de jetons à placer
+//
+// Retourne:
+//   - []Token: jetons placés, dans l'ordre de pose
+func (g *Game) drawPrefill(count int) []Token {
+	owners := make([]string, count)
+	for i := range owners {
+		owners[i] = "player1"
+		if i >= count/2 {
+			owners[i] = "player2"
+		}
+	}
+	g.random().Shuffle(len(owners), func(i, j int) { owners[i], owners[j] = owners[j], owners[i] })
+	return g.dropTokens(owners)
+}
+
+// dropTokens lâche des jetons dans des colonnes tirées au hasard
+//
+// Paramètres:
+//   - owners: contenu de chaque jeton, dans l'ordre de pose
+//
+// Retourne:
+//   - []Token: jetons placés (moins que demandé si le plateau est plein)
+func (g *Game) dropTokens(owners []string) []Token {
+	tokens := make([]Token, 0, len(owners))
+	for _, player := range owners {
+		if g.Board.IsFull() {
+			break
+		}
+
+		// Sélection d'une colonne aléatoire qui n'est pas pleine
+		col := g.random().Intn(g.Cols)
+		for g.Board.ColumnFull(col) {
+			col = g.random().Intn(g.Cols)
+		}
+
+		// Le jeton tombe au fond de la colonne (gravité normale au démarrage)
+		row := g.Board.LandingRow(col, false)
+		g.Board.Set(row, col, player)
+		tokens = append(tokens, Token{Row: row, Col: col, Player: player})
+	}
+	return tokens
+}
+
+//#endregion
+
+//#region CRITÈRES D'ÉQUITÉ
+
+// isFairStart vérifie que la disposition actuelle ne favorise aucun joueur
+//
+// Une disposition est refusée si :
+//   - un alignement de WinLength jetons existe déjà
+//   - un joueur a déjà WinLength-1 jetons alignés qu'une case vide
+//     compléterait, même si elle n'est pas encore jouable (une menace
+//     toute prête, et a fortiori une victoire dès le premier coup)
+//   - (si even) l'ordinateur estime qu'un joueur a un avantage net
+//
+// Paramètres:
+//   - even: true pour exiger en plus une évaluation équilibrée
+//
+// Retourne:
+//   - bool: true si la disposition peut être proposée aux joueurs
+func (g *Game) isFairStart(even bool) bool {
+	// Aucun alignement déjà formé
+	for row := 0; row < g.Rows; row++ {
+		for col := 0; col < g.Cols; col++ {
+			if g.Board.WinsThrough(row, col, g.WinLength) {
+				return false
+			}
+		}
+	}
+
+	// Aucune menace toute prête, pour l'un ou l'autre joueur
+	for _, player := range []string{"player1", "player2"} {
+		if g.hasOpenThreat(player) {
+			return false
+		}
+	}
+
+	if !even {
+		return true
+	}
+
+	// Évaluation par l'ordinateur du point de vue du joueur 1, qui commence
+	score := g.negamax(evenStartDepth, -botWinScore*2, botWinScore*2)
+	return abs(score) <= evenStartMargin
+}
+
+// hasOpenThreat indique si un joueur n'est qu'à un jeton d'un alignement
+//
+// Toutes les cases vides sont essayées, jouables ou non : une menace
+// encore hors d'atteinte finira par le devenir.
+//
+// Paramètres:
+//   - player: joueur dont les alignements sont cherchés
+//
+// Retourne:
+//   - bool: true si une case vide compléterait un alignement de WinLength jetons
+func (g *Game) hasOpenThreat(player string) bool {
+	for row := 0; row < g.Rows; row++ {
+		for col := 0; col < g.Cols; col++ {
+			if g.Board.Cell(row, col) != "" {
+				continue
+			}
+			g.Board.Set(row, col, player)
+			wins := g.Board.WinsThrough(row, col, g.WinLength)
+			g.Board.Clear(row, col)
+			if wins {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasImmediateWin indique si un joueur gagnerait en jouant maintenant l'une des colonnes
+//
+// Paramètres:
+//   - player: joueur pour lequel les coups sont essayés
+//
+// Retourne:
+//   - bool: true si au moins un coup est immédiatement gagnant
+func (g *Game) hasImmediateWin(player string) bool {
+	for col := 0; col < g.Cols; col++ {
+		row := g.Board.LandingRow(col, g.InverseGravity)
+		if row == -1 {
+			continue
+		}
+		g.Board.Set(row, col, player)
+		wins := g.Board.WinsThrough(row, col, g.WinLength)
+		g.Board.Clear(row, col)
+		if wins {
+			return true
+		}
+	}
+	return false
+}
+
+//#endregion
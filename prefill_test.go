@@ -0,0 +1,161 @@
+package main
+
+import (
+	"testing"
+)
+
+//#region OUTILS DE TEST
+
+// openLine cherche une fenêtre de winLength cases où un joueur a tous les
+// jetons sauf un, la dernière case étant vide (jouable ou non)
+//
+// Le parcours des fenêtres est indépendant de Bitboard.WinsThrough.
+func openLine(g *Game, player string, winLength int) ([]Move, bool) {
+	for _, dir := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+		for row := 0; row < g.Rows; row++ {
+			for col := 0; col < g.Cols; col++ {
+				endRow, endCol := row+dir[0]*(winLength-1), col+dir[1]*(winLength-1)
+				if endRow < 0 || endRow >= g.Rows || endCol < 0 || endCol >= g.Cols {
+					continue
+				}
+				window, mine, empty := []Move{}, 0, 0
+				for i := 0; i < winLength; i++ {
+					r, c := row+dir[0]*i, col+dir[1]*i
+					window = append(window, Move{Row: r, Col: c})
+					switch g.Board.Cell(r, c) {
+					case player:
+						mine++
+					case "":
+						empty++
+					}
+				}
+				if mine == winLength-1 && empty == 1 {
+					return window, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+//#endregion
+
+//#region TESTS D'ÉQUITÉ DU PRÉ-REMPLISSAGE
+
+// TestPrefillIsFair vérifie sur de nombreuses graines que les dispositions
+// initiales sont équilibrées et sans menace immédiate
+func TestPrefillIsFair(t *testing.T) {
+	// Dimensions et jetons pré-remplis des difficultés Facile, Normal et Difficile
+	for _, size := range [][3]int{{6, 7, 3}, {6, 9, 5}, {7, 8, 7}} {
+		for _, winLength := range []int{3, 4} {
+			for seed := int64(0); seed < 300; seed++ {
+				g := NewGameWithOptions(size[0], size[1], "Alice", "Bob", GameOptions{WinLength: winLength, Prefilled: size[2], Seed: seed})
+
+				// Répartition équitable (le jeton impair revient au joueur 2)
+				p1, p2 := countTokens(g)
+				if p1+p2 != len(g.Prefilled) || p2-p1 < 0 || p2-p1 > 1 {
+					t.Fatalf("%v graine %d: %d jetons pour player1, %d pour player2", size, seed, p1, p2)
+				}
+
+				// Aucun alignement déjà formé
+				for _, tok := range g.Prefilled {
+					if g.Board.WinsThrough(tok.Row, tok.Col, winLength) {
+						t.Fatalf("%v graine %d: alignement de %d déjà formé en %v", size, seed, winLength, tok)
+					}
+				}
+
+				// Aucune victoire en un coup, ni menace toute prête même hors d'atteinte
+				for _, player := range []string{"player1", "player2"} {
+					if g.hasImmediateWin(player) {
+						t.Fatalf("%v graine %d: %s peut gagner dès son premier coup", size, seed, player)
+					}
+					if line, ok := openLine(g, player, winLength); ok {
+						t.Fatalf("%v graine %d: %s n'est qu'à un jeton d'un alignement: %v", size, seed, player, line)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestPrefillKeepsTokenCount vérifie que les critères d'équité ne réduisent
+// pas le nombre de jetons de la difficulté dans les cas usuels
+func TestPrefillKeepsTokenCount(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		g := NewGameWithOptions(7, 8, "Alice", "Bob", GameOptions{Prefilled: 7, Seed: seed, EvenStart: true})
+		if len(g.Prefilled) != 7 {
+			t.Fatalf("graine %d: %d jetons pré-remplis, attendu 7", seed, len(g.Prefilled))
+		}
+	}
+}
+
+// TestEvenStart vérifie que l'ordinateur juge équilibrées les dispositions
+// obtenues avec l'option EvenStart
+func TestEvenStart(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		g := NewGameWithOptions(7, 8, "Alice", "Bob", GameOptions{Prefilled: 7, Seed: seed, EvenStart: true})
+		if score := g.negamax(evenStartDepth, -botWinScore*2, botWinScore*2); abs(score) > evenStartMargin {
+			t.Fatalf("graine %d: score %d hors de la marge %d", seed, score, evenStartMargin)
+		}
+	}
+}
+
+//#endregion
+
+//#region TESTS DES OBSTACLES NEUTRES
+
+// TestBlockers vérifie la pose des obstacles imposés et aléatoires, et
+// qu'une même graine donne les mêmes obstacles
+func TestBlockers(t *testing.T) {
+	layout := []Move{{Row: 0, Col: 0}, {Row: 3, Col: 4}}
+	for seed := int64(0); seed < 20; seed++ {
+		opts := GameOptions{Prefilled: 7, Blockers: 3, BlockerLayout: layout, Seed: seed}
+		a := NewGameWithOptions(7, 8, "Alice", "Bob", opts)
+		b := NewGameWithOptions(7, 8, "Alice", "Bob", opts)
+
+		if n := a.Board.Count(BlockedCell); n != 5 {
+			t.Fatalf("graine %d: %d obstacles, attendu 5", seed, n)
+		}
+		for _, m := range layout {
+			if a.Board.Cell(m.Row, m.Col) != BlockedCell {
+				t.Fatalf("graine %d: obstacle imposé absent en %v", seed, m)
+			}
+		}
+		if p1, p2 := countTokens(a); p1+p2 != 7 {
+			t.Fatalf("graine %d: %d jetons pré-remplis, attendu 7", seed, p1+p2)
+		}
+		if len(a.Prefilled) != 12 || len(b.Prefilled) != 12 {
+			t.Fatalf("graine %d: %d cases pré-remplies enregistrées, attendu 12", seed, len(a.Prefilled))
+		}
+		for i := range a.Prefilled {
+			if a.Prefilled[i] != b.Prefilled[i] {
+				t.Fatalf("graine %d: case %d différente (%v, %v)", seed, i, a.Prefilled[i], b.Prefilled[i])
+			}
+		}
+	}
+}
+
+// TestReplayRestoresBlockers vérifie que les obstacles font partie de la
+// disposition initiale relue et survivent à la sauvegarde
+func TestReplayRestoresBlockers(t *testing.T) {
+	g := NewGameWithOptions(6, 7, "Alice", "Bob", GameOptions{Blockers: 4, Seed: 1})
+	blocked := 0
+	for _, tok := range g.GetReplay().Prefilled {
+		if tok.Player == BlockedCell {
+			blocked++
+		}
+	}
+	if blocked != 4 {
+		t.Fatalf("%d obstacles dans la relecture, attendu 4", blocked)
+	}
+
+	loaded, err := GameFromRecord(g.Record())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Board.Count(BlockedCell) != 4 {
+		t.Fatalf("%d obstacles après rechargement, attendu 4", loaded.Board.Count(BlockedCell))
+	}
+}
+
+//#endregion
@@ -0,0 +1,426 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//#region CLASSEMENT ELO
+
+const (
+	// initialRating est le classement d'un compte qui n'a encore joué aucune partie classée
+	initialRating = 1500
+
+	// provisionalGames est le nombre de parties pendant lesquelles le classement évolue vite
+	provisionalGames = 30
+
+	// provisionalK et establishedK sont les coefficients K de la formule Elo,
+	// avant et après provisionalGames parties sur le préréglage
+	provisionalK = 40
+	establishedK = 20
+
+	// defaultLeaderboardSize et maxLeaderboardSize bornent le paramètre "limit" du classement
+	defaultLeaderboardSize = 20
+	maxLeaderboardSize     = 100
+)
+
+// Rating est le classement d'un compte sur un préréglage de difficulté
+//
+// Chaque préréglage a son propre classement : gagner en "normal" ne dit
+// rien du niveau en "easy".
+type Rating struct {
+	Rating  int            `json:"rating"`  // Classement Elo actuel
+	Games   int            `json:"games"`   // Parties classées jouées
+	Wins    int            `json:"wins"`    // Victoires
+	Losses  int            `json:"losses"`  // Défaites
+	Draws   int            `json:"draws"`   // Nulles
+	History []RatingChange `json:"history"` // Évolution, de la plus ancienne partie à la plus récente
+}
+
+// RatingChange est l'effet d'une partie classée sur le classement d'un joueur
+type RatingChange struct {
+	GameID     string    `json:"gameId"`     // Partie jouée
+	Opponent   string    `json:"opponent"`   // Compte de l'adversaire
+	Seat       string    `json:"seat"`       // Siège occupé ("player1" joue en premier)
+	Difficulty string    `json:"difficulty"` // Préréglage de la partie ("" pour un plateau personnalisé)
+	Result     string    `json:"result"`     // ResultWin, ResultLoss ou ResultDraw (mêmes valeurs que le solveur)
+	TurnCount  int       `json:"turnCount"`  // Nombre de coups de la partie
+	Before     int       `json:"before"`     // Classement avant la partie
+	After      int       `json:"after"`      // Classement après la partie
+	At         time.Time `json:"at"`         // Fin de la partie
+}
+
+// RatedGame est une partie terminée à prendre en compte dans le classement
+type RatedGame struct {
+	GameID     string            // Identifiant de la partie
+	Difficulty string            // Préréglage de difficulté (classement concerné)
+	Users      map[string]string // Compte de chaque siège
+	Winner     string            // "player1", "player2" ou "draw"
+	TurnCount  int               // Nombre de coups joués
+}
+
+// boardPreset retourne le format d'un plateau ("6x7" pour 6 lignes et 7 colonnes)
+func boardPreset(rows, cols int) string {
+	return fmt.Sprintf("%dx%d", rows, cols)
+}
+
+// expectedScore retourne le score attendu (0 à 1) d'un joueur classé rating contre opponent
+func expectedScore(rating, opponent int) float64 {
+	return 1 / (1 + math.Pow(10, float64(opponent-rating)/400))
+}
+
+// eloChange calcule la variation de classement d'un joueur après une partie
+//
+// Paramètres:
+//   - rating: classement du joueur avant la partie
+//   - opponent: classement de l'adversaire avant la partie
+//   - score: 1 pour une victoire, 0.5 pour une nulle, 0 pour une défaite
+//   - games: parties classées déjà jouées (coefficient K plus fort au début)
+//
+// Retourne:
+//   - int: points gagnés (ou perdus si négatif)
+func eloChange(rating, opponent int, score float64, games int) int {
+	k := float64(establishedK)
+	if games < provisionalGames {
+		k = provisionalK
+	}
+	return int(math.Round(k * (score - expectedScore(rating, opponent))))
+}
+
+// ranked indique si la partie compte pour le classement
+//
+// Une partie est classée quand les deux sièges sont liés à deux comptes
+// différents, sans ordinateur, sur un préréglage de difficulté dont les
+// règles n'ont pas été modifiées (PresetRules) : un plateau personnalisé
+// aux mêmes dimensions ne compte pas. Elle ne peut alors ni être annulée
+// ni rétablie : le résultat enregistré est définitif.
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) ranked() bool {
+	p1, p2 := g.Users["player1"], g.Users["player2"]
+	return p1 != "" && p2 != "" && p1 != p2 && g.Bot == "" && g.Difficulty != "" && g.PresetRules
+}
+
+// applyRating met à jour le classement des deux comptes d'une partie classée terminée
+//
+// Le résultat n'est compté qu'une fois (RatingApplied est enregistré
+// avec la partie). Une erreur est signalée dans les logs sans être
+// réessayée : un compte supprimé ne doit pas bloquer la partie.
+//
+// L'appelant doit détenir game.mu.
+func (gm *GameManager) applyRating(game *Game) {
+	if !game.GameOver || game.RatingApplied || !game.ranked() {
+		return
+	}
+	game.RatingApplied = true
+	err := gm.accounts.RecordGame(RatedGame{
+		GameID:     game.ID,
+		Difficulty: game.Difficulty,
+		Users:      game.Users,
+		Winner:     game.Winner,
+		TurnCount:  game.TurnCount,
+	})
+	if err != nil {
+		log.Printf("Erreur de classement de la partie %s: %v", game.ID, err)
+	}
+}
+
+//#endregion
+
+//#region CLASSEMENT DES COMPTES
+
+// setRatedPresets fixe les préréglages classés : un classement par
+// difficulté du registre, dans l'ordre du fichier
+//
+// Paramètres:
+//   - difficulties: préréglages proposés aux joueurs
+func (a *Accounts) setRatedPresets(difficulties *DifficultyRegistry) {
+	names := []string{}
+	for _, d := range difficulties.List() {
+		names = append(names, d.Name)
+	}
+	a.mu.Lock()
+	a.presets = names
+	a.mu.Unlock()
+}
+
+// RatedPresets retourne les noms des préréglages classés
+func (a *Accounts) RatedPresets() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]string{}, a.presets...)
+}
+
+// rating retourne le classement d'un compte sur un préréglage (créé au besoin)
+//
+// L'appelant doit détenir a.mu en écriture.
+func (u *User) rating(preset string) *Rating {
+	if u.Ratings == nil {
+		u.Ratings = make(map[string]*Rating)
+	}
+	if u.Ratings[preset] == nil {
+		u.Ratings[preset] = &Rating{Rating: initialRating, History: []RatingChange{}}
+	}
+	return u.Ratings[preset]
+}
+
+// RecordGame met à jour le classement des deux joueurs d'une partie terminée
+//
+// Les deux variations sont calculées à partir des classements d'avant
+// la partie, puis appliquées ensemble.
+//
+// Paramètres:
+//   - game: partie terminée (sièges liés à deux comptes)
+//
+// Retourne:
+//   - error: compte introuvable ou erreur d'écriture (les classements
+//     restent alors à jour en mémoire)
+func (a *Accounts) RecordGame(game RatedGame) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seats := [2]string{"player1", "player2"}
+	var users [2]*User
+	for i, seat := range seats {
+		users[i] = a.users[game.Users[seat]]
+		if users[i] == nil {
+			return fmt.Errorf("compte introuvable pour %s", seat)
+		}
+	}
+
+	ratings := [2]*Rating{users[0].rating(game.Difficulty), users[1].rating(game.Difficulty)}
+	before := [2]int{ratings[0].Rating, ratings[1].Rating}
+	now := time.Now()
+	for i, seat := range seats {
+		other := 1 - i
+		result, score := ResultDraw, 0.5
+		switch game.Winner {
+		case seat:
+			result, score = ResultWin, 1
+		case seats[other]:
+			result, score = ResultLoss, 0
+		}
+
+		r := ratings[i]
+		r.Rating += eloChange(before[i], before[other], score, r.Games)
+		r.Games++
+		switch result {
+		case ResultWin:
+			r.Wins++
+		case ResultLoss:
+			r.Losses++
+		default:
+			r.Draws++
+		}
+		r.History = append(r.History, RatingChange{
+			GameID:     game.GameID,
+			Opponent:   users[other].ID,
+			Seat:       seat,
+			Difficulty: game.Difficulty,
+			Result:     result,
+			TurnCount:  game.TurnCount,
+			Before:     before[i],
+			After:      r.Rating,
+			At:         now,
+		})
+	}
+	return a.save()
+}
+
+// Ratings retourne le classement d'un compte sur chaque préréglage classé
+//
+// Un préréglage sans partie jouée apparaît avec le classement initial.
+//
+// Retourne:
+//   - Profile: le compte
+//   - map[string]Rating: classement par préréglage ("easy", etc.)
+//   - bool: false si le compte n'existe pas
+func (a *Accounts) Ratings(id string) (Profile, map[string]Rating, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	user, ok := a.users[id]
+	if !ok {
+		return Profile{}, nil, false
+	}
+
+	ratings := make(map[string]Rating, len(a.presets))
+	for _, preset := range a.presets {
+		r := Rating{Rating: initialRating, History: []RatingChange{}}
+		if stored := user.Ratings[preset]; stored != nil {
+			r = *stored
+			r.History = append([]RatingChange{}, stored.History...)
+		}
+		ratings[preset] = r
+	}
+	return user.profile(), ratings, true
+}
+
+// rankedGames retourne les parties comptées dans le classement d'un compte
+//
+// Retourne:
+//   - map[string]bool: identifiants des parties classées (vide si le compte n'existe pas)
+func (a *Accounts) rankedGames(id string) map[string]bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	games := make(map[string]bool)
+	if user, ok := a.users[id]; ok {
+		for _, rating := range user.Ratings {
+			for _, change := range rating.History {
+				games[change.GameID] = true
+			}
+		}
+	}
+	return games
+}
+
+// LeaderboardEntry est une ligne du classement d'un préréglage
+type LeaderboardEntry struct {
+	Rank     int    `json:"rank"`
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Rating   int    `json:"rating"`
+	Games    int    `json:"games"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Draws    int    `json:"draws"`
+}
+
+// Leaderboard retourne les meilleurs comptes d'un préréglage
+//
+// Seuls les comptes ayant joué au moins une partie classée sur le préréglage
+// apparaissent. À classement égal, le plus de parties jouées passe devant.
+//
+// Paramètres:
+//   - preset: nom du préréglage de difficulté ("easy", etc.)
+//   - limit: nombre maximal de lignes
+//
+// Retourne:
+//   - []LeaderboardEntry: lignes triées par classement décroissant
+func (a *Accounts) Leaderboard(preset string, limit int) []LeaderboardEntry {
+	a.mu.RLock()
+	entries := []LeaderboardEntry{}
+	for _, user := range a.users {
+		r := user.Ratings[preset]
+		if r == nil || r.Games == 0 {
+			continue
+		}
+		entries = append(entries, LeaderboardEntry{
+			ID: user.ID, Username: user.Username, Rating: r.Rating,
+			Games: r.Games, Wins: r.Wins, Losses: r.Losses, Draws: r.Draws,
+		})
+	}
+	a.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Rating != entries[j].Rating {
+			return entries[i].Rating > entries[j].Rating
+		}
+		if entries[i].Games != entries[j].Games {
+			return entries[i].Games > entries[j].Games
+		}
+		return strings.ToLower(entries[i].Username) < strings.ToLower(entries[j].Username)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries
+}
+
+//#endregion
+
+//#region HANDLERS HTTP - CLASSEMENT
+
+// HandlePlayerRating retourne le classement d'un compte et son évolution
+//
+// Route: GET /api/players/{id}/rating
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request dont le chemin contient l'identifiant du compte
+//
+// Réponse:
+//   - 200 OK: {"player": profil, "ratings": {"easy": {rating, games, wins, losses, draws, history}, ...}}
+//   - 404 Not Found: Compte introuvable ou chemin inconnu
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (a *Accounts) HandlePlayerRating(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	id, ok := playerID(r.URL.Path, "rating")
+	if !ok {
+		respondError(w, http.StatusNotFound, "Route inconnue")
+		return
+	}
+
+	profile, ratings, ok := a.Ratings(id)
+	if !ok {
+		respondError(w, http.StatusNotFound, "Joueur introuvable")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"player":  profile,
+		"ratings": ratings,
+	})
+}
+
+// HandleLeaderboard retourne les meilleurs joueurs d'un préréglage
+//
+// Route: GET /api/leaderboard?preset=easy&limit=20
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request avec le préréglage (le premier du fichier par défaut) et le
+//     nombre de lignes (defaultLeaderboardSize par défaut, maxLeaderboardSize au plus)
+//
+// Réponse:
+//   - 200 OK: {"preset": "easy", "players": [{rank, id, username, rating, games, ...}]}
+//   - 400 Bad Request: Préréglage non classé ou limite invalide
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (a *Accounts) HandleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	presets := a.RatedPresets()
+	preset := r.URL.Query().Get("preset")
+	if preset == "" && len(presets) > 0 {
+		preset = presets[0]
+	}
+	rated := false
+	for _, p := range presets {
+		rated = rated || p == preset
+	}
+	if !rated {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("préréglage non classé: %q (attendu: %s)", preset, strings.Join(presets, ", ")))
+		return
+	}
+
+	limit := defaultLeaderboardSize
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxLeaderboardSize {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("limit doit être compris entre 1 et %d", maxLeaderboardSize))
+			return
+		}
+		limit = n
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"preset":  preset,
+		"players": a.Leaderboard(preset, limit),
+	})
+}
+
+//#endregion
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+//#region OUTILS DE TEST
+
+// newPresetGame crée une partie sur un préréglage de difficulté via HandleNewGame
+//
+// extra complète le corps de la requête (",\"winLength\":5" par exemple).
+func newPresetGame(t *testing.T, gm *GameManager, difficulty, extra string) string {
+	t.Helper()
+	body := fmt.Sprintf(`{"difficulty":%q,"player1":"Alice","player2":"Bob"%s}`, difficulty, extra)
+	rec := httptest.NewRecorder()
+	gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new", strings.NewReader(body)))
+	id, _ := decodeState(t, rec)["id"].(string)
+	if rec.Code != http.StatusOK || id == "" {
+		t.Fatalf("création de partie %s: %d %s", difficulty, rec.Code, rec.Body.String())
+	}
+	return id
+}
+
+// newRatedGame crée une partie sur un préréglage dont les deux sièges sont
+// liés à deux nouveaux comptes
+func newRatedGame(t *testing.T, gm *GameManager, difficulty string) (id string, p1, p2 Profile) {
+	t.Helper()
+	p1, _ = gm.accounts.Register("alice", "motdepasse")
+	p2, _ = gm.accounts.Register("bob", "motdepasse")
+	id = newPresetGame(t, gm, difficulty, "")
+	gm.getGame(id).Users = map[string]string{"player1": p1.ID, "player2": p2.ID}
+	return id, p1, p2
+}
+
+//#endregion
+
+//#region TESTS DU CLASSEMENT
+
+// TestEloChange vérifie la formule Elo et le coefficient K provisoire
+func TestEloChange(t *testing.T) {
+	for _, tc := range []struct {
+		rating, opponent int
+		score            float64
+		games, want      int
+	}{
+		{1500, 1500, 1, 0, 20},                    // Provisoire : K = 40
+		{1500, 1500, 1, provisionalGames, 10},     // Établi : K = 20
+		{1500, 1500, 0.5, 0, 0},                   // Nulle entre égaux
+		{1900, 1500, 1, provisionalGames, 2},      // Victoire attendue : peu de points
+		{1500, 1900, 0.5, provisionalGames, 8},    // Nulle contre plus fort
+		{1500, 1900, 0, provisionalGames + 1, -2}, // Défaite attendue
+	} {
+		if got := eloChange(tc.rating, tc.opponent, tc.score, tc.games); got != tc.want {
+			t.Errorf("eloChange(%d, %d, %v, %d) = %d, attendu %d", tc.rating, tc.opponent, tc.score, tc.games, got, tc.want)
+		}
+	}
+}
+
+// TestRatedGame vérifie la mise à jour du classement en fin de partie classée
+func TestRatedGame(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id, alice, bob := newRatedGame(t, gm, "easy")
+	gm.getGame(id).DropPiece(3)
+
+	rec := httptest.NewRecorder()
+	gm.HandleResign(rec, seatRequest(gm, "POST", id, "player2", "/api/game/resign", ""))
+	state := decodeState(t, rec)
+	if state["rated"] != true || state["canUndo"] != false || gm.getGame(id).RatingApplied != true {
+		t.Fatalf("partie classée: %v", state)
+	}
+
+	// Un nouvel enregistrement de la partie ne compte pas le résultat deux fois
+	gm.commitGame(gm.getGame(id))
+
+	_, ratings, _ := gm.accounts.Ratings(alice.ID)
+	won := ratings["easy"]
+	if won.Rating != 1520 || won.Games != 1 || won.Wins != 1 || len(won.History) != 1 {
+		t.Fatalf("classement du gagnant: %+v", won)
+	}
+	if change := won.History[0]; change.GameID != id || change.Opponent != bob.ID || change.Seat != "player1" ||
+		change.Result != ResultWin || change.Before != 1500 || change.After != 1520 || change.TurnCount != 1 {
+		t.Fatalf("historique du gagnant: %+v", change)
+	}
+	_, ratings, _ = gm.accounts.Ratings(bob.ID)
+	if lost := ratings["easy"]; lost.Rating != 1480 || lost.Losses != 1 {
+		t.Fatalf("classement du perdant: %+v", lost)
+	}
+	if other := ratings["normal"]; other.Rating != initialRating || other.Games != 0 {
+		t.Fatalf("autre préréglage modifié: %+v", other)
+	}
+	if len(ratings) != len(gm.difficulties.List()) {
+		t.Fatalf("un classement par préréglage du registre attendu: %v", ratings)
+	}
+}
+
+// TestRankedReset vérifie que supprimer une partie classée en cours revient à l'abandonner
+func TestRankedReset(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id, alice, bob := newRatedGame(t, gm, "easy")
+	gm.getGame(id).DropPiece(3)
+
+	rec := httptest.NewRecorder()
+	gm.HandleReset(rec, seatRequest(gm, "POST", id, "player1", "/api/game/reset", ""))
+	if rec.Code != http.StatusOK || gm.getGame(id) != nil {
+		t.Fatalf("suppression: %d %s", rec.Code, rec.Body.String())
+	}
+	_, ratings, _ := gm.accounts.Ratings(alice.ID)
+	if lost := ratings["easy"]; lost.Losses != 1 || lost.Rating != 1480 {
+		t.Fatalf("classement de celui qui supprime: %+v", lost)
+	}
+	_, ratings, _ = gm.accounts.Ratings(bob.ID)
+	if won := ratings["easy"]; won.Wins != 1 || won.Rating != 1520 {
+		t.Fatalf("classement de l'adversaire: %+v", won)
+	}
+	if archived, ok := gm.archive.Get(id); !ok || archived.Winner != "player2" || archived.Reason != ReasonResignation {
+		t.Fatalf("partie archivée: %+v", archived)
+	}
+
+	// Une partie classée déjà terminée est supprimée sans nouveau résultat
+	id = newPresetGame(t, gm, "easy", "")
+	gm.getGame(id).Users = map[string]string{"player1": alice.ID, "player2": bob.ID}
+	gm.HandleResign(httptest.NewRecorder(), seatRequest(gm, "POST", id, "player2", "/api/game/resign", ""))
+	gm.HandleReset(httptest.NewRecorder(), seatRequest(gm, "POST", id, "player2", "/api/game/reset", ""))
+	_, ratings, _ = gm.accounts.Ratings(bob.ID)
+	if r := ratings["easy"]; r.Games != 2 || r.Wins != 1 || r.Losses != 1 {
+		t.Fatalf("classement après une partie terminée: %+v", r)
+	}
+}
+
+// TestBindSeat vérifie la liaison du siège de l'invité à son compte
+func TestBindSeat(t *testing.T) {
+	accounts := newAccounts()
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), accounts)
+	alice, bob := loginCookie(t, accounts, "alice"), loginCookie(t, accounts, "bob")
+
+	newGame := func() string {
+		req := httptest.NewRequest("POST", "/api/game/new",
+			strings.NewReader(`{"difficulty":"easy","player2":"Invité","bindSeat":"player1"}`))
+		req.AddCookie(alice)
+		rec := httptest.NewRecorder()
+		gm.HandleNewGame(rec, req)
+		id, _ := decodeState(t, rec)["id"].(string)
+		return id
+	}
+	bind := func(id, seat string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := seatRequest(gm, "POST", id, seat, "/api/game/bind", "")
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		gm.HandleBindSeat(rec, req)
+		return rec
+	}
+
+	id := newGame()
+	if rec := bind(id, "player2", nil); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("liaison sans session: %d", rec.Code)
+	}
+	if rec := bind(id, "player2", alice); rec.Code != http.StatusBadRequest {
+		t.Fatalf("un compte sur les deux sièges: %d", rec.Code)
+	}
+	rec := bind(id, "player2", bob)
+	state := decodeState(t, rec)
+	if rec.Code != http.StatusOK || state["player2"] != "bob" || state["rated"] != true {
+		t.Fatalf("liaison de bob: %d %v", rec.Code, state)
+	}
+	if rec := bind(id, "player2", bob); rec.Code != http.StatusOK {
+		t.Fatalf("nouvelle liaison du même compte: %d", rec.Code)
+	}
+	if rec := bind(id, "inconnu", bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("jeton invalide: %d", rec.Code)
+	}
+
+	// Trop tard une fois la partie commencée
+	id = newGame()
+	gm.getGame(id).DropPiece(3)
+	if rec := bind(id, "player2", bob); rec.Code != http.StatusBadRequest || gm.getGame(id).Users["player2"] != "" {
+		t.Fatalf("liaison après le premier coup: %d", rec.Code)
+	}
+}
+
+// TestUnratedGames vérifie les parties qui ne comptent pas pour le classement
+func TestUnratedGames(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+
+	alice, _ := gm.accounts.Register("alice", "motdepasse")
+	bob, _ := gm.accounts.Register("bob", "motdepasse")
+	users := map[string]string{"player1": alice.ID, "player2": bob.ID}
+
+	// Préréglage joué avec une règle modifiée
+	var ids []string
+	for _, extra := range []string{`,"winLength":5`, `,"gravity":{"preset":"off"}`} {
+		id := newPresetGame(t, gm, "easy", extra)
+		gm.getGame(id).Users = users
+		ids = append(ids, id)
+	}
+
+	// Plateau personnalisé aux dimensions d'un préréglage
+	id := newTestGame(t, gm, 6, 7)
+	gm.getGame(id).Users = users
+	game := gm.getGame(id)
+	game.DropPiece(0)
+	if game.GetState()["canUndo"] != true {
+		t.Fatal("l'annulation doit rester possible hors classement")
+	}
+	ids = append(ids, id)
+
+	// Un seul siège lié à un compte
+	id = newPresetGame(t, gm, "easy", "")
+	gm.getGame(id).Users = map[string]string{"player1": alice.ID}
+	ids = append(ids, id)
+
+	// Le préréglage sans modification reste classé, même en répétant ses règles
+	if id := newPresetGame(t, gm, "easy", `,"winLength":4,"gravity":{"preset":"classic"}`); !gm.getGame(id).PresetRules {
+		t.Fatal("règles identiques au préréglage considérées comme modifiées")
+	}
+
+	for _, id := range ids {
+		rec := httptest.NewRecorder()
+		gm.HandleResign(rec, seatRequest(gm, "POST", id, "player2", "/api/game/resign", ""))
+		if rec.Code != http.StatusOK || gm.getGame(id).RatingApplied {
+			t.Fatalf("partie %s: %d, comptée %v", id, rec.Code, gm.getGame(id).RatingApplied)
+		}
+	}
+	if entries := gm.accounts.Leaderboard("easy", 10); len(entries) != 0 {
+		t.Fatalf("classement non vide: %+v", entries)
+	}
+}
+
+// TestRatingHandlers vérifie les routes du classement d'un joueur et des meilleurs joueurs
+func TestRatingHandlers(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id, alice, bob := newRatedGame(t, gm, "normal")
+	gm.getGame(id).DropPiece(0)
+	gm.HandleResign(httptest.NewRecorder(), seatRequest(gm, "POST", id, "player1", "/api/game/resign", ""))
+
+	get := func(handler http.HandlerFunc, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	rec := get(gm.accounts.HandlePlayerRating, "/api/players/"+bob.ID+"/rating")
+	state := decodeState(t, rec)
+	ratings, _ := state["ratings"].(map[string]interface{})
+	normal, _ := ratings["normal"].(map[string]interface{})
+	if rec.Code != http.StatusOK || normal["rating"] != float64(1520) || normal["wins"] != float64(1) {
+		t.Fatalf("classement de bob: %d %v", rec.Code, state)
+	}
+	for _, path := range []string{"/api/players/inconnu/rating", "/api/players/" + bob.ID, "/api/players/" + bob.ID + "/x/rating"} {
+		if rec := get(gm.accounts.HandlePlayerRating, path); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: %d", path, rec.Code)
+		}
+	}
+
+	rec = get(gm.accounts.HandleLeaderboard, "/api/leaderboard?preset=normal")
+	players, _ := decodeState(t, rec)["players"].([]interface{})
+	if len(players) != 2 || players[0].(map[string]interface{})["id"] != bob.ID ||
+		players[1].(map[string]interface{})["id"] != alice.ID || players[1].(map[string]interface{})["rank"] != float64(2) {
+		t.Fatalf("meilleurs joueurs: %v", players)
+	}
+	rec = get(gm.accounts.HandleLeaderboard, "/api/leaderboard")
+	if state := decodeState(t, rec); state["preset"] != "easy" {
+		t.Fatalf("préréglage par défaut: %v", state)
+	}
+	for _, path := range []string{"/api/leaderboard?preset=6x9", "/api/leaderboard?limit=0", "/api/leaderboard?limit=abc"} {
+		if rec := get(gm.accounts.HandleLeaderboard, path); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: %d", path, rec.Code)
+		}
+	}
+}
+
+//#endregion
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+//#region FIN DE PARTIE HORS DU PLATEAU
+
+// Actions de la proposition de nulle
+const (
+	DrawActionOffer   = "offer"   // Proposer la nulle à l'adversaire
+	DrawActionAccept  = "accept"  // Accepter la nulle proposée par l'adversaire
+	DrawActionDecline = "decline" // Refuser la proposition (ou retirer la sienne)
+)
+
+// endGame termine la partie sans coup joué (abandon, nulle acceptée, temps écoulé)
+//
+// La pendule du joueur actuel est arrêtée avec le temps effectivement
+// consommé et la proposition de nulle en attente est effacée.
+//
+// Paramètres:
+//   - winner: "player1", "player2" ou "draw"
+//   - reason: cause de la fin de partie (ReasonResignation, etc.)
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) endGame(winner, reason string) {
+	if g.clockRunning() {
+		g.Clocks[g.CurrentPlayer] = g.remaining(g.CurrentPlayer, time.Now())
+		g.TurnStarted = time.Time{}
+	}
+	g.GameOver = true
+	g.Winner = winner
+	g.Reason = reason
+	g.DrawOffer = ""
+}
+
+// conceded indique si la partie s'est terminée hors du plateau
+//
+// Ces fins sont définitives : ni l'annulation ni le rétablissement
+// ne peuvent les remettre en cause.
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) conceded() bool {
+	return g.Reason == ReasonResignation || g.Reason == ReasonAgreedDraw || g.Reason == ReasonTimeout
+}
+
+// ResignAs fait abandonner le joueur détenteur d'un jeton de siège
+//
+// L'abandon est possible à tout moment, même pendant le tour adverse :
+// l'adversaire est déclaré vainqueur.
+//
+// Paramètres:
+//   - token: jeton de siège présenté par le client
+//
+// Retourne:
+//   - error: errInvalidSeat, errTimeUp si la pendule du joueur actuel vient
+//     de tomber (la partie est alors terminée au temps), ou une erreur si la
+//     partie est déjà terminée
+func (g *Game) ResignAs(token string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seat := g.seatOf(token)
+	if seat == "" {
+		return errInvalidSeat
+	}
+	if g.checkTime(time.Now()) {
+		return errTimeUp
+	}
+	if g.GameOver {
+		return errors.New("la partie est terminée")
+	}
+	g.endGame(opponentOf(seat), ReasonResignation)
+	return nil
+}
+
+// LeaveRanked fait abandonner le joueur qui supprime une partie classée en cours
+//
+// Supprimer la partie ne doit pas permettre d'échapper au classement :
+// le joueur qui la quitte la perd par abandon (ou au temps, si sa
+// pendule est déjà tombée). Une partie non classée ou terminée n'est
+// pas modifiée.
+//
+// Paramètres:
+//   - token: jeton de siège du joueur qui supprime la partie (déjà vérifié)
+//
+// Retourne:
+//   - bool: true si la partie vient de se terminer
+func (g *Game) LeaveRanked(token string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.GameOver || !g.ranked() {
+		return false
+	}
+	if !g.checkTime(time.Now()) {
+		g.endGame(opponentOf(g.seatOf(token)), ReasonResignation)
+	}
+	return true
+}
+
+// DrawAs applique une action de proposition de nulle pour le détenteur d'un jeton de siège
+//
+// Une proposition reste valable jusqu'à ce que l'adversaire l'accepte ou
+// la refuse, ou jusqu'au coup suivant. Contre l'ordinateur, la réponse
+// est immédiate : il accepte si sa position ne lui est pas favorable.
+//
+// Paramètres:
+//   - token: jeton de siège présenté par le client
+//   - action: DrawActionOffer, DrawActionAccept ou DrawActionDecline
+//
+// Retourne:
+//   - error: errInvalidSeat, errTimeUp si la pendule du joueur actuel vient
+//     de tomber (la partie est alors terminée au temps), ou une erreur si
+//     l'action n'est pas possible
+func (g *Game) DrawAs(token, action string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seat := g.seatOf(token)
+	if seat == "" {
+		return errInvalidSeat
+	}
+	if g.checkTime(time.Now()) {
+		return errTimeUp
+	}
+	if g.GameOver {
+		return errors.New("la partie est terminée")
+	}
+
+	switch action {
+	case DrawActionOffer:
+		if g.DrawOffer != "" {
+			return errors.New("une proposition de nulle est déjà en attente")
+		}
+		switch {
+		case g.Bot == "":
+			g.DrawOffer = seat
+		case g.evaluate(g.Bot) <= 0:
+			g.endGame("draw", ReasonAgreedDraw) // L'ordinateur accepte aussitôt
+		default:
+			return errors.New("l'ordinateur refuse la nulle")
+		}
+	case DrawActionAccept:
+		if g.DrawOffer != opponentOf(seat) {
+			return errors.New("aucune proposition de nulle de l'adversaire")
+		}
+		g.endGame("draw", ReasonAgreedDraw)
+	case DrawActionDecline:
+		if g.DrawOffer == "" {
+			return errors.New("aucune proposition de nulle en attente")
+		}
+		g.DrawOffer = ""
+	default:
+		return errors.New("action inconnue (offer, accept ou decline)")
+	}
+	return nil
+}
+
+//#endregion
+
+//#region HANDLERS HTTP
+
+// HandleResign fait abandonner un joueur
+//
+// Route: POST /api/game/resign?id=<identifiant>
+// En-tête: X-Seat-Token: <jeton du joueur qui abandonne>
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request
+//
+// Réponse:
+//   - 200 OK: État final de la partie (reason: "resignation")
+//   - 400 Bad Request: Partie déjà terminée (ou tout juste perdue au temps) ou identifiant manquant
+//   - 403 Forbidden: Jeton de siège invalide
+//   - 404 Not Found: Partie introuvable
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleResign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
+		return
+	}
+
+	err := game.ResignAs(r.Header.Get(seatTokenHeader))
+	if errors.Is(err, errInvalidSeat) {
+		respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if errors.Is(err, errTimeUp) {
+		gm.commitGame(game) // La défaite au temps vient d'être constatée
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	gm.commitGame(game)
+	respondJSON(w, http.StatusOK, game.GetState())
+}
+
+// HandleDraw propose, accepte ou refuse une nulle
+//
+// Route: POST /api/game/draw?id=<identifiant>
+// En-tête: X-Seat-Token: <jeton du joueur qui agit>
+// Body JSON attendu:
+//
+//	{
+//	  "action": "offer" // "offer", "accept" ou "decline"
+//	}
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request contenant l'action
+//
+// Réponse:
+//   - 200 OK: Nouvel état de la partie ("drawOffer" indique la proposition en attente)
+//   - 400 Bad Request: Action impossible, partie terminée (ou tout juste perdue au temps) ou identifiant manquant
+//   - 403 Forbidden: Jeton de siège invalide
+//   - 404 Not Found: Partie introuvable
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleDraw(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
+		return
+	}
+
+	var req struct {
+		Action string `json:"action"` // DrawActionOffer, DrawActionAccept ou DrawActionDecline
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Format JSON invalide")
+		return
+	}
+
+	err := game.DrawAs(r.Header.Get(seatTokenHeader), req.Action)
+	if errors.Is(err, errInvalidSeat) {
+		respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if errors.Is(err, errTimeUp) {
+		gm.commitGame(game) // La défaite au temps vient d'être constatée
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	gm.commitGame(game)
+	respondJSON(w, http.StatusOK, game.GetState())
+}
+
+//#endregion
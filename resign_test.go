@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//#region TESTS DE L'ABANDON ET DE LA NULLE
+
+// decodeState décode l'état renvoyé par un handler
+func decodeState(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var state map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+// TestResign vérifie que l'abandon donne la victoire à l'adversaire,
+// même hors de son tour, et qu'il ne peut pas être annulé
+func TestResign(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 7)
+	gm.getGame(id).DropPiece(3)
+
+	rec := httptest.NewRecorder()
+	gm.HandleResign(rec, seatRequest(gm, "POST", id, "player1", "/api/game/resign", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("abandon: %d %s", rec.Code, rec.Body.String())
+	}
+	state := decodeState(t, rec)
+	if state["winner"] != "player2" || state["reason"] != ReasonResignation || state["canUndo"] != false {
+		t.Fatalf("état après abandon: %v", state)
+	}
+
+	// Abandonner une partie terminée, ou sans siège, est refusé
+	rec = httptest.NewRecorder()
+	gm.HandleResign(rec, seatRequest(gm, "POST", id, "player2", "/api/game/resign", ""))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("second abandon: %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	gm.HandleResign(rec, httptest.NewRequest("POST", "/api/game/resign?id="+id, nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("abandon sans siège: %d", rec.Code)
+	}
+}
+
+// TestDrawOffer vérifie le déroulement d'une proposition de nulle
+func TestDrawOffer(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 7)
+	draw := func(seat, action string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		gm.HandleDraw(rec, seatRequest(gm, "POST", id, seat, "/api/game/draw", `{"action":"`+action+`"}`))
+		if rec.Code != http.StatusOK {
+			return rec.Code, nil
+		}
+		return rec.Code, decodeState(t, rec)
+	}
+
+	// Personne ne peut accepter sa propre proposition
+	if _, state := draw("player1", DrawActionOffer); state["drawOffer"] != "player1" {
+		t.Fatalf("proposition non enregistrée: %v", state)
+	}
+	if code, _ := draw("player1", DrawActionAccept); code != http.StatusBadRequest {
+		t.Fatalf("auto-acceptation: %d", code)
+	}
+	if code, _ := draw("player2", DrawActionOffer); code != http.StatusBadRequest {
+		t.Fatalf("seconde proposition: %d", code)
+	}
+
+	// Un refus efface la proposition ; un coup joué aussi
+	if _, state := draw("player2", DrawActionDecline); state["drawOffer"] != "" {
+		t.Fatalf("proposition non refusée: %v", state)
+	}
+	draw("player1", DrawActionOffer)
+	gm.getGame(id).DropPiece(0)
+	if offer := gm.getGame(id).GetState()["drawOffer"]; offer != "" {
+		t.Fatalf("proposition conservée après un coup: %v", offer)
+	}
+
+	// Nulle acceptée
+	draw("player2", DrawActionOffer)
+	_, state := draw("player1", DrawActionAccept)
+	if state["gameOver"] != true || state["winner"] != "draw" || state["reason"] != ReasonAgreedDraw {
+		t.Fatalf("nulle non conclue: %v", state)
+	}
+	if code, _ := draw("player1", "surrender"); code != http.StatusBadRequest {
+		t.Fatalf("action inconnue acceptée: %d", code)
+	}
+}
+
+// TestFlagFallDuringResignOrDraw vérifie qu'une pendule tombée constatée
+// par un abandon ou une proposition de nulle termine la partie au temps
+// et l'enregistre aussitôt, comme un coup joué hors délai
+func TestFlagFallDuringResignOrDraw(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		body   string
+		handle func(*GameManager, http.ResponseWriter, *http.Request)
+	}{
+		{"/api/game/resign", "", (*GameManager).HandleResign},
+		{"/api/game/draw", `{"action":"offer"}`, (*GameManager).HandleDraw},
+	} {
+		gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+		rec := httptest.NewRecorder()
+		gm.HandleNewGame(rec, httptest.NewRequest("POST", "/api/game/new", strings.NewReader(
+			`{"rows":6,"cols":7,"player1":"Alice","player2":"Bob","timeControl":{"perMove":30}}`)))
+		var created struct {
+			ID string `json:"id"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+			t.Fatal(err)
+		}
+		gm.HandleDropPiece(httptest.NewRecorder(), seatRequest(gm, "POST", created.ID, "player1", "/api/game/drop", `{"col":3}`))
+
+		// La pendule du joueur 2 est tombée, le minuteur ne l'a pas encore vu
+		game := gm.getGame(created.ID)
+		game.mu.Lock()
+		game.TurnStarted = time.Now().Add(-31 * time.Second)
+		game.mu.Unlock()
+
+		rec = httptest.NewRecorder()
+		tc.handle(gm, rec, seatRequest(gm, "POST", created.ID, "player1", tc.path, tc.body))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: %d, attendu 400", tc.path, rec.Code)
+		}
+		records, err := gm.store.LoadAll()
+		if err != nil || len(records) != 1 || records[0].Winner != "player1" || records[0].Reason != ReasonTimeout {
+			t.Fatalf("%s: défaite au temps non enregistrée: %+v %v", tc.path, records, err)
+		}
+		if _, ok := gm.archive.Get(created.ID); !ok {
+			t.Fatalf("%s: partie non archivée", tc.path)
+		}
+	}
+}
+
+// TestEndReasons vérifie la cause enregistrée pour les fins de partie sur le plateau
+func TestEndReasons(t *testing.T) {
+	off, _ := ResolveGravityRule(GravityRule{Preset: "off"}, 0)
+	g := NewGameWithOptions(6, 7, "Alice", "Bob", GameOptions{Gravity: off})
+	for _, col := range []int{0, 1, 0, 1, 0, 1, 0} {
+		g.DropPiece(col)
+	}
+	if g.Reason != ReasonAlignment {
+		t.Fatalf("victoire: cause %q", g.Reason)
+	}
+	g.Undo()
+	if g.Reason != "" || g.GameOver {
+		t.Fatalf("cause conservée après annulation: %q", g.Reason)
+	}
+
+	// Le joueur 2 joue en colonne col la dernière case libre de la ligne du haut
+	for _, tc := range []struct {
+		top    string
+		col    int
+		reason string
+	}{
+		{"112.", 3, ReasonFullBoard},   // Plateau plein sans alignement
+		{"1...", 1, ReasonNoAlignment}, // Plus aucun alignement possible, plateau incomplet
+	} {
+		g := newEmptyGame(4, 4)
+		for r, line := range []string{tc.top, "2211", "1122", "2211"} {
+			for c, cell := range line {
+				if cell != '.' {
+					g.Board.Set(r, c, "player"+string(cell))
+				}
+			}
+		}
+		g.CurrentPlayer = "player2"
+		if err := g.DropPiece(tc.col); err != nil {
+			t.Fatal(err)
+		}
+		if g.Winner != "draw" || g.Reason != tc.reason {
+			t.Fatalf("%q: gagnant %q, cause %q, attendu %q", tc.top, g.Winner, g.Reason, tc.reason)
+		}
+	}
+}
+
+//#endregion
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+)
+
+//#region JETONS DE SIÈGE
+
+// Erreurs de contrôle d'accès aux sièges
+var (
+	// errInvalidSeat est retournée quand le jeton ne correspond à aucun siège
+	errInvalidSeat = errors.New("jeton de siège invalide")
+	// errNotYourTurn est retournée quand le jeton est celui de l'autre joueur
+	errNotYourTurn = errors.New("ce n'est pas votre tour")
+)
+
+// seatTokenHeader est l'en-tête HTTP portant le jeton de siège du joueur
+const seatTokenHeader = "X-Seat-Token"
+
+// newSecret génère une chaîne aléatoire imprévisible
+//
+// Paramètres:
+//   - size: nombre d'octets aléatoires (la chaîne fait le double en hexadécimal)
+//
+// Retourne:
+//   - string: secret hexadécimal
+func newSecret(size int) string {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // La source d'aléa du système ne devrait jamais échouer
+	}
+	return hex.EncodeToString(b)
+}
+
+// assignSeats attribue un jeton secret à chaque siège humain de la partie
+//
+// Le siège de l'ordinateur n'en reçoit pas : personne ne peut jouer à sa place.
+func (g *Game) assignSeats() {
+	g.SeatTokens = make(map[string]string)
+	for _, seat := range []string{"player1", "player2"} {
+		if seat != g.Bot {
+			g.SeatTokens[seat] = newSecret(16)
+		}
+	}
+}
+
+// seatOf retrouve le siège correspondant à un jeton
+//
+// Une partie sans jetons (créée avant leur introduction) est ouverte :
+// le siège retourné est alors celui du joueur actuel.
+//
+// L'appelant doit détenir g.mu.
+//
+// Paramètres:
+//   - token: jeton présenté par le client
+//
+// Retourne:
+//   - string: "player1", "player2" ou "" si le jeton est invalide
+func (g *Game) seatOf(token string) string {
+	if len(g.SeatTokens) == 0 {
+		return g.CurrentPlayer
+	}
+	for seat, secret := range g.SeatTokens {
+		// Comparaison en temps constant pour ne rien révéler du secret
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+			return seat
+		}
+	}
+	return ""
+}
+
+// HasSeat indique si un jeton correspond à l'un des sièges de la partie
+func (g *Game) HasSeat(token string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.seatOf(token) != ""
+}
+
+// PlayAs place un jeton pour le joueur détenteur d'un jeton de siège
+//
+// Paramètres:
+//   - token: jeton de siège présenté par le client
+//   - col: numéro de la colonne (0 à Cols-1)
+//   - flip: true pour un coup spécial qui inverse la gravité
+//
+// Retourne:
+//   - error: errInvalidSeat, errNotYourTurn, ou une erreur de DropPiece
+func (g *Game) PlayAs(token string, col int, flip bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seat := g.seatOf(token)
+	if seat == "" {
+		return errInvalidSeat
+	}
+	if seat != g.CurrentPlayer && !g.GameOver {
+		return errNotYourTurn
+	}
+	return g.dropPiece(col, flip)
+}
+
+//#endregion
+
+//#region LIAISON D'UN SIÈGE À UN COMPTE
+
+// BindAs lie le siège d'un jeton au compte connecté
+//
+// C'est ainsi que le joueur qui rejoint une invitation fait compter la
+// partie pour le classement (voir ranked). La liaison n'est possible
+// qu'avant le premier coup : personne ne peut décider de rendre la
+// partie classée une fois qu'elle tourne à son avantage.
+//
+// Paramètres:
+//   - token: jeton de siège présenté par le client
+//   - account: compte connecté ; son nom devient le pseudo du siège
+//
+// Retourne:
+//   - error: errInvalidSeat, ou une erreur si la liaison n'est pas possible
+func (g *Game) BindAs(token string, account Profile) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seat := g.seatOf(token)
+	switch {
+	case seat == "":
+		return errInvalidSeat
+	case g.Users[seat] == account.ID:
+		return nil // Déjà lié à ce compte
+	case g.Users[seat] != "":
+		return errors.New("ce siège est déjà lié à un autre compte")
+	case g.Users[opponentOf(seat)] == account.ID:
+		return errors.New("ce compte occupe déjà l'autre siège")
+	case g.GameOver:
+		return errors.New("la partie est terminée")
+	case len(g.history) > 0:
+		return errors.New("un siège ne peut être lié qu'avant le premier coup")
+	}
+
+	if g.Users == nil {
+		g.Users = make(map[string]string)
+	}
+	g.Users[seat] = account.ID
+	if seat == "player1" {
+		g.Player1 = account.Username
+	} else {
+		g.Player2 = account.Username
+	}
+	return nil
+}
+
+//#endregion
+
+//#region CONTRÔLE D'ACCÈS HTTP
+
+// requireSeat vérifie que la requête porte un jeton de siège de la partie
+//
+// Envoie directement une erreur 403 au client si ce n'est pas le cas.
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer l'éventuelle erreur
+//   - r: Request portant l'en-tête X-Seat-Token
+//   - game: partie concernée
+//
+// Retourne:
+//   - bool: true si le jeton est valide
+func requireSeat(w http.ResponseWriter, r *http.Request, game *Game) bool {
+	if !game.HasSeat(r.Header.Get(seatTokenHeader)) {
+		respondError(w, http.StatusForbidden, "Jeton de siège invalide")
+		return false
+	}
+	return true
+}
+
+//#endregion
+
+//#region HANDLER HTTP
+
+// HandleBindSeat lie le siège du joueur au compte connecté
+//
+// Route: POST /api/game/bind?id=<identifiant>
+// En-tête: X-Seat-Token: <jeton du siège à lier>
+// Cookie: session du compte
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request
+//
+// Réponse:
+//   - 200 OK: Nouvel état de la partie (pseudo du siège et "users" mis à jour)
+//   - 400 Bad Request: Liaison impossible ou identifiant manquant
+//   - 401 Unauthorized: Aucune session valide
+//   - 403 Forbidden: Jeton de siège invalide
+//   - 404 Not Found: Partie introuvable
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandleBindSeat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	game := gm.gameFromRequest(w, r)
+	if game == nil {
+		return
+	}
+	account, ok := gm.accounts.FromRequest(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, errNotLoggedIn.Error())
+		return
+	}
+
+	err := game.BindAs(r.Header.Get(seatTokenHeader), account)
+	if errors.Is(err, errInvalidSeat) {
+		respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	gm.commitGame(game)
+	respondJSON(w, http.StatusOK, game.GetState())
+}
+
+//#endregion
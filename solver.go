@@ -0,0 +1,866 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+//#region PARAMÈTRES DU SOLVEUR
+
+const (
+	// solverDefaultTime est la durée d'analyse quand le client n'en précise pas
+	solverDefaultTime = 5 * time.Second
+
+	// solverMaxTime borne par défaut la durée d'analyse demandée par le client
+	// (variable d'environnement ANALYZE_MAX_TIME, voir GameManager.analyzeMaxTime)
+	solverMaxTime = 10 * time.Second
+
+	// solverMaxAnalyses est le nombre d'analyses /api/analyze menées en même
+	// temps ; au-delà, le serveur répond 503
+	solverMaxAnalyses = 2
+
+	// solverWinThreshold sépare les scores de fin de partie (botWinScore moins
+	// la distance en demi-coups) des scores heuristiques de evaluate
+	solverWinThreshold = botWinScore / 2
+
+	// solverTableBits fixe la table de transposition d'une analyse jusqu'à la
+	// fin de partie : 2^22 positions de 16 octets, soit 64 Mo
+	solverTableBits = 22
+
+	// solverDepthTableBits fixe la table d'une analyse limitée en profondeur
+	// (indices) : 2^16 positions, soit 1 Mo
+	solverDepthTableBits = 16
+
+	// solverMaxSide est la plus grande dimension d'un plateau (voir validateBoard)
+	solverMaxSide = 10
+
+	// solverCheckEvery est le nombre de positions explorées entre deux
+	// vérifications de l'échéance
+	solverCheckEvery = 1 << 12
+)
+
+// Verdicts d'un coup sous jeu parfait
+const (
+	ResultWin     = "win"     // Le joueur qui joue ce coup gagne quoi que fasse l'adversaire
+	ResultLoss    = "loss"    // L'adversaire gagne quoi que fasse le joueur
+	ResultDraw    = "draw"    // Aucun des deux joueurs ne peut forcer la victoire
+	ResultUnknown = "unknown" // Analyse interrompue avant de conclure (voir Score)
+)
+
+//#endregion
+
+//#region RÉSULTAT DE L'ANALYSE
+
+// ColumnAnalysis est le verdict du solveur pour une colonne
+type ColumnAnalysis struct {
+	Col    int    `json:"col"`             // Colonne jouée
+	Result string `json:"result"`          // ResultWin, ResultLoss, ResultDraw ou ResultUnknown
+	Plies  int    `json:"plies,omitempty"` // win/loss : demi-coups jusqu'à la fin de partie, ce coup compris
+	Score  int    `json:"score"`           // Score du coup pour le joueur qui le joue (heuristique si unknown)
+}
+
+// Analysis est le résultat de l'analyse d'une position
+type Analysis struct {
+	Player string           `json:"player"` // Joueur qui a le trait
+	Moves  []ColumnAnalysis `json:"moves"`  // Verdict de chaque colonne jouable, de gauche à droite
+	Best   int              `json:"best"`   // Meilleure colonne (-1 si aucun coup n'est possible)
+	Depth  int              `json:"depth"`  // Profondeur de la dernière recherche complète (demi-coups)
+	Exact  bool             `json:"exact"`  // true si tous les verdicts sont prouvés
+	Nodes  int              `json:"nodes"`  // Nombre de positions explorées
+}
+
+//#endregion
+
+//#region TABLE DE TRANSPOSITION
+
+// Nature de la valeur gardée en table (fenêtre alpha-bêta oblige)
+const (
+	boundExact = iota // Valeur exacte
+	boundLower        // La valeur réelle est au moins celle-ci (coupure bêta)
+	boundUpper        // La valeur réelle est au plus celle-ci (aucun coup n'a dépassé alpha)
+)
+
+// ttEntry est une position déjà analysée (16 octets)
+type ttEntry struct {
+	key     uint64 // Empreinte de la position (0 = emplacement libre)
+	value   int32  // Score, les fins de partie comptées depuis cette position
+	depth   int8   // Profondeur de la recherche qui a produit le score
+	bound   uint8  // boundExact, boundLower ou boundUpper
+	horizon bool   // true si le score dépend de l'horizon (sinon valable à toute profondeur)
+	best    int8   // Meilleure colonne trouvée (-1 si aucune)
+}
+
+// transpositionTable garde les positions déjà analysées dans un tableau de taille fixe
+//
+// Chaque empreinte désigne un seul emplacement : une nouvelle position
+// remplace celle qui l'occupait. La mémoire ne dépend donc que de la
+// taille choisie, pas de la durée de l'analyse.
+type transpositionTable []ttEntry
+
+// newTranspositionTable crée une table vide de 2^bits emplacements
+func newTranspositionTable(bits int) transpositionTable {
+	return make(transpositionTable, 1<<bits)
+}
+
+// get retourne l'entrée d'une position, si elle est encore en table
+func (t transpositionTable) get(key uint64) (ttEntry, bool) {
+	entry := t[key&uint64(len(t)-1)]
+	return entry, entry.key == key
+}
+
+// put range une entrée à l'emplacement de sa position
+func (t transpositionTable) put(entry ttEntry) {
+	t[entry.key&uint64(len(t)-1)] = entry
+}
+
+// mix64 est le mélange splitmix64 (voir turnRoll)
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// toTable convertit un score de fin de partie compté depuis la racine en
+// score compté depuis la position (pour être réutilisable à une autre profondeur)
+func toTable(v, ply int) int {
+	switch {
+	case v > solverWinThreshold:
+		return v + ply
+	case v < -solverWinThreshold:
+		return v - ply
+	}
+	return v
+}
+
+// fromTable est l'inverse de toTable
+func fromTable(v, ply int) int {
+	switch {
+	case v > solverWinThreshold:
+		return v - ply
+	case v < -solverWinThreshold:
+		return v + ply
+	}
+	return v
+}
+
+//#endregion
+
+//#region POSITIONS DU SOLVEUR
+
+// solverPosition est une position pendant la recherche
+//
+// Les jetons sont rangés du point de vue du joueur qui a le trait : les
+// règles ne distinguent pas les joueurs, deux positions aux couleurs
+// échangées ont donc la même valeur (et la même entrée de table).
+type solverPosition struct {
+	own, opp bits128 // Jetons du joueur qui a le trait et de son adversaire
+	inverse  bool    // Gravité inversée
+	turn     int     // Tours déjà joués (calendrier des inversions de gravité)
+}
+
+// solverMove est un coup envisagé depuis une position
+type solverMove struct {
+	col     int            // Colonne jouée
+	cell    bits128        // Case où tombe le jeton
+	child   solverPosition // Position après le coup
+	settled bool           // true si score est connu sans recherche
+	score   int            // Score du coup pour le joueur qui le joue (si settled)
+	threats int            // Cases gagnantes du joueur après le coup (ordre d'exploration)
+}
+
+// solver explore une position par negamax alpha-bêta avec table de transposition
+//
+// Les coups sont joués directement sur les masques de bits, en suivant
+// les règles de game.go (gravité inversée, obstacles, égalité anticipée) ;
+// la partie copiée ne sert qu'à connaître ces règles et à l'évaluation
+// heuristique. Les coups spéciaux de la règle "special" ne sont pas envisagés.
+type solver struct {
+	game     *Game              // Copie de la partie (règles, obstacles, evaluate)
+	root     string             // Joueur qui a le trait à la racine
+	columns  []bits128          // Cases de chaque colonne, obstacles exclus
+	free     bits128            // Cases du plateau qui ne sont pas des obstacles
+	dirs     [4]int             // Décalages des 4 directions d'alignement
+	order    []int              // Colonnes du centre vers les bords
+	table    transpositionTable // Positions déjà analysées
+	deadline time.Time          // Échéance de l'analyse (zéro = aucune)
+	nodes    int                // Positions explorées
+	horizon  bool               // true si la recherche en cours a atteint l'horizon
+	aborted  bool               // true si l'échéance est dépassée
+}
+
+// newSolver prépare la recherche sur une copie de partie
+//
+// Paramètres:
+//   - g: copie de la partie, qui appartient désormais au solveur
+//   - tableBits: taille de la table de transposition (2^tableBits positions)
+func newSolver(g *Game, tableBits int) *solver {
+	s := &solver{
+		game:    g,
+		root:    g.CurrentPlayer,
+		columns: make([]bits128, g.Cols),
+		free:    g.Board.full.andNot(g.Board.blocked),
+		dirs:    g.Board.directions(),
+		order:   g.orderedColumns(),
+		table:   newTranspositionTable(tableBits),
+	}
+	for col := range s.columns {
+		s.columns[col] = g.Board.columnMask(col).andNot(g.Board.blocked)
+	}
+	return s
+}
+
+// position retourne la position de la partie copiée
+func (s *solver) position() solverPosition {
+	b := &s.game.Board
+	p := solverPosition{own: b.player1, opp: b.player2, inverse: s.game.InverseGravity, turn: s.game.TurnCount}
+	if s.root == "player2" {
+		p.own, p.opp = p.opp, p.own
+	}
+	return p
+}
+
+// hash retourne l'empreinte d'une position (jamais nulle)
+//
+// Le numéro du tour n'y figure que s'il influence la suite de la partie :
+// modulo la période pour la gravité périodique, en entier pour la gravité
+// aléatoire, pas du tout sinon.
+func (s *solver) hash(p solverPosition) uint64 {
+	phase := 0
+	switch s.game.Gravity.Mode {
+	case GravityPeriodic:
+		phase = p.turn % s.game.Gravity.Period
+	case GravityRandom:
+		phase = p.turn
+	}
+	flags := uint64(phase) << 1
+	if p.inverse {
+		flags |= 1
+	}
+	h := mix64(p.own.lo + 0x9E3779B97F4A7C15)
+	h = mix64(h ^ p.own.hi)
+	h = mix64(h ^ p.opp.lo)
+	h = mix64(h ^ p.opp.hi)
+	return mix64(h^flags) | 1
+}
+
+// landing retourne la case où tomberait un jeton joué dans une colonne
+// (masque vide si la colonne est pleine), comme Bitboard.LandingRow
+func (s *solver) landing(p solverPosition, col int) bits128 {
+	empty := s.columns[col].andNot(p.own.or(p.opp))
+	i := empty.lowest()
+	if p.inverse {
+		i = empty.highest()
+	}
+	if i < 0 {
+		return bits128{}
+	}
+	return bit(i)
+}
+
+// playable retourne les cases où le joueur qui a le trait peut poser un jeton
+func (s *solver) playable(p solverPosition) bits128 {
+	var cells bits128
+	for col := range s.columns {
+		cells = cells.or(s.landing(p, col))
+	}
+	return cells
+}
+
+// play retourne la position après un jeton posé sur cell
+func (s *solver) play(p solverPosition, cell bits128) solverPosition {
+	turn := p.turn + 1
+	return solverPosition{
+		own:     p.opp,
+		opp:     p.own.or(cell),
+		inverse: p.inverse != s.game.Gravity.flipsAfter(turn),
+		turn:    turn,
+	}
+}
+
+// threats retourne les cases qui compléteraient un alignement de stones
+//
+// Pour chaque direction, before[k] marque les cases dont les k voisines
+// du côté -d sont à stones, after[k] celles du côté +d : une case complète
+// un alignement de n si, pour un k, elle a k voisines d'un côté et n-1-k
+// de l'autre. Les bits sentinelles (toujours à 0) empêchent les suites de
+// passer d'une colonne à l'autre. Le résultat est à croiser avec les cases
+// encore vides.
+func (s *solver) threats(stones bits128) bits128 {
+	n := s.game.WinLength
+	var cells bits128
+	var before, after [solverMaxSide]bits128
+	for _, d := range s.dirs {
+		before[0], after[0] = s.free, s.free
+		for k := 1; k < n; k++ {
+			before[k] = before[k-1].and(stones.shl(k * d))
+			after[k] = after[k-1].and(stones.shr(k * d))
+		}
+		for k := 0; k < n; k++ {
+			cells = cells.or(before[k].and(after[n-1-k]))
+		}
+	}
+	return cells
+}
+
+// canAlign indique si le joueur dont l'adversaire a les jetons opponent
+// peut encore aligner WinLength jetons (voir Bitboard.CanAlign)
+func (s *solver) canAlign(opponent bits128) bool {
+	open := s.free.andNot(opponent)
+	for _, d := range s.dirs {
+		if !open.runs(d, s.game.WinLength).isZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// moves liste les coups jouables d'une position, du centre vers les bords
+//
+// Les coups dont l'issue est immédiate sont réglés sans recherche : le
+// plateau plein donne la nulle, et un coup qui laisse une victoire à
+// l'adversaire perd au demi-coup suivant. Les victoires immédiates du
+// joueur qui a le trait ne sont pas détectées (voir search).
+//
+// Paramètres:
+//   - p: position dont les coups sont listés
+//   - ply: nombre de demi-coups déjà joués depuis la racine
+//   - list: tableau réutilisé pour la liste (évite une allocation par position)
+func (s *solver) moves(p solverPosition, ply int, list []solverMove) []solverMove {
+	theirs := s.threats(p.opp)
+	playable := s.playable(p)
+	for _, col := range s.order {
+		cell := s.landing(p, col)
+		if cell.isZero() {
+			continue
+		}
+		m := solverMove{col: col, cell: cell, child: s.play(p, cell)}
+		// Sans inversion de gravité, seule la colonne jouée change de case d'arrivée
+		next := playable.andNot(cell).or(s.landing(m.child, col))
+		if m.child.inverse != p.inverse {
+			next = s.playable(m.child)
+		}
+		switch {
+		case !theirs.and(next).isZero():
+			m.settled, m.score = true, -(botWinScore - (ply + 2))
+		case next.isZero():
+			m.settled = true
+		default:
+			empty := s.free.andNot(m.child.own.or(m.child.opp))
+			m.threats = s.threats(m.child.opp).and(empty).count()
+		}
+		list = append(list, m)
+	}
+	return list
+}
+
+//#endregion
+
+//#region RECHERCHE EXACTE
+
+// search retourne la valeur de la position pour le joueur qui a le trait
+//
+// Le joueur qui a le trait ne peut pas gagner immédiatement : moves a déjà
+// réglé sans recherche les coups qui le permettaient à l'adversaire. Sa
+// victoire la plus rapide est donc à son deuxième coup, et sa défaite la
+// plus rapide au coup suivant de l'adversaire : ces bornes resserrent la
+// fenêtre avant toute exploration, ce qui rend les recherches à fenêtre
+// nulle de prove très rapides.
+//
+// Paramètres:
+//   - p: position à évaluer
+//   - depth: nombre de demi-coups restant à explorer
+//   - ply: nombre de demi-coups déjà joués depuis la racine
+//   - alpha, beta: fenêtre alpha-bêta
+//
+// Retourne:
+//   - int: score du point de vue du joueur qui a le trait (sans signification
+//     si s.aborted est passé à true)
+func (s *solver) search(p solverPosition, depth, ply, alpha, beta int) int {
+	s.nodes++
+	if s.nodes%solverCheckEvery == 0 && !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.aborted = true
+	}
+	if s.aborted {
+		return 0
+	}
+
+	// Bornes prouvées : un joueur qui ne peut plus aligner ne gagnera pas
+	upper, lower := botWinScore-(ply+3), -(botWinScore - (ply + 2))
+	if !s.canAlign(p.opp) {
+		upper = 0
+	}
+	if !s.canAlign(p.own) {
+		lower = 0
+	}
+	if beta > upper {
+		if beta = upper; alpha >= beta {
+			return beta
+		}
+	}
+	if alpha < lower {
+		if alpha = lower; alpha >= beta {
+			return alpha
+		}
+	}
+
+	// Position déjà rencontrée : sa valeur (ou une borne) suffit peut-être
+	key := s.hash(p)
+	entry, known := s.table.get(key)
+	if known && (!entry.horizon || int(entry.depth) >= depth) {
+		v := fromTable(int(entry.value), ply)
+		if entry.bound == boundExact || (entry.bound == boundLower && v >= beta) || (entry.bound == boundUpper && v <= alpha) {
+			s.horizon = s.horizon || entry.horizon
+			return v
+		}
+	}
+
+	// Horizon atteint : évaluation heuristique, bornée pour ne jamais
+	// passer pour une fin de partie
+	if depth == 0 {
+		s.horizon = true
+		return max(-solverWinThreshold, min(s.evaluate(p, ply), solverWinThreshold))
+	}
+
+	var buf [solverMaxSide]solverMove
+	moves := s.moves(p, ply, buf[:0])
+	tableBest := -1
+	if known {
+		tableBest = int(entry.best)
+	}
+	orderMoves(moves, tableBest)
+
+	outer := s.horizon
+	s.horizon = false
+	origAlpha := alpha
+	best, bestCol := -botWinScore*2, -1
+	for _, m := range moves {
+		score := m.score
+		if !m.settled {
+			score = -s.search(m.child, depth-1, ply+1, -beta, -alpha)
+			if s.aborted {
+				return 0
+			}
+		}
+		if score > best {
+			best, bestCol = score, m.col
+		}
+		if best > alpha {
+			alpha = best
+		}
+		// Coupure : l'adversaire n'autorisera jamais cette branche
+		if alpha >= beta {
+			break
+		}
+	}
+	if bestCol < 0 {
+		return 0 // Aucun coup possible (ne se produit pas : moves règle le plateau plein)
+	}
+	nodeHorizon := s.horizon
+	s.horizon = outer || nodeHorizon
+
+	bound := uint8(boundExact)
+	if best <= origAlpha {
+		bound = boundUpper
+	} else if best >= beta {
+		bound = boundLower
+	}
+	s.table.put(ttEntry{key: key, value: int32(toTable(best, ply)), depth: int8(depth), bound: bound, horizon: nodeHorizon, best: int8(bestCol)})
+	return best
+}
+
+// orderMoves trie les coups, les plus prometteurs en premier
+//
+// Les coups réglés d'avance passent devant (ils ne coûtent rien), puis le
+// meilleur coup connu de la table, puis ceux qui laissent au joueur le
+// plus de cases gagnantes (menaces). À égalité, l'ordre du centre vers les
+// bords est conservé (tri stable).
+//
+// Paramètres:
+//   - moves: coups retournés par solver.moves
+//   - tableBest: meilleure colonne gardée en table (-1 si aucune)
+func orderMoves(moves []solverMove, tableBest int) {
+	rank := func(m *solverMove) int {
+		switch {
+		case m.settled:
+			return 1 << 20
+		case m.col == tableBest:
+			return 1 << 19
+		}
+		return m.threats
+	}
+	for i := 1; i < len(moves); i++ {
+		for j := i; j > 0 && rank(&moves[j]) > rank(&moves[j-1]); j-- {
+			moves[j], moves[j-1] = moves[j-1], moves[j]
+		}
+	}
+}
+
+// evaluate note une position à l'horizon avec l'heuristique de l'ordinateur
+//
+// Retourne:
+//   - int: score du point de vue du joueur qui a le trait
+func (s *solver) evaluate(p solverPosition, ply int) int {
+	player := s.root
+	if ply%2 == 1 {
+		player = opponentOf(player)
+	}
+	b := &s.game.Board
+	b.player1, b.player2 = p.own, p.opp
+	if player == "player2" {
+		b.player1, b.player2 = p.opp, p.own
+	}
+	return s.game.evaluate(player)
+}
+
+// prove retourne la valeur exacte d'un coup, par recherches à fenêtre nulle
+//
+// Chaque recherche à fenêtre nulle répond seulement à la question « le
+// score dépasse-t-il v ? », bien plus vite qu'une recherche complète. Les
+// scores possibles (défaite ou victoire en 1 à empty demi-coups, nulle)
+// sont numérotés de -empty à empty, et la valeur est trouvée par
+// dichotomie sur ce numéro. La première question porte sur la nulle
+// (le coup gagne-t-il ?), puis la dichotomie penche vers les fins de
+// partie lointaines, les plus fréquentes.
+//
+// Paramètres:
+//   - child: position après le coup
+//   - empty: cases vides à la racine (le coup compris)
+//
+// Retourne:
+//   - int: score du coup pour le joueur qui le joue
+//   - bool: false si l'échéance est dépassée avant la preuve
+func (s *solver) prove(child solverPosition, empty int) (int, bool) {
+	score := func(rank int) int {
+		switch {
+		case rank > 0:
+			return botWinScore - (empty + 1 - rank)
+		case rank < 0:
+			return -(botWinScore - (empty + 1 + rank))
+		}
+		return 0
+	}
+
+	lo, hi := -empty, empty
+	for lo < hi {
+		med := lo + (hi-lo)/2
+		if med <= 0 && lo/2 < med {
+			med = lo / 2
+		} else if med >= 0 && hi/2 > med {
+			med = hi / 2
+		}
+		v := score(med)
+		won := -s.search(child, empty, 1, -(v + 1), -v)
+		if s.aborted {
+			return 0, false
+		}
+		if won > v {
+			lo = med + 1
+		} else {
+			hi = med
+		}
+	}
+	return score(lo), true
+}
+
+//#endregion
+
+//#region ANALYSE D'UNE POSITION
+
+// Analyze évalue chaque colonne jouable sous jeu parfait
+//
+// Sans limite de profondeur, chaque coup est d'abord prouvé jusqu'à la
+// fin de partie (voir prove), pendant au plus les trois quarts du temps
+// imparti. Les coups restés sans preuve reçoivent ensuite une évaluation
+// approfondie d'un demi-coup à la fois (la table de transposition est
+// conservée), jusqu'à maxDepth ou à l'échéance : leur verdict est alors
+// celui de la dernière itération terminée, ResultUnknown s'il dépend de
+// l'horizon.
+//
+// Paramètres:
+//   - limit: durée maximale de l'analyse (la première itération est toujours terminée)
+//   - maxDepth: profondeur maximale en demi-coups (0 = jusqu'à la fin de partie)
+//
+// Retourne:
+//   - Analysis: verdict de chaque colonne et meilleure colonne
+func (g *Game) Analyze(limit time.Duration, maxDepth int) Analysis {
+	start := time.Now()
+	g.mu.Lock()
+	sim := g.clone()
+	g.mu.Unlock()
+
+	empty := sim.Rows*sim.Cols - sim.Board.occupied().count()
+	complete := maxDepth <= 0 || maxDepth >= empty
+	tableBits := solverDepthTableBits
+	if complete {
+		maxDepth, tableBits = empty, solverTableBits
+	}
+	result := Analysis{Player: sim.CurrentPlayer, Moves: []ColumnAnalysis{}, Best: -1}
+	if sim.GameOver {
+		return result
+	}
+	s := newSolver(sim, tableBits)
+	root := s.position()
+	moves := s.moves(root, 0, nil)
+	wins := s.threats(root.own)
+	for i := range moves {
+		if !wins.and(moves[i].cell).isZero() {
+			moves[i].settled, moves[i].score = true, botWinScore-1
+		}
+	}
+
+	// Preuve jusqu'à la fin de partie, coup par coup
+	proved := false
+	if complete {
+		s.deadline = start.Add(limit * 3 / 4)
+		for i := range moves {
+			if moves[i].settled {
+				continue
+			}
+			score, ok := s.prove(moves[i].child, empty)
+			if !ok {
+				break
+			}
+			moves[i].settled, moves[i].score = true, score
+		}
+		proved = !s.aborted
+	}
+
+	if proved {
+		for _, m := range moves {
+			result.Moves = append(result.Moves, classifyMove(m.col, m.score, true))
+		}
+		result.Depth, result.Exact = empty, true
+	} else {
+		// Évaluation limitée en profondeur des coups restés sans preuve
+		s.deadline, s.aborted = time.Time{}, false // La première itération n'est jamais interrompue
+		for depth := 1; depth <= maxDepth; depth++ {
+			verdicts := []ColumnAnalysis{}
+			exact := true
+			for _, m := range moves {
+				score, reached := m.score, false
+				if !m.settled {
+					s.horizon = false
+					score = -s.search(m.child, depth-1, 1, -botWinScore*2, botWinScore*2)
+					if s.aborted {
+						break
+					}
+					reached = s.horizon
+				}
+				verdict := classifyMove(m.col, score, !reached)
+				exact = exact && verdict.Result != ResultUnknown
+				verdicts = append(verdicts, verdict)
+			}
+			if s.aborted {
+				break
+			}
+			result.Moves, result.Depth, result.Exact = verdicts, depth, exact
+			if exact {
+				break
+			}
+			s.deadline = start.Add(limit)
+		}
+	}
+
+	// Meilleur coup (du centre vers les bords à score égal), puis colonnes de gauche à droite
+	bestScore := -botWinScore * 2
+	for _, move := range result.Moves {
+		if move.Score > bestScore {
+			result.Best, bestScore = move.Col, move.Score
+		}
+	}
+	sort.Slice(result.Moves, func(i, j int) bool { return result.Moves[i].Col < result.Moves[j].Col })
+	result.Nodes = s.nodes
+	return result
+}
+
+// classifyMove traduit le score d'un coup en verdict
+//
+// Un score de fin de partie est toujours prouvé ; un score heuristique
+// ne vaut match nul que si la recherche n'a jamais atteint l'horizon.
+//
+// Paramètres:
+//   - col: colonne jouée
+//   - score: score du coup pour le joueur qui le joue
+//   - complete: true si la recherche du coup n'a pas atteint l'horizon
+func classifyMove(col, score int, complete bool) ColumnAnalysis {
+	verdict := ColumnAnalysis{Col: col, Score: score}
+	switch {
+	case score > solverWinThreshold:
+		verdict.Result, verdict.Plies = ResultWin, botWinScore-score
+	case score < -solverWinThreshold:
+		verdict.Result, verdict.Plies = ResultLoss, botWinScore+score
+	case complete:
+		verdict.Result = ResultDraw
+	default:
+		verdict.Result = ResultUnknown
+	}
+	return verdict
+}
+
+// positionGame construit une partie à partir d'une position décrite par le client
+//
+// Paramètres:
+//   - rows, cols, winLength: dimensions et jetons à aligner
+//   - board: cases indexées par [ligne][colonne] ("", "player1", "player2" ou BlockedCell)
+//   - current: joueur qui a le trait
+//   - inverse, turnCount: état de la gravité et nombre de tours déjà joués
+//   - gravity: règle d'inversion de la gravité (vide = DefaultGravityPreset)
+//
+// Retourne:
+//   - *Game: partie prête à être analysée
+//   - error: description du premier paramètre invalide
+func positionGame(rows, cols, winLength int, board [][]string, current string, inverse bool, turnCount int, gravity GravityRule) (*Game, error) {
+	if winLength == 0 {
+		winLength = DefaultWinLength
+	}
+	if err := validateBoard(rows, cols, winLength, 0); err != nil {
+		return nil, err
+	}
+	if current != "player1" && current != "player2" {
+		return nil, errors.New("le joueur qui a le trait doit être player1 ou player2")
+	}
+	if turnCount < 0 {
+		return nil, errors.New("le nombre de tours ne peut pas être négatif")
+	}
+	rule, err := ResolveGravityRule(gravity, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Game{
+		Rows:           rows,
+		Cols:           cols,
+		WinLength:      winLength,
+		Board:          NewBitboard(rows, cols),
+		CurrentPlayer:  current,
+		TurnCount:      turnCount,
+		InverseGravity: inverse,
+		Gravity:        rule,
+	}
+	if len(board) != rows {
+		return nil, fmt.Errorf("le plateau doit avoir %d lignes", rows)
+	}
+	for row, cells := range board {
+		if len(cells) != cols {
+			return nil, fmt.Errorf("la ligne %d doit avoir %d colonnes", row, cols)
+		}
+		for col, cell := range cells {
+			switch cell {
+			case "":
+			case "player1", "player2", BlockedCell:
+				g.Board.Set(row, col, cell)
+			default:
+				return nil, fmt.Errorf("case inconnue en ligne %d, colonne %d: %q", row, col, cell)
+			}
+		}
+	}
+
+	// Une position déjà gagnée n'a plus rien à analyser
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			if g.Board.WinsThrough(row, col, winLength) {
+				return nil, fmt.Errorf("la position est déjà gagnée par %s", g.Board.Cell(row, col))
+			}
+		}
+	}
+	if g.checkDraw() {
+		g.GameOver = true
+		g.Winner = "draw"
+	}
+	return g, nil
+}
+
+//#endregion
+
+//#region HANDLER HTTP
+
+// HandleAnalyze analyse une position et retourne le verdict de chaque colonne
+//
+// Route: POST /api/analyze
+//
+// Format de la requête JSON (mêmes clés que l'état d'une partie, qui peut
+// donc être envoyé tel quel):
+//
+//	{
+//	  "rows": 6,
+//	  "cols": 7,
+//	  "winLength": 4,              // optionnel (4 par défaut)
+//	  "board": [["", ...], ...],   // "", "player1", "player2" ou "blocked"
+//	  "currentPlayer": "player1",
+//	  "inverseGravity": false,
+//	  "turnCount": 12,             // tours joués (calendrier des inversions)
+//	  "gravity": {"preset": "classic"}, // optionnel
+//	  "maxDepth": 0,               // optionnel: profondeur maximale (0 = jusqu'à la fin)
+//	  "timeLimit": 5000            // optionnel: durée maximale en millisecondes (5 s par défaut)
+//	}
+//
+// L'analyse est bornée en temps (gm.analyzeMaxTime, 10 s par défaut) et en
+// mémoire : un milieu de partie sur le plateau 6x7 est prouvé en quelques
+// secondes, mais sur un grand plateau ou en tout début de partie, la
+// recherche s'arrête avant la preuve et les colonnes sans preuve reçoivent
+// le verdict "unknown" (évaluation limitée en profondeur).
+// Au plus solverMaxAnalyses analyses sont menées en même temps.
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request contenant la position
+//
+// Réponse:
+//   - 200 OK: verdict de chaque colonne (Analysis)
+//   - 400 Bad Request: Position invalide ou déjà gagnée
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+//   - 503 Service Unavailable: Trop d'analyses en cours
+func (gm *GameManager) HandleAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	var req struct {
+		Rows           int         `json:"rows"`           // Nombre de lignes
+		Cols           int         `json:"cols"`           // Nombre de colonnes
+		WinLength      int         `json:"winLength"`      // Jetons à aligner (0 = DefaultWinLength)
+		Board          [][]string  `json:"board"`          // Cases indexées par [ligne][colonne]
+		CurrentPlayer  string      `json:"currentPlayer"`  // Joueur qui a le trait
+		InverseGravity bool        `json:"inverseGravity"` // Gravité inversée
+		TurnCount      int         `json:"turnCount"`      // Tours déjà joués
+		Gravity        GravityRule `json:"gravity"`        // Règle d'inversion de la gravité
+		MaxDepth       int         `json:"maxDepth"`       // Profondeur maximale (0 = jusqu'à la fin)
+		TimeLimit      int         `json:"timeLimit"`      // Durée maximale en millisecondes (0 = solverDefaultTime)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Format JSON invalide")
+		return
+	}
+
+	g, err := positionGame(req.Rows, req.Cols, req.WinLength, req.Board, req.CurrentPlayer, req.InverseGravity, req.TurnCount, req.Gravity)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.MaxDepth < 0 || req.TimeLimit < 0 {
+		respondError(w, http.StatusBadRequest, "La profondeur et la durée ne peuvent pas être négatives")
+		return
+	}
+
+	limit := min(solverDefaultTime, gm.analyzeMaxTime)
+	if req.TimeLimit > 0 {
+		limit = min(time.Duration(req.TimeLimit)*time.Millisecond, gm.analyzeMaxTime)
+	}
+
+	// Une place parmi les analyses en cours, sans attendre
+	select {
+	case gm.analyses <- struct{}{}:
+		defer func() { <-gm.analyses }()
+	default:
+		respondError(w, http.StatusServiceUnavailable, "Trop d'analyses en cours, réessayez dans un instant")
+		return
+	}
+	respondJSON(w, http.StatusOK, g.Analyze(limit, req.MaxDepth))
+}
+
+//#endregion
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//#region SOLVEUR DE RÉFÉRENCE
+
+// referenceValue explore toute la partie sans élagage ni table : c'est la
+// définition du score que le solveur doit retrouver
+func referenceValue(g *Game, ply int) int {
+	best, played := -botWinScore*2, false
+	for col := 0; col < g.Cols; col++ {
+		before := g.saveTurnState()
+		row, err := g.applyMove(col, false)
+		if err != nil {
+			continue
+		}
+		played = true
+		var score int
+		switch {
+		case g.GameOver && g.Winner == "draw":
+			score = 0
+		case g.GameOver:
+			score = botWinScore - (ply + 1)
+		default:
+			score = -referenceValue(g, ply+1)
+		}
+		g.Board.Clear(row, col)
+		g.loadTurnState(before)
+		best = max(best, score)
+	}
+	if !played {
+		return 0
+	}
+	return best
+}
+
+// randomPosition joue des coups au hasard jusqu'à ne laisser que empty cases libres
+func randomPosition(rng *rand.Rand, rows, cols, winLength, empty int, gravity GravityRule) *Game {
+	for {
+		g := NewGameWithOptions(rows, cols, "Alice", "Bob", GameOptions{WinLength: winLength, Gravity: gravity, Seed: 1})
+		for !g.GameOver && rows*cols-g.Board.occupied().count() > empty {
+			g.applyMove(rng.Intn(cols), false)
+		}
+		if !g.GameOver {
+			return g
+		}
+	}
+}
+
+//#endregion
+
+//#region TESTS DU SOLVEUR
+
+// TestSolverMatchesReference compare les verdicts du solveur à une
+// exploration exhaustive, avec et sans inversions de gravité
+func TestSolverMatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for _, gravity := range []GravityRule{{Preset: "off"}, {Mode: GravityPeriodic, Period: 3}} {
+		rule, _ := ResolveGravityRule(gravity, 0)
+		for i := 0; i < 15; i++ {
+			g := randomPosition(rng, 5, 5, 4, 9, rule)
+			analysis := g.Analyze(time.Minute, 0)
+			if !analysis.Exact {
+				t.Fatalf("%s position %d: analyse inexacte", rule.Mode, i)
+			}
+			for _, move := range analysis.Moves {
+				before := g.saveTurnState()
+				row, _ := g.applyMove(move.Col, false)
+				want := botWinScore - 1
+				switch {
+				case g.GameOver && g.Winner == "draw":
+					want = 0
+				case !g.GameOver:
+					want = -referenceValue(g, 1)
+				}
+				g.Board.Clear(row, move.Col)
+				g.loadTurnState(before)
+
+				if move.Score != want {
+					t.Fatalf("%s position %d colonne %d: score %d (%s), attendu %d", rule.Mode, i, move.Col, move.Score, move.Result, want)
+				}
+			}
+		}
+	}
+}
+
+// TestSolverVerdicts vérifie la traduction des scores en victoire, défaite
+// ou nulle et le nombre de demi-coups annoncé
+func TestSolverVerdicts(t *testing.T) {
+	g := newEmptyGame(6, 7)
+	g.Gravity, _ = ResolveGravityRule(GravityRule{Preset: "off"}, 0)
+	for _, col := range []int{0, 1, 2} {
+		g.Board.Set(5, col, "player1")
+		g.Board.Set(4, col, "player2")
+	}
+	g.TurnCount = 6
+
+	// Le joueur 1 gagne tout de suite en colonne 3
+	analysis := g.Analyze(time.Second, 4)
+	if analysis.Best != 3 {
+		t.Fatalf("meilleur coup %d, attendu 3", analysis.Best)
+	}
+	if move := analysis.Moves[3]; move.Result != ResultWin || move.Plies != 1 {
+		t.Fatalf("colonne 3: %+v, attendu une victoire en 1", move)
+	}
+
+	// Au joueur 2 : tout coup hors de la colonne 3 perd en 2 demi-coups
+	g.CurrentPlayer = "player2"
+	analysis = g.Analyze(time.Second, 4)
+	for _, move := range analysis.Moves {
+		if move.Col != 3 && (move.Result != ResultLoss || move.Plies != 2) {
+			t.Fatalf("colonne %d: %+v, attendu une défaite en 2", move.Col, move)
+		}
+	}
+	if analysis.Best != 3 || analysis.Moves[3].Result == ResultLoss {
+		t.Fatalf("parade manquée: %+v", analysis)
+	}
+}
+
+// TestSolverRespectsTimeLimit vérifie qu'un grand plateau vide donne une
+// évaluation limitée en profondeur dans le temps imparti
+func TestSolverRespectsTimeLimit(t *testing.T) {
+	g := newEmptyGame(10, 10)
+	start := time.Now()
+	analysis := g.Analyze(200*time.Millisecond, 0)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("analyse de %v pour une limite de 200ms", elapsed)
+	}
+	if analysis.Exact || analysis.Depth < 1 || len(analysis.Moves) != 10 {
+		t.Fatalf("analyse inattendue: profondeur %d, exacte %v, %d coups", analysis.Depth, analysis.Exact, len(analysis.Moves))
+	}
+}
+
+// TestSolverProvesMidgame vérifie qu'un milieu de partie sur le plateau 6x7
+// est prouvé dans la durée d'analyse par défaut, avec et sans inversions de gravité
+func TestSolverProvesMidgame(t *testing.T) {
+	for _, opening := range []struct {
+		gravity string
+		cols    []int
+	}{
+		{"classic", []int{3, 3, 3, 3, 3, 3, 2, 4, 2, 4, 4, 2}},
+		{"off", []int{3, 2, 3, 3, 4, 5, 2, 4, 4, 3, 1, 5}},
+	} {
+		rule, _ := ResolveGravityRule(GravityRule{Preset: opening.gravity}, 0)
+		g := NewGameWithOptions(6, 7, "Alice", "Bob", GameOptions{Gravity: rule})
+		for _, col := range opening.cols {
+			if err := g.DropPiece(col); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		start := time.Now()
+		analysis := g.Analyze(solverDefaultTime, 0)
+		if !analysis.Exact {
+			t.Fatalf("%s: ouverture %v non prouvée en %v (%d positions)", opening.gravity, opening.cols, time.Since(start), analysis.Nodes)
+		}
+		for _, move := range analysis.Moves {
+			if move.Result == ResultUnknown {
+				t.Fatalf("%s: colonne %d sans verdict", opening.gravity, move.Col)
+			}
+		}
+	}
+}
+
+// TestHandleAnalyze vérifie la validation de la position et la réponse de l'API
+func TestHandleAnalyze(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+
+	body := `{"rows":4,"cols":4,"winLength":3,"gravity":{"preset":"off"},"currentPlayer":"player2","turnCount":4,
+		"board":[["","","",""],["","","",""],["player2","blocked","",""],["player1","player1","player2","player1"]]}`
+	rec := httptest.NewRecorder()
+	gm.HandleAnalyze(rec, httptest.NewRequest("POST", "/api/analyze", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("analyse: %d %s", rec.Code, rec.Body.String())
+	}
+	var analysis Analysis
+	if err := json.NewDecoder(rec.Body).Decode(&analysis); err != nil {
+		t.Fatal(err)
+	}
+	if !analysis.Exact || analysis.Player != "player2" || len(analysis.Moves) != 4 {
+		t.Fatalf("analyse inattendue: %+v", analysis)
+	}
+
+	for _, bad := range []string{
+		`{"rows":4,"cols":4,"currentPlayer":"player1","board":[["","","",""]]}`,
+		`{"rows":4,"cols":4,"winLength":3,"currentPlayer":"player1","board":[["","","",""],["","","",""],["","","",""],["player1","player1","player1",""]]}`,
+		`{"rows":4,"cols":4,"currentPlayer":"nobody","board":[["","","",""],["","","",""],["","","",""],["","","",""]]}`,
+		`{"rows":4,"cols":4,"currentPlayer":"player1","board":[["","","",""],["","","",""],["","","",""],["","x","",""]]}`,
+	} {
+		rec := httptest.NewRecorder()
+		gm.HandleAnalyze(rec, httptest.NewRequest("POST", "/api/analyze", strings.NewReader(bad)))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("position invalide acceptée (%d): %s", rec.Code, bad)
+		}
+	}
+
+	// Toutes les places occupées : l'analyse est refusée sans attendre
+	for i := 0; i < solverMaxAnalyses; i++ {
+		gm.analyses <- struct{}{}
+	}
+	rec = httptest.NewRecorder()
+	gm.HandleAnalyze(rec, httptest.NewRequest("POST", "/api/analyze", strings.NewReader(body)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("analyses simultanées: %d, attendu 503", rec.Code)
+	}
+	<-gm.analyses
+	rec = httptest.NewRecorder()
+	gm.HandleAnalyze(rec, httptest.NewRequest("POST", "/api/analyze", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("analyse après libération d'une place: %d", rec.Code)
+	}
+
+	// La durée demandée est bornée par la durée maximale du serveur
+	gm.analyzeMaxTime = 100 * time.Millisecond
+	empty := `{"rows":10,"cols":10,"currentPlayer":"player1","timeLimit":60000,"board":[` +
+		strings.Repeat(`["","","","","","","","","",""],`, 9) + `["","","","","","","","","",""]]}`
+	start := time.Now()
+	rec = httptest.NewRecorder()
+	gm.HandleAnalyze(rec, httptest.NewRequest("POST", "/api/analyze", strings.NewReader(empty)))
+	if rec.Code != http.StatusOK || time.Since(start) > 5*time.Second {
+		t.Fatalf("analyse bornée: %d en %v", rec.Code, time.Since(start))
+	}
+}
+
+//#endregion
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+//#region STATISTIQUES DES JOUEURS
+
+// customDifficulty regroupe les parties jouées sur un plateau personnalisé (sans préréglage)
+const customDifficulty = "custom"
+
+// Record est le bilan d'un joueur sur un ensemble de parties
+type Record struct {
+	Games   int     `json:"games"`   // Parties jouées
+	Wins    int     `json:"wins"`    // Victoires
+	Losses  int     `json:"losses"`  // Défaites
+	Draws   int     `json:"draws"`   // Nulles
+	WinRate float64 `json:"winRate"` // Part des victoires (0 à 1, 0 sans partie)
+}
+
+// add compte le résultat d'une partie dans le bilan
+func (r *Record) add(result string) {
+	r.Games++
+	switch result {
+	case ResultWin:
+		r.Wins++
+	case ResultLoss:
+		r.Losses++
+	default:
+		r.Draws++
+	}
+	r.WinRate = float64(r.Wins) / float64(r.Games)
+}
+
+// PlayerStats sont les statistiques d'un compte sur toutes ses parties terminées
+type PlayerStats struct {
+	Player Profile `json:"player"`
+	Record
+	Ranked       Record            `json:"ranked"`       // Bilan des seules parties classées
+	AverageTurns float64           `json:"averageTurns"` // Durée moyenne d'une partie, en coups (TurnCount)
+	ByDifficulty map[string]Record `json:"byDifficulty"` // Bilan par préréglage ("custom" pour un plateau personnalisé)
+	BySeat       map[string]Record `json:"bySeat"`       // Bilan en premier ("player1") et en second ("player2")
+	ByPreset     map[string]Record `json:"byPreset"`     // Bilan par format de plateau ("6x7", etc.)
+}
+
+// seatResult traduit le gagnant d'une partie en résultat pour un siège
+//
+// Retourne:
+//   - string: ResultWin, ResultLoss ou ResultDraw
+func seatResult(winner, seat string) string {
+	switch winner {
+	case seat:
+		return ResultWin
+	case "draw":
+		return ResultDraw
+	default:
+		return ResultLoss
+	}
+}
+
+// Stats calcule les statistiques d'un compte à partir de l'archive
+//
+// Toutes les parties terminées dont un siège est lié au compte sont
+// comptées : parties classées, mais aussi contre un invité, contre
+// l'ordinateur ou sur un format non classé. Une partie est classée si
+// son résultat a été compté (voir RatedGame) : Ranked en fait le bilan.
+//
+// Paramètres:
+//   - id: identifiant du compte
+//
+// Retourne:
+//   - PlayerStats: bilan global, classé, par préréglage, par siège et par format
+//   - bool: false si le compte n'existe pas
+func (gm *GameManager) Stats(id string) (PlayerStats, bool) {
+	profile, ok := gm.accounts.Get(id)
+	if !ok {
+		return PlayerStats{}, false
+	}
+	ranked := gm.accounts.rankedGames(id)
+
+	stats := PlayerStats{
+		Player:       profile,
+		ByDifficulty: make(map[string]Record),
+		BySeat:       map[string]Record{"player1": {}, "player2": {}},
+		ByPreset:     make(map[string]Record),
+	}
+	turns := 0
+	for _, game := range gm.archive.PlayedBy(id) {
+		for _, seat := range []string{"player1", "player2"} {
+			if game.Users[seat] != id {
+				continue
+			}
+			result := seatResult(game.Winner, seat)
+			stats.add(result)
+			turns += game.TurnCount
+			if ranked[game.ID] {
+				stats.Ranked.add(result)
+			}
+
+			difficulty := game.Difficulty
+			if difficulty == "" {
+				difficulty = customDifficulty
+			}
+			addTo(stats.ByDifficulty, difficulty, result)
+			addTo(stats.BySeat, seat, result)
+			addTo(stats.ByPreset, boardPreset(game.Rows, game.Cols), result)
+		}
+	}
+	if stats.Games > 0 {
+		stats.AverageTurns = float64(turns) / float64(stats.Games)
+	}
+	return stats, true
+}
+
+// addTo compte un résultat dans le bilan d'une catégorie
+func addTo(records map[string]Record, key, result string) {
+	r := records[key]
+	r.add(result)
+	records[key] = r
+}
+
+//#endregion
+
+//#region HANDLERS HTTP - JOUEURS
+
+// HandlePlayers aiguille les routes d'un joueur selon la fin du chemin
+//
+// Routes:
+//   - GET /api/players/{id}/rating: voir Accounts.HandlePlayerRating
+//   - GET /api/players/{id}/stats: voir HandlePlayerStats
+func (gm *GameManager) HandlePlayers(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/stats") {
+		gm.HandlePlayerStats(w, r)
+		return
+	}
+	gm.accounts.HandlePlayerRating(w, r)
+}
+
+// playerID extrait l'identifiant du compte d'un chemin /api/players/{id}/{resource}
+//
+// Retourne:
+//   - string: identifiant du compte
+//   - bool: false si le chemin ne correspond pas
+func playerID(path, resource string) (string, bool) {
+	id, ok := strings.CutSuffix(strings.TrimPrefix(path, "/api/players/"), "/"+resource)
+	return id, ok && id != "" && !strings.Contains(id, "/")
+}
+
+// HandlePlayerStats retourne les statistiques d'un compte
+//
+// Route: GET /api/players/{id}/stats
+//
+// Le bilan porte sur toutes les parties terminées du compte ; "ranked"
+// ne reprend que celles qui ont compté pour le classement.
+//
+// Paramètres:
+//   - w: ResponseWriter pour envoyer la réponse
+//   - r: Request dont le chemin contient l'identifiant du compte
+//
+// Réponse:
+//   - 200 OK: {"player", "games", "wins", "losses", "draws", "winRate", "ranked",
+//     "averageTurns", "byDifficulty", "bySeat", "byPreset"}
+//   - 404 Not Found: Compte introuvable ou chemin inconnu
+//   - 405 Method Not Allowed: Méthode HTTP incorrecte
+func (gm *GameManager) HandlePlayerStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		respondError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
+		return
+	}
+
+	id, ok := playerID(r.URL.Path, "stats")
+	if !ok {
+		respondError(w, http.StatusNotFound, "Route inconnue")
+		return
+	}
+
+	stats, ok := gm.Stats(id)
+	if !ok {
+		respondError(w, http.StatusNotFound, "Joueur introuvable")
+		return
+	}
+	respondJSON(w, http.StatusOK, stats)
+}
+
+//#endregion
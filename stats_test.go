@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+//#region TESTS DES STATISTIQUES
+
+// TestPlayerStats vérifie le bilan global, classé, par difficulté et par siège
+func TestPlayerStats(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	alice, _ := gm.accounts.Register("alice", "motdepasse")
+	bob, _ := gm.accounts.Register("bob", "motdepasse")
+	asP1 := map[string]string{"player1": alice.ID, "player2": bob.ID}
+	asP2 := map[string]string{"player1": bob.ID, "player2": alice.ID}
+
+	archive := func(id string, rows, cols int, difficulty string, users map[string]string, winner string, turns int) {
+		gm.archive.put(ArchivedGame{
+			Replay:     Replay{ID: id, Rows: rows, Cols: cols, Player1: "alice", Player2: "bob", GameOver: true, Winner: winner},
+			Difficulty: difficulty,
+			TurnCount:  turns,
+			Users:      users,
+		})
+	}
+	for _, game := range []RatedGame{
+		{GameID: "g1", Difficulty: "easy", Users: asP1, Winner: "player1", TurnCount: 10},
+		{GameID: "g2", Difficulty: "normal", Users: asP2, Winner: "player1", TurnCount: 20},
+		{GameID: "g3", Difficulty: "easy", Users: asP1, Winner: "draw", TurnCount: 30},
+	} {
+		if err := gm.accounts.RecordGame(game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archive("g1", 6, 7, "easy", asP1, "player1", 10)
+	archive("g2", 6, 9, "normal", asP2, "player1", 20)
+	archive("g3", 6, 7, "easy", asP1, "draw", 30)
+
+	// Parties non classées : contre l'ordinateur (comptée), et sous le
+	// même pseudo sans compte lié (ignorée)
+	archive("g4", 5, 5, "", map[string]string{"player1": alice.ID}, "player1", 20)
+	archive("g5", 6, 7, "easy", nil, "player2", 40)
+
+	stats, ok := gm.Stats(alice.ID)
+	if !ok || stats.Games != 4 || stats.Wins != 2 || stats.Losses != 1 || stats.Draws != 1 || stats.AverageTurns != 20 {
+		t.Fatalf("bilan global: %+v", stats)
+	}
+	if stats.Ranked != (Record{Games: 3, Wins: 1, Losses: 1, Draws: 1, WinRate: 1.0 / 3}) {
+		t.Errorf("bilan classé: %+v", stats.Ranked)
+	}
+	for key, want := range map[string]Record{
+		"easy":   {Games: 2, Wins: 1, Draws: 1, WinRate: 0.5},
+		"normal": {Games: 1, Losses: 1},
+		"custom": {Games: 1, Wins: 1, WinRate: 1},
+	} {
+		if got := stats.ByDifficulty[key]; got != want {
+			t.Errorf("difficulté %s: %+v, attendu %+v", key, got, want)
+		}
+	}
+	if _, ok := stats.ByDifficulty["hard"]; ok {
+		t.Error("difficulté jamais jouée présente")
+	}
+	if first := stats.BySeat["player1"]; first != (Record{Games: 3, Wins: 2, Draws: 1, WinRate: 2.0 / 3}) {
+		t.Errorf("en premier: %+v", first)
+	}
+	if second := stats.BySeat["player2"]; second != (Record{Games: 1, Losses: 1}) {
+		t.Errorf("en second: %+v", second)
+	}
+	if stats.ByPreset["6x7"].Games != 2 || stats.ByPreset["6x9"].Games != 1 || stats.ByPreset["5x5"].Games != 1 {
+		t.Errorf("par format: %+v", stats.ByPreset)
+	}
+
+	// Un compte sans partie a un bilan vide, avec les deux sièges
+	carol, _ := gm.accounts.Register("carol", "motdepasse")
+	if empty, _ := gm.Stats(carol.ID); empty.Games != 0 || empty.AverageTurns != 0 || len(empty.BySeat) != 2 {
+		t.Fatalf("bilan vide: %+v", empty)
+	}
+	if _, ok := gm.Stats("inconnu"); ok {
+		t.Fatal("statistiques d'un compte inconnu")
+	}
+}
+
+// TestPlayerStatsFromPlayedGames vérifie qu'une partie jouée jusqu'au bout
+// contre l'ordinateur apparaît dans les statistiques
+func TestPlayerStatsFromPlayedGames(t *testing.T) {
+	accounts := newAccounts()
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), accounts)
+	cookie := loginCookie(t, accounts, "alice")
+	req := httptest.NewRequest("POST", "/api/game/new",
+		strings.NewReader(`{"difficulty":"easy","player2":"Ordinateur","bot":"player2","botLevel":1,"bindSeat":"player1"}`))
+	req.AddCookie(cookie)
+	rec := httptest.NewRecorder()
+	gm.HandleNewGame(rec, req)
+	state := decodeState(t, rec)
+	id, _ := state["id"].(string)
+	users, _ := state["users"].(map[string]interface{})
+	alice, _ := users["player1"].(string)
+
+	gm.HandleResign(httptest.NewRecorder(), seatRequest(gm, "POST", id, "player1", "/api/game/resign", ""))
+	stats, ok := gm.Stats(alice)
+	if !ok || stats.Games != 1 || stats.Losses != 1 || stats.Ranked.Games != 0 || stats.ByDifficulty["easy"].Games != 1 {
+		t.Fatalf("partie contre l'ordinateur: %+v", stats)
+	}
+}
+
+// TestPlayerStatsHandler vérifie la route des statistiques et l'aiguillage des routes d'un joueur
+func TestPlayerStatsHandler(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	alice, _ := gm.accounts.Register("alice", "motdepasse")
+
+	for path, want := range map[string]int{
+		"/api/players/" + alice.ID + "/stats":  http.StatusOK,
+		"/api/players/" + alice.ID + "/rating": http.StatusOK,
+		"/api/players/inconnu/stats":           http.StatusNotFound,
+		"/api/players//stats":                  http.StatusNotFound,
+		"/api/players/" + alice.ID + "/games":  http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		gm.HandlePlayers(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: %d, attendu %d", path, rec.Code, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	gm.HandlePlayerStats(rec, httptest.NewRequest("POST", "/api/players/"+alice.ID+"/stats", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("méthode POST: %d", rec.Code)
+	}
+}
+
+//#endregion
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+//#region INTERFACE DE STOCKAGE
+
+// GameStore est un stockage persistant des parties
+//
+// Le GameManager y écrit chaque partie après chaque modification réussie
+// et recharge toutes les parties au démarrage du serveur.
+//
+// Les parties terminées sont aussi archivées à part : l'archive n'est
+// jamais effacée par Delete, seule la réouverture d'une partie par
+// l'annulation d'un coup l'en retire (voir archive.go).
+type GameStore interface {
+	// Save enregistre (ou remplace) une partie
+	Save(record GameRecord) error
+	// Delete supprime une partie (sans erreur si elle n'existe pas)
+	Delete(id string) error
+	// LoadAll retourne toutes les parties enregistrées
+	LoadAll() ([]GameRecord, error)
+	// Archive enregistre (ou remplace) une partie terminée dans l'archive
+	Archive(game ArchivedGame) error
+	// Unarchive retire une partie de l'archive (sans erreur si elle n'y est pas)
+	Unarchive(id string) error
+	// LoadArchive retourne toutes les parties archivées
+	LoadArchive() ([]ArchivedGame, error)
+}
+
+// NewGameStore crée le stockage choisi par configuration
+//
+// Paramètres:
+//   - kind: "memory" (aucune persistance) ou "file" (fichiers JSON dans
+//     DATA_DIR/games, et DATA_DIR/archive pour les parties terminées)
+//   - dataDir: dossier de données utilisé par le stockage "file"
+//
+// Retourne:
+//   - GameStore: le stockage demandé
+//   - error: si le type est inconnu ou le dossier inutilisable
+func NewGameStore(kind, dataDir string) (GameStore, error) {
+	switch kind {
+	case "", "memory":
+		return newMemoryStore(), nil
+	case "file":
+		return newFileStore(filepath.Join(dataDir, "games"), filepath.Join(dataDir, "archive"))
+	}
+	return nil, fmt.Errorf("stockage inconnu: %q (attendu: memory ou file)", kind)
+}
+
+//#endregion
+
+//#region ENREGISTREMENT D'UNE PARTIE
+
+// GameRecord est la forme sérialisable d'une partie complète
+//
+// Contrairement à GetState, il contient aussi l'historique et la pile de
+// rétablissement, pour que l'annulation fonctionne après un redémarrage.
+type GameRecord struct {
+	ID             string            `json:"id"`
+	Rows           int               `json:"rows"`
+	Cols           int               `json:"cols"`
+	Difficulty     string            `json:"difficulty"`
+	PresetRules    bool              `json:"presetRules,omitempty"`
+	WinLength      int               `json:"winLength"`
+	Board          [][]string        `json:"board"`
+	CurrentPlayer  string            `json:"currentPlayer"`
+	Player1        string            `json:"player1"`
+	Player2        string            `json:"player2"`
+	GameOver       bool              `json:"gameOver"`
+	Winner         string            `json:"winner"`
+	Reason         string            `json:"reason"`
+	LastMove       *Move             `json:"lastMove"`
+	TurnCount      int               `json:"turnCount"`
+	InverseGravity bool              `json:"inverseGravity"`
+	Gravity        GravityRule       `json:"gravity"`
+	Bot            string            `json:"bot"`
+	BotLevel       int               `json:"botLevel"`
+	Prefilled      []Token           `json:"prefilled"`
+	Seed           int64             `json:"seed"`
+	SeatTokens     map[string]string `json:"seatTokens"`
+	Users          map[string]string `json:"users,omitempty"`
+	RatingApplied  bool              `json:"ratingApplied,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	EndedAt        time.Time         `json:"endedAt"`
+	History        []storedMove      `json:"history"`
+	RedoStack      []storedMove      `json:"redoStack"`
+
+	TimeControl TimeControl      `json:"timeControl"`         // Cadence de la partie
+	Clocks      map[string]int64 `json:"clocks,omitempty"`    // Temps restant au début du tour, en millisecondes
+	TurnStarted time.Time        `json:"turnStarted"`         // Début du tour en cours
+	DrawOffer   string           `json:"drawOffer,omitempty"` // Joueur proposant la nulle
+}
+
+// storedMove est un coup de l'historique avec les états nécessaires à l'annulation
+type storedMove struct {
+	MoveRecord
+	Before turnState `json:"before"`
+	After  turnState `json:"after"`
+}
+
+// Record retourne la forme sérialisable de la partie
+func (g *Game) Record() GameRecord {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.record()
+}
+
+// record contient la logique de Record, sans verrouillage
+//
+// L'appelant doit détenir g.mu.
+func (g *Game) record() GameRecord {
+	return GameRecord{
+		ID:             g.ID,
+		Rows:           g.Rows,
+		Cols:           g.Cols,
+		Difficulty:     g.Difficulty,
+		PresetRules:    g.PresetRules,
+		WinLength:      g.WinLength,
+		Board:          g.Board.Grid(),
+		CurrentPlayer:  g.CurrentPlayer,
+		Player1:        g.Player1,
+		Player2:        g.Player2,
+		GameOver:       g.GameOver,
+		Winner:         g.Winner,
+		Reason:         g.Reason,
+		LastMove:       g.LastMove,
+		TurnCount:      g.TurnCount,
+		InverseGravity: g.InverseGravity,
+		Gravity:        g.Gravity,
+		Bot:            g.Bot,
+		BotLevel:       g.BotLevel,
+		Prefilled:      append([]Token{}, g.Prefilled...),
+		Seed:           g.Seed,
+		SeatTokens:     g.SeatTokens,
+		Users:          g.Users,
+		RatingApplied:  g.RatingApplied,
+		CreatedAt:      g.CreatedAt,
+		EndedAt:        g.EndedAt,
+		History:        storeMoves(g.history),
+		RedoStack:      storeMoves(g.redoStack),
+		TimeControl:    g.TimeControl,
+		Clocks:         storeClocks(g.Clocks),
+		TurnStarted:    g.TurnStarted,
+		DrawOffer:      g.DrawOffer,
+	}
+}
+
+// GameFromRecord reconstruit une partie à partir de sa forme sérialisée
+//
+// Paramètres:
+//   - rec: partie enregistrée
+//
+// Retourne:
+//   - *Game: la partie, prête à être jouée
+//   - error: si le plateau est hors limites, de dimensions incohérentes
+//     ou contient une case inconnue
+func GameFromRecord(rec GameRecord) (*Game, error) {
+	// Les parties enregistrées avant l'introduction de la règle se jouent à 4
+	winLength := rec.WinLength
+	if winLength == 0 {
+		winLength = DefaultWinLength
+	}
+
+	if err := validateBoard(rec.Rows, rec.Cols, winLength, 0); err != nil {
+		return nil, fmt.Errorf("partie %s: %w", rec.ID, err)
+	}
+	if len(rec.Board) != rec.Rows {
+		return nil, fmt.Errorf("partie %s: %d lignes enregistrées, %d attendues", rec.ID, len(rec.Board), rec.Rows)
+	}
+
+	// ... et avec l'inversion de gravité historique
+	gravity := rec.Gravity
+	if gravity.Mode == "" {
+		gravity, _ = ResolveGravityRule(GravityRule{}, rec.Seed)
+	}
+
+	board := NewBitboard(rec.Rows, rec.Cols)
+	for row, cells := range rec.Board {
+		if len(cells) != rec.Cols {
+			return nil, fmt.Errorf("partie %s: ligne %d de %d colonnes, %d attendues", rec.ID, row, len(cells), rec.Cols)
+		}
+		for col, cell := range cells {
+			switch cell {
+			case "":
+			case "player1", "player2", BlockedCell:
+				board.Set(row, col, cell)
+			default:
+				return nil, fmt.Errorf("partie %s: case (%d, %d) inconnue: %q", rec.ID, row, col, cell)
+			}
+		}
+	}
+
+	return &Game{
+		ID:             rec.ID,
+		Rows:           rec.Rows,
+		Cols:           rec.Cols,
+		Difficulty:     rec.Difficulty,
+		PresetRules:    rec.PresetRules,
+		WinLength:      winLength,
+		Board:          board,
+		CurrentPlayer:  rec.CurrentPlayer,
+		Player1:        rec.Player1,
+		Player2:        rec.Player2,
+		GameOver:       rec.GameOver,
+		Winner:         rec.Winner,
+		Reason:         rec.Reason,
+		LastMove:       rec.LastMove,
+		TurnCount:      rec.TurnCount,
+		InverseGravity: rec.InverseGravity,
+		Gravity:        gravity,
+		Bot:            rec.Bot,
+		BotLevel:       rec.BotLevel,
+		Prefilled:      rec.Prefilled,
+		Seed:           rec.Seed,
+		SeatTokens:     rec.SeatTokens,
+		Users:          rec.Users,
+		RatingApplied:  rec.RatingApplied,
+		CreatedAt:      rec.CreatedAt,
+		EndedAt:        rec.EndedAt,
+		history:        loadMoves(rec.History),
+		redoStack:      loadMoves(rec.RedoStack),
+		TimeControl:    rec.TimeControl,
+		Clocks:         loadClocks(rec.Clocks),
+		TurnStarted:    rec.TurnStarted,
+		DrawOffer:      rec.DrawOffer,
+		publishedOver:  rec.GameOver,
+	}, nil
+}
+
+// storeMoves convertit un historique en forme sérialisable
+func storeMoves(moves []MoveRecord) []storedMove {
+	stored := make([]storedMove, len(moves))
+	for i, m := range moves {
+		stored[i] = storedMove{
+			MoveRecord: m,
+			Before:     m.before,
+			After:      m.after,
+		}
+	}
+	return stored
+}
+
+// loadMoves reconstruit un historique depuis sa forme sérialisée
+func loadMoves(stored []storedMove) []MoveRecord {
+	moves := make([]MoveRecord, len(stored))
+	for i, s := range stored {
+		moves[i] = s.MoveRecord
+		moves[i].before = s.Before
+		moves[i].after = s.After
+	}
+	return moves
+}
+
+// storeClocks convertit les pendules en millisecondes
+func storeClocks(clocks map[string]time.Duration) map[string]int64 {
+	if clocks == nil {
+		return nil
+	}
+	stored := make(map[string]int64, len(clocks))
+	for player, left := range clocks {
+		stored[player] = left.Milliseconds()
+	}
+	return stored
+}
+
+// loadClocks reconstruit les pendules depuis leur forme sérialisée
+func loadClocks(stored map[string]int64) map[string]time.Duration {
+	if stored == nil {
+		return nil
+	}
+	clocks := make(map[string]time.Duration, len(stored))
+	for player, ms := range stored {
+		clocks[player] = time.Duration(ms) * time.Millisecond
+	}
+	return clocks
+}
+
+//#endregion
+
+//#region STOCKAGE EN MÉMOIRE
+
+// memoryStore conserve les parties en mémoire uniquement
+//
+// C'est le stockage par défaut : les parties sont perdues au redémarrage.
+type memoryStore struct {
+	mu       sync.Mutex
+	records  map[string]GameRecord
+	archived map[string]ArchivedGame
+}
+
+// newMemoryStore crée un stockage en mémoire vide
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		records:  make(map[string]GameRecord),
+		archived: make(map[string]ArchivedGame),
+	}
+}
+
+// Save enregistre une partie en mémoire
+func (s *memoryStore) Save(record GameRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.ID] = record
+	return nil
+}
+
+// Delete supprime une partie de la mémoire
+func (s *memoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, id)
+	return nil
+}
+
+// LoadAll retourne toutes les parties en mémoire
+func (s *memoryStore) LoadAll() ([]GameRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]GameRecord, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// Archive enregistre une partie terminée en mémoire
+func (s *memoryStore) Archive(game ArchivedGame) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.archived[game.ID] = game
+	return nil
+}
+
+// Unarchive retire une partie archivée de la mémoire
+func (s *memoryStore) Unarchive(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.archived, id)
+	return nil
+}
+
+// LoadArchive retourne toutes les parties archivées en mémoire
+func (s *memoryStore) LoadArchive() ([]ArchivedGame, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	games := make([]ArchivedGame, 0, len(s.archived))
+	for _, g := range s.archived {
+		games = append(games, g)
+	}
+	return games, nil
+}
+
+//#endregion
+
+//#region STOCKAGE EN FICHIERS JSON
+
+// fileStore enregistre chaque partie dans un fichier JSON <id>.json
+//
+// L'écriture passe par un fichier temporaire renommé ensuite, pour
+// qu'un arrêt brutal ne laisse jamais de fichier à moitié écrit.
+type fileStore struct {
+	dir        string // Dossier contenant les fichiers des parties
+	archiveDir string // Dossier contenant les fichiers des parties archivées
+}
+
+// newFileStore crée un stockage en fichiers (et ses dossiers si besoin)
+func newFileStore(dir, archiveDir string) (*fileStore, error) {
+	for _, d := range []string{dir, archiveDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return nil, fmt.Errorf("création du dossier %s: %w", d, err)
+		}
+	}
+	return &fileStore{dir: dir, archiveDir: archiveDir}, nil
+}
+
+// path retourne le chemin du fichier d'une partie
+func (s *fileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save écrit une partie dans son fichier
+func (s *fileStore) Save(record GameRecord) error {
+	return writeJSONFile(s.path(record.ID), record)
+}
+
+// writeJSONFile écrit une valeur en JSON dans un fichier
+//
+// L'écriture passe par un fichier temporaire du même dossier renommé
+// ensuite : le fichier n'est jamais à moitié écrit.
+//
+// Paramètres:
+//   - path: chemin du fichier à écrire
+//   - v: valeur à sérialiser
+//
+// Retourne:
+//   - error: erreur de sérialisation ou d'écriture
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Sans effet une fois le fichier renommé
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete supprime le fichier d'une partie
+func (s *fileStore) Delete(id string) error {
+	err := os.Remove(s.path(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// LoadAll lit tous les fichiers de parties du dossier
+func (s *fileStore) LoadAll() ([]GameRecord, error) {
+	records := []GameRecord{}
+	err := readJSONFiles(s.dir, func(data []byte) error {
+		var rec GameRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		records = append(records, rec)
+		return nil
+	})
+	return records, err
+}
+
+// Archive écrit une partie terminée dans le dossier de l'archive
+func (s *fileStore) Archive(game ArchivedGame) error {
+	return writeJSONFile(filepath.Join(s.archiveDir, game.ID+".json"), game)
+}
+
+// Unarchive supprime le fichier d'une partie archivée
+func (s *fileStore) Unarchive(id string) error {
+	err := os.Remove(filepath.Join(s.archiveDir, id+".json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// LoadArchive lit tous les fichiers du dossier de l'archive
+func (s *fileStore) LoadArchive() ([]ArchivedGame, error) {
+	games := []ArchivedGame{}
+	err := readJSONFiles(s.archiveDir, func(data []byte) error {
+		var game ArchivedGame
+		if err := json.Unmarshal(data, &game); err != nil {
+			return err
+		}
+		games = append(games, game)
+		return nil
+	})
+	return games, err
+}
+
+// readJSONFiles décode chaque fichier .json d'un dossier
+//
+// Un fichier illisible ou corrompu est ignoré (et signalé dans les logs)
+// pour ne pas empêcher le serveur de démarrer.
+//
+// Paramètres:
+//   - dir: dossier à lire
+//   - decode: décode le contenu d'un fichier et le conserve
+//
+// Retourne:
+//   - error: si le dossier lui-même ne peut pas être lu
+func readJSONFiles(dir string, decode func(data []byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = decode(data)
+		}
+		if err != nil {
+			log.Printf("Fichier ignoré %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+//#endregion
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+//#region TESTS DE STOCKAGE
+
+// TestFileStoreSurvivesRestart enregistre une partie dans des fichiers,
+// la recharge dans un nouveau gestionnaire et vérifie qu'elle reprend
+// exactement là où elle s'était arrêtée (annulation comprise).
+func TestFileStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewGameStore("file", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gm := NewGameManager(store, testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 9)
+	game := gm.getGame(id)
+	for _, col := range []int{0, 1, 2, 3, 4, 5, 6} {
+		game.DropPiece(col)
+	}
+	game.Undo()
+	gm.commitGame(game)
+	want := game.GetState()
+
+	// « Redémarrage » : nouveau stockage et nouveau gestionnaire sur le même dossier
+	store, err = NewGameStore("file", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted := NewGameManager(store, testDifficulties(t), newAccounts())
+	if n, err := restarted.LoadGames(); err != nil || n != 1 {
+		t.Fatalf("LoadGames() = %d, %v", n, err)
+	}
+
+	reloaded := restarted.getGame(id)
+	if reloaded == nil {
+		t.Fatal("partie non rechargée")
+	}
+	if got := reloaded.GetState(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("état rechargé différent:\n%v\nattendu:\n%v", got, want)
+	}
+	if !reflect.DeepEqual(reloaded.GetReplay(), game.GetReplay()) {
+		t.Fatal("relecture rechargée différente")
+	}
+
+	// L'historique a survécu : annuler et rétablir fonctionnent encore
+	if err := reloaded.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.Undo(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFileStoreDelete vérifie qu'une partie réinitialisée n'est pas rechargée
+func TestFileStoreDelete(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewGameStore("file", dir)
+	gm := NewGameManager(store, testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 7)
+
+	if err := store.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+	records, err := store.LoadAll()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("LoadAll() = %d parties, %v", len(records), err)
+	}
+}
+
+// TestCorruptFilesAreSkipped vérifie qu'un fichier corrompu ou une partie
+// invalide n'empêche pas le serveur de redémarrer
+func TestCorruptFilesAreSkipped(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewGameStore("file", dir)
+	gm := NewGameManager(store, testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 7)
+	gm.HandleResign(httptest.NewRecorder(), seatRequest(gm, "POST", newTestGame(t, gm, 6, 7), "player1", "/api/game/resign", ""))
+
+	// Un fichier qui n'est pas du JSON, dans chaque dossier
+	for _, sub := range []string{"games", "archive"} {
+		if err := os.WriteFile(filepath.Join(dir, sub, "corrompu.json"), []byte("{pas du json"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Des parties lisibles mais invalides
+	for name, rec := range map[string]GameRecord{
+		"immense":  {ID: "immense", Rows: 5000, Cols: 7},
+		"inconnue": {ID: "inconnue", Rows: 4, Cols: 4, Board: [][]string{{"", "", "", ""}, {"", "", "", ""}, {"", "", "", ""}, {"joueur3", "", "", ""}}},
+		"tronquee": {ID: "tronquee", Rows: 6, Cols: 7, Board: [][]string{{""}}},
+	} {
+		if err := writeJSONFile(filepath.Join(dir, "games", name+".json"), rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, _ = NewGameStore("file", dir)
+	restarted := NewGameManager(store, testDifficulties(t), newAccounts())
+	n, err := restarted.LoadGames()
+	if err != nil || n != 2 || restarted.getGame(id) == nil {
+		t.Fatalf("LoadGames() = %d, %v", n, err)
+	}
+	if total := len(restarted.archive.games); total != 1 {
+		t.Fatalf("%d parties archivées rechargées, 1 attendue", total)
+	}
+}
+
+// TestUnknownStore vérifie que la configuration est validée
+func TestUnknownStore(t *testing.T) {
+	if _, err := NewGameStore("redis", t.TempDir()); err == nil {
+		t.Fatal("un stockage inconnu devrait être refusé")
+	}
+}
+
+//#endregion
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+//#region PROTOCOLE WEBSOCKET (RFC 6455)
+
+// Opcodes des trames WebSocket utilisés par le serveur
+const (
+	wsOpText  = 0x1 // Trame texte (messages JSON)
+	wsOpClose = 0x8 // Fermeture de la connexion
+	wsOpPing  = 0x9 // Ping (le serveur répond par un pong)
+	wsOpPong  = 0xA // Pong
+)
+
+// wsGUID est la constante ajoutée à la clé du client pour la poignée de main
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsMaxPayload limite la taille des trames reçues (le client n'envoie que des contrôles)
+const wsMaxPayload = 4096
+
+// errCrossOrigin refuse une connexion ouverte depuis la page d'un autre site
+var errCrossOrigin = errors.New("origine de la connexion WebSocket refusée")
+
+// sameOrigin indique si la requête vient d'une page servie par ce serveur
+//
+// Les navigateurs envoient toujours l'en-tête Origin lors d'une connexion
+// WebSocket ; un client sans navigateur (sans Origin) est accepté.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// wsUpgrade effectue la poignée de main WebSocket et récupère la connexion TCP
+//
+// Paramètres:
+//   - w: ResponseWriter de la requête HTTP (doit supporter http.Hijacker)
+//   - r: Request contenant les en-têtes Upgrade et Sec-WebSocket-Key
+//
+// Retourne:
+//   - net.Conn: connexion brute, prête pour l'échange de trames
+//   - *bufio.ReadWriter: tampon associé (peut déjà contenir des données du client)
+//   - error: si la requête n'est pas une demande WebSocket valide,
+//     ou errCrossOrigin si elle vient de la page d'un autre site
+func wsUpgrade(w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.ReadWriter, error) {
+	if !sameOrigin(r) {
+		return nil, nil, errCrossOrigin
+	}
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		return nil, nil, errors.New("en-têtes Upgrade: websocket attendus")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, nil, errors.New("en-tête Sec-WebSocket-Key manquant")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("connexion non détournable")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Clé d'acceptation: base64(SHA-1(clé + GUID))
+	sum := sha1.Sum([]byte(key + wsGUID))
+	accept := base64.StdEncoding.EncodeToString(sum[:])
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + accept + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, rw, nil
+}
+
+// wsWriteFrame écrit une trame non masquée (les trames du serveur ne le sont jamais)
+//
+// Paramètres:
+//   - w: destination
+//   - opcode: type de trame (wsOpText, wsOpClose...)
+//   - payload: contenu de la trame
+func wsWriteFrame(w *bufio.Writer, opcode byte, payload []byte) error {
+	w.WriteByte(0x80 | opcode) // FIN + opcode
+
+	// Longueur sur 7 bits, 16 bits ou 64 bits selon la taille
+	switch n := len(payload); {
+	case n < 126:
+		w.WriteByte(byte(n))
+	case n <= 0xFFFF:
+		w.WriteByte(126)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(127)
+		binary.Write(w, binary.BigEndian, uint64(n))
+	}
+
+	w.Write(payload)
+	return w.Flush()
+}
+
+// wsReadFrame lit une trame envoyée par le client
+//
+// Retourne:
+//   - byte: opcode de la trame
+//   - []byte: contenu démasqué
+//   - error: erreur de lecture ou trame invalide
+func wsReadFrame(r *bufio.Reader) (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var n uint16
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(n)
+	case 127:
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return 0, nil, err
+		}
+	}
+	if length > wsMaxPayload {
+		return 0, nil, errors.New("trame trop grande")
+	}
+	// Le client doit toujours masquer ses trames
+	if !masked {
+		return 0, nil, errors.New("trame client non masquée")
+	}
+
+	mask := make([]byte, 4)
+	if _, err := io.ReadFull(r, mask); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+//#endregion
+
+//#region CLIENTS CONNECTÉS
+
+// wsClient est un navigateur abonné aux événements d'une partie
+type wsClient struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	wmu  sync.Mutex  // Sérialise les écritures (messages et réponses aux pings)
+	send chan []byte // Messages en attente d'envoi (la file pleine déconnecte le client)
+}
+
+// write écrit une trame en excluant toute autre écriture simultanée
+func (c *wsClient) write(opcode byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return wsWriteFrame(c.rw.Writer, opcode, payload)
+}
+
+// writeLoop envoie les messages de la file jusqu'à sa fermeture
+func (c *wsClient) writeLoop() {
+	defer c.conn.Close()
+	for msg := range c.send {
+		if err := c.write(wsOpText, msg); err != nil {
+			return
+		}
+	}
+	// File fermée par le hub: fermeture propre
+	c.write(wsOpClose, nil)
+}
+
+// readLoop lit les trames du client jusqu'à la déconnexion
+//
+// Le client n'a rien à envoyer: seules les trames de contrôle sont traitées.
+//
+// Paramètres:
+//   - onClose: appelée à la déconnexion (désabonnement du client)
+func (c *wsClient) readLoop(onClose func()) {
+	defer onClose()
+	for {
+		opcode, payload, err := wsReadFrame(c.rw.Reader)
+		if err != nil || opcode == wsOpClose {
+			return
+		}
+		if opcode == wsOpPing {
+			c.write(wsOpPong, payload)
+		}
+	}
+}
+
+//#endregion
+
+//#region DIFFUSION DES ÉVÉNEMENTS
+
+// GameEvent est un message poussé aux clients abonnés à une partie
+//
+// Types d'événements:
+//   - "state": état complet de la partie (payload de GetState)
+//   - "move": un coup vient d'être joué
+//   - "gravity": la gravité vient de s'inverser
+//   - "gameover": la partie vient de se terminer
+//   - "reset": la partie a été supprimée (la connexion est ensuite fermée)
+//
+// Sur le canal d'un ticket de la file d'attente (voir HandleLobbyWebSocket):
+//   - "waiting": le joueur attend un adversaire
+//   - "match": la partie est créée (voir LobbyMatch)
+//   - "cancelled": le ticket a été remplacé par une nouvelle inscription
+type GameEvent struct {
+	Type           string                 `json:"type"`
+	State          map[string]interface{} `json:"state,omitempty"`
+	Move           *MoveRecord            `json:"move,omitempty"`
+	InverseGravity *bool                  `json:"inverseGravity,omitempty"`
+	Winner         string                 `json:"winner,omitempty"`
+	Match          *LobbyMatch            `json:"match,omitempty"`
+}
+
+// EventHub distribue les événements des parties aux clients WebSocket
+type EventHub struct {
+	mu      sync.Mutex
+	clients map[string]map[*wsClient]bool // Clients abonnés, par identifiant de partie
+}
+
+// NewEventHub crée un hub sans abonné
+func NewEventHub() *EventHub {
+	return &EventHub{clients: make(map[string]map[*wsClient]bool)}
+}
+
+// subscribe abonne un client aux événements d'une partie
+func (h *EventHub) subscribe(gameID string, c *wsClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[gameID] == nil {
+		h.clients[gameID] = make(map[*wsClient]bool)
+	}
+	h.clients[gameID][c] = true
+}
+
+// unsubscribe désabonne un client et ferme sa file d'envoi
+func (h *EventHub) unsubscribe(gameID string, c *wsClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.clients[gameID][c] {
+		return
+	}
+	delete(h.clients[gameID], c)
+	if len(h.clients[gameID]) == 0 {
+		delete(h.clients, gameID)
+	}
+	close(c.send)
+}
+
+// sendTo envoie un événement à un seul client abonné
+//
+// Paramètres:
+//   - gameID: identifiant de la partie à laquelle le client est abonné
+//   - c: client destinataire
+//   - event: événement à envoyer
+func (h *EventHub) sendTo(gameID string, c *wsClient, event GameEvent) {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		log.Println("Erreur d'encodage d'un événement:", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.clients[gameID][c] {
+		return // Déjà déconnecté
+	}
+	select {
+	case c.send <- msg:
+	default:
+	}
+}
+
+// Publish envoie des événements à tous les clients abonnés à une partie
+//
+// L'envoi ne bloque jamais: un client trop lent (file pleine) est déconnecté.
+//
+// Paramètres:
+//   - gameID: identifiant de la partie
+//   - events: événements à envoyer, dans l'ordre
+func (h *EventHub) Publish(gameID string, events ...GameEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, event := range events {
+		msg, err := json.Marshal(event)
+		if err != nil {
+			log.Println("Erreur d'encodage d'un événement:", err)
+			continue
+		}
+		for c := range h.clients[gameID] {
+			select {
+			case c.send <- msg:
+			default:
+				delete(h.clients[gameID], c)
+				close(c.send)
+			}
+		}
+	}
+	if len(h.clients[gameID]) == 0 {
+		delete(h.clients, gameID)
+	}
+}
+
+// Close déconnecte proprement tous les clients abonnés à une partie
+//
+// Les messages déjà en file sont envoyés avant la trame de fermeture.
+//
+// Paramètres:
+//   - gameID: identifiant de la partie (supprimée)
+func (h *EventHub) Close(gameID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients[gameID] {
+		close(c.send)
+	}
+	delete(h.clients, gameID)
+}
+
+// moveEvents construit les événements d'une suite de coups
+//
+// Chaque coup produit un événement "move", suivi d'un événement "gravity"
+// s'il a inversé la gravité ; l'état final est ajouté à la fin, suivi de
+// "gameover" si ces événements terminent la partie.
+//
+// Paramètres:
+//   - moves: coups joués depuis la dernière diffusion
+//   - state: état de la partie après ces coups
+//   - ended: true si la partie vient de se terminer (fin pas encore diffusée)
+//
+// Retourne:
+//   - []GameEvent: événements à publier
+func moveEvents(moves []MoveRecord, state map[string]interface{}, ended bool) []GameEvent {
+	events := []GameEvent{}
+	for i := range moves {
+		m := moves[i]
+		events = append(events, GameEvent{Type: "move", Move: &m})
+		if m.after.InverseGravity != m.before.InverseGravity {
+			inverse := m.after.InverseGravity
+			events = append(events, GameEvent{Type: "gravity", InverseGravity: &inverse})
+		}
+	}
+	events = append(events, GameEvent{Type: "state", State: state})
+	if ended {
+		events = append(events, GameEvent{Type: "gameover", Winner: state["winner"].(string)})
+	}
+	return events
+}
+
+//#endregion
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//#region CLIENT WEBSOCKET DE TEST
+
+// dialWebSocket ouvre une connexion WebSocket brute vers le serveur de test
+func dialWebSocket(t *testing.T, server *httptest.Server, path string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	io.WriteString(conn, "GET "+path+" HTTP/1.1\r\n"+
+		"Host: localhost\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("poignée de main: %d", resp.StatusCode)
+	}
+	// Valeur de référence de la RFC 6455 pour cette clé
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept = %q", got)
+	}
+	return conn, r
+}
+
+// readEvent lit une trame texte (non masquée) du serveur
+func readEvent(t *testing.T, conn net.Conn, r *bufio.Reader) GameEvent {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		t.Fatal(err)
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var n uint16
+		binary.Read(r, binary.BigEndian, &n)
+		length = uint64(n)
+	case 127:
+		binary.Read(r, binary.BigEndian, &length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	var event GameEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatalf("%v: %s", err, payload)
+	}
+	return event
+}
+
+//#endregion
+
+//#region TESTS TEMPS RÉEL
+
+// TestWebSocketPushesMoves vérifie qu'un second écran reçoit les coups
+// joués par un autre client, inversion de gravité comprise.
+func TestWebSocketPushesMoves(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/game/drop", gm.HandleDropPiece)
+	mux.HandleFunc("/api/game/ws", gm.HandleWebSocket)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	id := newTestGame(t, gm, 10, 10)
+	conn, r := dialWebSocket(t, server, "/api/game/ws?id="+id)
+
+	// État initial à la connexion
+	if event := readEvent(t, conn, r); event.Type != "state" || event.State["id"] != id {
+		t.Fatalf("premier événement: %+v", event)
+	}
+
+	// 5 coups : le dernier inverse la gravité
+	for i, col := range []int{0, 1, 2, 3, 4} {
+		seat := []string{"player1", "player2"}[i%2]
+		req, _ := http.NewRequest("POST", server.URL+"/api/game/drop?id="+id,
+			strings.NewReader(fmt.Sprintf(`{"col":%d}`, col)))
+		req.Header.Set(seatTokenHeader, gm.getGame(id).SeatTokens[seat])
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if event := readEvent(t, conn, r); event.Type != "move" || event.Move.Col != col || event.Move.Turn != i+1 {
+			t.Fatalf("coup %d: %+v", i+1, event)
+		}
+		if i == 4 {
+			event := readEvent(t, conn, r)
+			if event.Type != "gravity" || event.InverseGravity == nil || !*event.InverseGravity {
+				t.Fatalf("inversion de gravité attendue: %+v", event)
+			}
+		}
+		if event := readEvent(t, conn, r); event.Type != "state" || event.State["turnCount"] != float64(i+1) {
+			t.Fatalf("état après le coup %d: %+v", i+1, event)
+		}
+	}
+}
+
+// TestWebSocketRejectsPlainRequest vérifie qu'une requête HTTP simple est refusée
+func TestWebSocketRejectsPlainRequest(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 7)
+
+	rec := httptest.NewRecorder()
+	gm.HandleWebSocket(rec, httptest.NewRequest("GET", "/api/game/ws?id="+id, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("code %d, attendu 400", rec.Code)
+	}
+}
+
+// TestWebSocketResetClosesClients vérifie qu'un écran abonné à une partie
+// supprimée reçoit "reset" puis la fermeture de la connexion
+func TestWebSocketResetClosesClients(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/game/ws", gm.HandleWebSocket)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	id := newTestGame(t, gm, 6, 7)
+	conn, r := dialWebSocket(t, server, "/api/game/ws?id="+id)
+	readEvent(t, conn, r)
+
+	gm.HandleReset(httptest.NewRecorder(), seatRequest(gm, "POST", id, "player1", "/api/game/reset", ""))
+	if event := readEvent(t, conn, r); event.Type != "reset" {
+		t.Fatalf("événement reçu: %+v", event)
+	}
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil || header[0]&0x0F != wsOpClose {
+		t.Fatalf("trame de fermeture attendue: %x, %v", header, err)
+	}
+
+	gm.hub.mu.Lock()
+	defer gm.hub.mu.Unlock()
+	if _, ok := gm.hub.clients[id]; ok {
+		t.Fatal("abonnés d'une partie supprimée conservés")
+	}
+}
+
+// TestPublishDropsSlowClients vérifie qu'un client dont la file est pleine
+// est déconnecté sans laisser d'entrée vide dans le hub
+func TestPublishDropsSlowClients(t *testing.T) {
+	hub := NewEventHub()
+	slow := &wsClient{send: make(chan []byte)} // File sans place: toujours pleine
+	hub.subscribe("partie", slow)
+	hub.Publish("partie", GameEvent{Type: "state"})
+
+	if _, open := <-slow.send; open {
+		t.Fatal("file du client lent non fermée")
+	}
+	if len(hub.clients) != 0 {
+		t.Fatalf("entrée vide conservée: %v", hub.clients)
+	}
+}
+
+// TestGameOverPublishedOnce vérifie que la fin de partie n'est annoncée
+// qu'une fois, même si la partie terminée est enregistrée à nouveau
+func TestGameOverPublishedOnce(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 7)
+	client := &wsClient{send: make(chan []byte, 16)}
+	gm.hub.subscribe(id, client)
+	gameOvers := func() int {
+		n := 0
+		for len(client.send) > 0 {
+			var event GameEvent
+			json.Unmarshal(<-client.send, &event)
+			if event.Type == "gameover" {
+				n++
+			}
+		}
+		return n
+	}
+
+	gm.HandleResign(httptest.NewRecorder(), seatRequest(gm, "POST", id, "player1", "/api/game/resign", ""))
+	if n := gameOvers(); n != 1 {
+		t.Fatalf("%d événements gameover à l'abandon, attendu 1", n)
+	}
+	gm.commitGame(gm.getGame(id))
+	if n := gameOvers(); n != 0 {
+		t.Fatalf("%d événements gameover pour une partie déjà terminée", n)
+	}
+}
+
+// TestWebSocketRejectsCrossOrigin vérifie qu'une page d'un autre site ne peut
+// pas s'abonner aux parties
+func TestWebSocketRejectsCrossOrigin(t *testing.T) {
+	gm := NewGameManager(newMemoryStore(), testDifficulties(t), newAccounts())
+	id := newTestGame(t, gm, 6, 7)
+
+	req := httptest.NewRequest("GET", "/api/game/ws?id="+id, nil)
+	req.Host = "localhost:8080"
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Origin", "http://evil.example")
+	rec := httptest.NewRecorder()
+	gm.HandleWebSocket(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("code %d, attendu 403", rec.Code)
+	}
+
+	req.Header.Set("Origin", "http://localhost:8080")
+	if !sameOrigin(req) {
+		t.Fatal("page du serveur refusée")
+	}
+}
+
+//#endregion